@@ -0,0 +1,306 @@
+package attest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/mchmarny/reputer/pkg/schema"
+)
+
+const (
+	// StatementType is the in-toto Statement v1 type.
+	StatementType = "https://in-toto.io/Statement/v1"
+
+	// PredicateType identifies a reputer report predicate.
+	PredicateType = "https://github.com/mchmarny/reputer/attestation/report/v1"
+
+	// PayloadType is the DSSE payload type of in-toto statements.
+	PayloadType = "application/vnd.in-toto+json"
+
+	// digestGitCommit is the in-toto digest algorithm name for git commits.
+	digestGitCommit = "gitCommit"
+)
+
+// commitSHA matches a full git commit SHA, the only form a gitCommit digest
+// may take.
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// Statement is an in-toto v1 Statement with a report predicate.
+type Statement struct {
+	Type          string         `json:"_type"`
+	Subject       []Subject      `json:"subject"`
+	PredicateType string         `json:"predicateType"`
+	Predicate     *report.Report `json:"predicate"`
+}
+
+// Subject identifies the attested artifact.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Envelope is a DSSE envelope.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a single DSSE signature.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// NewStatement returns a statement whose subject is the report's repo at
+// its commit. The report must have both, and the commit must be a full SHA.
+func NewStatement(r *report.Report) (*Statement, error) {
+	if r == nil {
+		return nil, errors.New("report must be specified")
+	}
+	if r.Repo == "" || r.AtCommit == "" {
+		return nil, errors.New("report must have a repo and commit")
+	}
+	if !commitSHA.MatchString(r.AtCommit) {
+		return nil, fmt.Errorf("report commit %q is not a full commit SHA", r.AtCommit)
+	}
+
+	return &Statement{
+		Type: StatementType,
+		Subject: []Subject{{
+			Name:   r.Repo,
+			Digest: map[string]string{digestGitCommit: r.AtCommit},
+		}},
+		PredicateType: PredicateType,
+		Predicate:     r,
+	}, nil
+}
+
+// Sign serializes the statement and signs it as a DSSE envelope.
+func Sign(st *Statement, key crypto.Signer) (*Envelope, error) {
+	if st == nil {
+		return nil, errors.New("statement must be specified")
+	}
+
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding statement: %w", err)
+	}
+
+	keyID, err := KeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := sign(key, pae(PayloadType, payload))
+	if err != nil {
+		return nil, fmt.Errorf("error signing statement: %w", err)
+	}
+
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{{KeyID: keyID, Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// Write signs the report and writes the indented envelope.
+func Write(w io.Writer, r *report.Report, key crypto.Signer) error {
+	st, err := NewStatement(r)
+	if err != nil {
+		return err
+	}
+
+	env, err := Sign(st, key)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(env); err != nil {
+		return fmt.Errorf("error encoding envelope: %w", err)
+	}
+
+	return nil
+}
+
+// Read decodes a DSSE envelope.
+func Read(r io.Reader) (*Envelope, error) {
+	var env Envelope
+	if err := json.NewDecoder(r).Decode(&env); err != nil {
+		return nil, fmt.Errorf("error decoding envelope: %w", err)
+	}
+	return &env, nil
+}
+
+// LoadEnvelope reads a DSSE envelope from a file.
+func LoadEnvelope(path string) (*Envelope, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // G304: path is a user-supplied attestation file
+	if err != nil {
+		return nil, fmt.Errorf("error reading envelope %s: %w", path, err)
+	}
+
+	return Read(bytes.NewReader(b))
+}
+
+// Verify checks that a signature in the envelope was made by pub, then
+// decodes the statement and checks it against the reputer predicate
+// schema. It returns the verified statement.
+func Verify(env *Envelope, pub crypto.PublicKey) (*Statement, error) {
+	if env == nil {
+		return nil, errors.New("envelope must be specified")
+	}
+	if env.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type: %s", env.PayloadType)
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding payload: %w", err)
+	}
+
+	keyID, err := KeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := pae(env.PayloadType, payload)
+	verified := false
+	for _, s := range env.Signatures {
+		if s.KeyID != "" && s.KeyID != keyID {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if verify(pub, msg, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("no valid signature for key " + keyID)
+	}
+
+	return parseStatement(payload)
+}
+
+// parseStatement strictly decodes a statement and validates its schema.
+// Predicates written before report schema versioning skip the report
+// schema check.
+func parseStatement(payload []byte) (*Statement, error) {
+	var raw struct {
+		Predicate json.RawMessage `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("error decoding statement: %w", err)
+	}
+	if err := schema.ValidateReport(raw.Predicate); err != nil {
+		if !errors.Is(err, schema.ErrUnversioned) {
+			return nil, fmt.Errorf("invalid predicate: %w", err)
+		}
+		slog.Warn("attested report predates schema versioning, skipping schema validation")
+	}
+
+	var st Statement
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&st); err != nil {
+		return nil, fmt.Errorf("error decoding statement: %w", err)
+	}
+
+	if err := st.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid statement: %w", err)
+	}
+
+	return &st, nil
+}
+
+// Validate checks the statement's type, predicate type, and that the
+// subject matches the report's repo and commit.
+func (s *Statement) Validate() error {
+	if s.Type != StatementType {
+		return fmt.Errorf("unexpected statement type: %s", s.Type)
+	}
+	if s.PredicateType != PredicateType {
+		return fmt.Errorf("unexpected predicate type: %s", s.PredicateType)
+	}
+
+	r := s.Predicate
+	if r == nil {
+		return errors.New("predicate must be specified")
+	}
+	if r.Repo == "" || r.AtCommit == "" {
+		return errors.New("predicate must have a repo and commit")
+	}
+	if !commitSHA.MatchString(r.AtCommit) {
+		return fmt.Errorf("predicate commit %q is not a full commit SHA", r.AtCommit)
+	}
+	if r.GeneratedOn.IsZero() {
+		return errors.New("predicate must have a generation time")
+	}
+	if r.Meta == nil || r.Meta.ModelVersion == "" {
+		return errors.New("predicate must have a model version")
+	}
+	for i, a := range r.Contributors {
+		if a == nil || a.Username == "" {
+			return fmt.Errorf("contributor %d must have a username", i)
+		}
+		if a.Reputation < 0 || a.Reputation > 1 {
+			return fmt.Errorf("contributor %s reputation %v out of range", a.Username, a.Reputation)
+		}
+	}
+
+	if len(s.Subject) != 1 {
+		return fmt.Errorf("expected one subject, got %d", len(s.Subject))
+	}
+	sub := s.Subject[0]
+	if sub.Name != r.Repo || sub.Digest[digestGitCommit] != r.AtCommit {
+		return errors.New("subject does not match predicate repo and commit")
+	}
+
+	return nil
+}
+
+// pae returns the DSSE pre-authentication encoding of the payload.
+func pae(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// sign signs msg with ed25519 directly or with ECDSA over its digest.
+func sign(key crypto.Signer, msg []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, msg), nil
+	case *ecdsa.PrivateKey:
+		return ecdsa.SignASN1(rand.Reader, k, digest(k.Curve.Params().BitSize, msg))
+	default:
+		return nil, fmt.Errorf("unsupported key type: %T", key)
+	}
+}
+
+// verify checks sig over msg for an ed25519 or ECDSA public key.
+func verify(pub crypto.PublicKey, msg, sig []byte) bool {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, msg, sig)
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest(k.Curve.Params().BitSize, msg), sig)
+	default:
+		return false
+	}
+}
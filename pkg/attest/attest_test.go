@@ -0,0 +1,202 @@
+package attest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCommit = "0123456789abcdef0123456789abcdef01234567"
+
+func testReport() *report.Report {
+	return &report.Report{
+		SchemaVersion: report.SchemaVersion,
+		Repo:          "github.com/o/r",
+		AtCommit:      testCommit,
+		GeneratedOn:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		TotalCommits:  3,
+		Meta:          report.MakeMeta(nil),
+		Contributors:  []*report.Author{{Username: "alice", Reputation: 0.8}},
+	}
+}
+
+// writeKeys writes the key pair as PEM files and returns their paths.
+func writeKeys(t *testing.T, key crypto.Signer) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	priv, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pub, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+
+	privPath := filepath.Join(dir, "key.pem")
+	pubPath := filepath.Join(dir, "key.pub")
+	require.NoError(t, os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: priv}), 0o600))
+	require.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}), 0o600))
+
+	return privPath, pubPath
+}
+
+func TestSignVerify(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ec384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	for name, key := range map[string]crypto.Signer{"ed25519": edKey, "ecdsa-p256": ecKey, "ecdsa-p384": ec384} {
+		t.Run(name, func(t *testing.T) {
+			privPath, pubPath := writeKeys(t, key)
+			signer, err := LoadPrivateKey(privPath)
+			require.NoError(t, err)
+			pub, err := LoadPublicKey(pubPath)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, testReport(), signer))
+
+			env, err := Read(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, PayloadType, env.PayloadType)
+			require.Len(t, env.Signatures, 1)
+
+			id, err := KeyID(pub)
+			require.NoError(t, err)
+			assert.Equal(t, id, env.Signatures[0].KeyID)
+
+			st, err := Verify(env, pub)
+			require.NoError(t, err)
+			assert.Equal(t, "github.com/o/r", st.Subject[0].Name)
+			assert.Equal(t, testCommit, st.Subject[0].Digest["gitCommit"])
+			assert.Equal(t, "alice", st.Predicate.Contributors[0].Username)
+		})
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	st, err := NewStatement(testReport())
+	require.NoError(t, err)
+	env, err := Sign(st, key)
+	require.NoError(t, err)
+
+	_, err = Verify(env, other.Public())
+	assert.ErrorContains(t, err, "no valid signature")
+
+	// Tampered payload.
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	require.NoError(t, err)
+	tampered := *env
+	tampered.Payload = base64.StdEncoding.EncodeToString(bytes.Replace(payload, []byte(`"reputation":0.8`), []byte(`"reputation":0.9`), 1))
+	require.NotEqual(t, env.Payload, tampered.Payload)
+	_, err = Verify(&tampered, key.Public())
+	assert.ErrorContains(t, err, "no valid signature")
+
+	wrongType := *env
+	wrongType.PayloadType = "application/json"
+	_, err = Verify(&wrongType, key.Public())
+	assert.ErrorContains(t, err, "payload type")
+}
+
+func TestVerifySchema(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		mutate func(st *Statement)
+		want   string
+	}{
+		{name: "predicate type", mutate: func(st *Statement) { st.PredicateType = "x" }, want: "predicate type"},
+		{name: "subject mismatch", mutate: func(st *Statement) { st.Subject[0].Digest["gitCommit"] = "def" }, want: "subject does not match"},
+		{name: "no model", mutate: func(st *Statement) { st.Predicate.Meta = nil }, want: "model version"},
+		{name: "bad reputation", mutate: func(st *Statement) { st.Predicate.Contributors[0].Reputation = 2 }, want: "maximum: got 2, want 1"},
+		{name: "unversioned bad reputation", mutate: func(st *Statement) {
+			st.Predicate.SchemaVersion = ""
+			st.Predicate.Contributors[0].Reputation = 2
+		}, want: "out of range"},
+		{name: "schema version", mutate: func(st *Statement) { st.Predicate.SchemaVersion = "2.0.0" }, want: "unsupported schema version"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			st, err := NewStatement(testReport())
+			require.NoError(t, err)
+			tc.mutate(st)
+			env, err := Sign(st, key)
+			require.NoError(t, err)
+
+			_, err = Verify(env, key.Public())
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+
+	// Unknown predicate fields are rejected.
+	payload := []byte(`{"_type":"` + StatementType + `","subject":[],"predicateType":"` + PredicateType + `","predicate":{"repo":"x","bogus":1}}`)
+	sig, err := sign(key, pae(PayloadType, payload))
+	require.NoError(t, err)
+	env := &Envelope{PayloadType: PayloadType, Payload: base64.StdEncoding.EncodeToString(payload), Signatures: []Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}}}
+	_, err = Verify(env, key.Public())
+	assert.ErrorContains(t, err, "unknown field")
+}
+
+func TestNewStatementRequiresCommit(t *testing.T) {
+	r := testReport()
+	r.AtCommit = ""
+	_, err := NewStatement(r)
+	assert.Error(t, err)
+
+	r.AtCommit = "abc123"
+	_, err = NewStatement(r)
+	assert.ErrorContains(t, err, "not a full commit SHA")
+}
+
+func TestPAE(t *testing.T) {
+	assert.Equal(t, "DSSEv1 29 http://example.com/HelloWorld 11 hello world",
+		string(pae("http://example.com/HelloWorld", []byte("hello world"))))
+}
+
+func TestStatementJSON(t *testing.T) {
+	st, err := NewStatement(testReport())
+	require.NoError(t, err)
+	b, err := json.Marshal(st)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"_type":"https://in-toto.io/Statement/v1"`)
+	assert.Contains(t, string(b), `"subject":[{"name":"github.com/o/r","digest":{"gitCommit":"`+testCommit+`"}}]`)
+}
+
+func TestLoadKeyErrors(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "bad.pem")
+	require.NoError(t, os.WriteFile(p, []byte("not pem"), 0o600))
+
+	_, err := LoadPrivateKey(p)
+	assert.ErrorContains(t, err, "no PEM data")
+	_, err = LoadPublicKey(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+
+	_, pubPath := writeKeys(t, ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	_, err = LoadPrivateKey(pubPath)
+	assert.ErrorContains(t, err, "unsupported PEM block")
+}
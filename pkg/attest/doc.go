@@ -0,0 +1,7 @@
+// Package attest wraps reputation reports in signed in-toto attestations.
+//
+// A report becomes the predicate of an in-toto v1 Statement whose subject
+// is the scanned repo at its commit. The statement is signed with a local
+// ed25519 or ECDSA key and serialized as a DSSE envelope, so later pipeline
+// stages can verify the report has not been altered since it was produced.
+package attest
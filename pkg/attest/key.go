@@ -0,0 +1,109 @@
+package attest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// LoadPrivateKey reads an unencrypted PKCS #8 ed25519 or ECDSA private key
+// ("PRIVATE KEY") or a SEC 1 ECDSA key ("EC PRIVATE KEY") from a PEM file.
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in %s", block.Type, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key %s: %w", path, err)
+	}
+
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T in %s (must be ed25519 or ECDSA)", key, path)
+	}
+}
+
+// LoadPublicKey reads a PKIX ed25519 or ECDSA public key ("PUBLIC KEY")
+// from a PEM file.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("unsupported PEM block %q in %s", block.Type, path)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing public key %s: %w", path, err)
+	}
+
+	switch key.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T in %s (must be ed25519 or ECDSA)", key, path)
+	}
+}
+
+// KeyID returns the hex SHA-256 of the public key's PKIX encoding.
+func KeyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("error encoding public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// readPEM returns the first PEM block in the file.
+func readPEM(path string) (*pem.Block, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // G304: path is a user-supplied key file
+	if err != nil {
+		return nil, fmt.Errorf("error reading key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+
+	return block, nil
+}
+
+// digest hashes msg with the SHA-2 function matching the curve size.
+func digest(bits int, msg []byte) []byte {
+	switch {
+	case bits > 384:
+		sum := sha512.Sum512(msg)
+		return sum[:]
+	case bits > 256:
+		sum := sha512.Sum384(msg)
+		return sum[:]
+	default:
+		sum := sha256.Sum256(msg)
+		return sum[:]
+	}
+}
@@ -0,0 +1,92 @@
+// Package cache memoizes lookups by key for a time. Concurrent lookups of
+// the same key are coalesced into one, and failed lookups are not cached.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache memoizes lookups by key for a time. Concurrent lookups of the same
+// key wait for the first one. Failed lookups are not cached.
+type Cache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	nextPrune time.Time
+}
+
+// entry is a single cached lookup.
+type entry struct {
+	done    chan struct{}
+	val     any
+	err     error
+	expires time.Time
+}
+
+// New returns an empty cache whose entries expire after ttl.
+func New(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, entries: make(map[string]*entry)}
+}
+
+// Len returns the number of cached and in-flight lookups.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Get returns the value cached under key, calling fetch when there is none
+// or it has expired.
+func Get[T any](c *Cache, key string, fetch func() (T, error)) (T, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && e.expires.IsZero() {
+		// In flight: wait for the first lookup.
+		c.mu.Unlock()
+		<-e.done
+		v, _ := e.val.(T)
+		return v, e.err
+	}
+	now := time.Now()
+	if ok && now.Before(e.expires) {
+		c.mu.Unlock()
+		v, _ := e.val.(T)
+		return v, nil
+	}
+
+	c.prune(now)
+	e = &entry{done: make(chan struct{})}
+	c.entries[key] = e
+	c.mu.Unlock()
+
+	v, err := fetch()
+
+	c.mu.Lock()
+	e.val, e.err = v, err
+	if err != nil {
+		delete(c.entries, key)
+	} else {
+		e.expires = time.Now().Add(c.ttl)
+	}
+	c.mu.Unlock()
+	close(e.done)
+
+	return v, err
+}
+
+// prune drops expired entries, at most once per ttl, so a long-lived cache
+// does not grow with keys that are never looked up again. The caller must
+// hold c.mu.
+func (c *Cache) prune(now time.Time) {
+	if now.Before(c.nextPrune) {
+		return
+	}
+	for k, e := range c.entries {
+		if !e.expires.IsZero() && !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.nextPrune = now.Add(c.ttl)
+}
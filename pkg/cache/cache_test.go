@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	c := New(time.Hour)
+	var calls atomic.Int32
+	fetch := func() (int, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := Get(c, "k", fetch)
+			assert.NoError(t, err)
+			assert.Equal(t, 42, v)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load(), "concurrent lookups are coalesced")
+
+	v, err := Get(c, "k", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 42, v)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestGetErrorAndExpiry(t *testing.T) {
+	c := New(time.Hour)
+	_, err := Get(c, "k", func() (string, error) { return "", errors.New("boom") })
+	require.Error(t, err)
+
+	v, err := Get(c, "k", func() (string, error) { return "ok", nil })
+	require.NoError(t, err, "failed lookups are not cached")
+	assert.Equal(t, "ok", v)
+
+	c = New(0)
+	_, _ = Get(c, "k", func() (string, error) { return "old", nil })
+	v, _ = Get(c, "k", func() (string, error) { return "new", nil })
+	assert.Equal(t, "new", v, "expired entries are fetched again")
+}
+
+func TestPrune(t *testing.T) {
+	c := New(0)
+	for _, k := range []string{"a", "b", "c"} {
+		_, _ = Get(c, k, func() (string, error) { return k, nil })
+	}
+	assert.Equal(t, 1, c.Len(), "expired entries are dropped on the next miss")
+}
@@ -2,13 +2,22 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
 
+	"github.com/mchmarny/reputer/pkg/diff"
 	"github.com/mchmarny/reputer/pkg/logging"
+	"github.com/mchmarny/reputer/pkg/report"
 	"github.com/mchmarny/reputer/pkg/reporter"
+	"github.com/mchmarny/reputer/pkg/server"
 )
 
 // stringSlice implements flag.Value for repeated string flags.
@@ -22,17 +31,116 @@ func (s *stringSlice) Set(v string) error {
 
 const usageMsg = `
 Usage: reputer [options]
+       reputer verify --key <public-key> [--file <path>] <envelope>
+       reputer diff [diff options] <old-report> <new-report>
+       reputer schema [--file <path>]
+       reputer user <login> [--repo <repo>] [options]
+       reputer pr <owner/repo#number> [options]
+       reputer org <host/org> [org options] [options]
+       reputer deps [deps options] <manifest>...
+       reputer serve [serve options]
 
 Options:
-  --repo          Repo URI (required, e.g. github.com/owner/repo)
+  --repo          Repo URI, e.g. github.com/owner/repo (required unless --aggregate or --repos-file; repeatable, combines repos)
+  --aggregate     Saved JSON or YAML report, with stats, combined into the org-wide view (repeatable, optional)
+  --repos-file    File of repo URIs, one per line, each scanned into its own report; - reads stdin (optional)
+  --output-dir    Write one report per repo of --repos-file to this directory (optional, default: NDJSON to --file or stdout)
+  --concurrency   Repos of --repos-file scanned at once (optional, default: 4)
   --commit        Commit at which to end the report (optional, inclusive)
+  --as-of         Score authors as of this time, RFC 3339 or YYYY-MM-DD (optional, default: --commit date or now)
   --stats         Includes stats used to calculate reputation (optional)
+  --explain       Includes per-signal score breakdown, implied by --stats (optional)
   --file          Write output to file at this path (optional, stdout if not specified)
-  --format        Output format: json or yaml (optional, default: json)
+  --format        Output format: json, yaml, sarif, markdown, html, csv, tsv, or attestation (optional, default: json)
+  --signing-key   PEM ed25519 or ECDSA private key that signs attestation output (required for attestation)
+  --template      Go template file used for markdown or html output (optional, default: built-in)
+  --columns       Comma-separated columns for csv or tsv output (optional, default: all)
   --trusted-orgs  Org whose members get a scoring boost (repeatable, optional)
+  --trust         Trust file with allowlist, denylist, trusted orgs and email domains (optional)
+  --repo-trust    Also loads .github/reputer.yaml trust file from the repo's default branch (optional)
+  --dormancy-days Inactivity gap that flags a reactivated account, 0 disables detection (optional, default: 365)
+  --reactivation-window
+                  Days after first repo commit a reactivated account stays flagged (optional, default: 90)
+  --sensitive     Profiles per-author changes to CI, build, release, and dependency files (optional)
+  --sensitive-paths
+                  Path glob treated as sensitive, replaces defaults and implies --sensitive (repeatable, optional)
+  --model         Scoring model definition file, YAML or JSON (optional, default: built-in v3)
+  --model-version Built-in scoring model version, e.g. 3.2.0 (optional, default: latest)
+  --compare       Built-in model version or model file to also score with (repeatable, optional)
+  --policy        Policy file of scoring rules evaluated after scoring (optional)
+  --risk-threshold
+                  Reputation below which commits count as low-reputation in the summary (optional, default: 0.5)
+  --sort          Sort contributors by username, reputation, commits, last_commit, or age, with optional :asc or :desc (optional, default: username)
+  --top           Keep only the first N contributors after sorting (optional)
+  --min-score     Keep contributors with reputation at or above this score (optional)
+  --max-score     Keep contributors with reputation at or below this score (optional)
+  --author        Keep contributors whose login matches this glob, case-insensitive (repeatable, optional)
+  --tiers         Named reputation tiers as name=min pairs (optional, default: high=0.7,medium=0.4,low=0)
+  --fail-below    Exit with code 3 when the average reputation is below this score (optional)
+  --fail-if-any-below
+                  Apply --fail-below to each contributor instead of the average (optional)
   --debug         Turns logging verbose (optional)
   --version       Prints version only (optional)
 
+Exit codes: 0 success, 1 error, 2 invalid usage, 3 reputation below --fail-below.
+
+Verify options:
+  --key           PEM public key the attestation must be signed with (required)
+  --file          Write the verified report as JSON to file at this path (optional, stdout if not specified)
+
+Diff options:
+  --threshold     Minimum reputation change reported (optional, default: 0.1)
+  --format        Output format: text, json, or markdown (optional, default: text)
+  --file          Write output to file at this path (optional, stdout if not specified)
+
+User options:
+  <login>         Account to score, e.g. octocat or github.com/octocat (required)
+  --repo          Repo the account is scored against (optional, at most one; without it repo signals are not applicable)
+  --stats         Includes stats used to calculate reputation (optional, default: true)
+                  All other options above apply, except --aggregate.
+
+Pull request options:
+  <owner/repo#number>
+                  Pull request to score, also host/owner/repo#number or its URL (required)
+                  All other options above apply, except --repo, --aggregate, and --commit.
+
+Org options:
+  <host/org>      GitHub org or GitLab group, including its subgroups, whose repos are scanned, e.g. github.com/owner (required)
+  --include-archived
+                  Also scans archived repos (optional)
+  --include-forks Also scans forked repos (optional)
+  --visibility    Scan only public, private, or internal repos (optional, default: all)
+  --topic         Scan only repos with this topic (repeatable, optional, all must match)
+  --output-dir    Write one report per repo, and the org summary, to this directory (optional, default: NDJSON to --file or stdout)
+  --concurrency   Repos scanned at once (optional, default: 4)
+                  All other options above apply, except --repo, --aggregate, and --commit.
+
+Deps options:
+  <manifest>      go.mod, package.json, or requirements*.txt whose dependencies are scored (required, repeatable)
+  --direct-only   Skips Go modules marked indirect (optional)
+  --fixtures      Directory with repos.yaml mapping dependencies to repos and saved repo reports; no network access (optional)
+  --concurrency   Dependencies resolved and repos scanned at once (optional, default: 4)
+  --trusted-orgs  Org whose members get a scoring boost (repeatable, optional)
+  --risk-threshold
+                  Repo reputation below which a dependency counts as low-reputation (optional, default: 0.5)
+  --tiers         Named reputation tiers as name=min pairs (optional, default: high=0.7,medium=0.4,low=0)
+  --fail-below    Exit with code 3 when any dependency's repo reputation is below this score (optional)
+  --format        Output format: text, json, yaml, or markdown (optional, default: text)
+  --file          Write output to file at this path (optional, stdout if not specified)
+
+Serve options:
+  --addr          Address the API listens on (optional, default: 127.0.0.1:8080)
+  --ttl           How long reports are cached, e.g. 30m (optional, default: 15m)
+  --stats         Includes author stats in repo reports; user reports always include them (optional)
+  --trusted-orgs  Org whose members get a scoring boost (repeatable, optional)
+  --allow         Host (e.g. github.com) or org (e.g. github.com/acme) the API serves (repeatable, optional, default: any)
+  --insecure      Serves any request without a token or --allow, spending the server's provider tokens (optional)
+                  API requests must send the REPUTER_SERVER_TOKEN environment variable's value as a bearer token when it is set.
+                  The server refuses to start without REPUTER_SERVER_TOKEN, --allow, or --insecure.
+
+Schema options:
+  --file          Write the report JSON Schema to file at this path (optional, stdout if not specified)
+
 `
 
 var (
@@ -41,37 +149,146 @@ var (
 	commit  = "unknown"
 	date    = "unknown"
 
-	repo        string
+	repos       stringSlice
+	aggregate   stringSlice
+	reposFile   string
+	outputDir   string
+	concurrency int
+	inclArchive bool
+	inclForks   bool
+	visibility  string
+	topics      stringSlice
 	commitSHA   string
+	asOf        string
 	file        string
 	format      string
+	tmplFile    string
+	columns     string
 	trustedOrgs stringSlice
+	trustFile   string
+	repoTrust   bool
+	dormancy    int64
+	reactWindow int64
+	sensitive   bool
+	sensPaths   stringSlice
+	modelFile   string
+	modelVer    string
+	compare     stringSlice
+	policyFile  string
+	riskThresh  float64
+	signingKey  string
+	tiers       string
+	failBelow   float64
+	failAny     bool
+	sortBy      string
+	top         int
+	minScore    *float64
+	maxScore    *float64
+	authors     stringSlice
 	isDebug     bool
 	isVersion   bool
 	withStats   bool
+	withExplain bool
 )
 
 func init() {
-	flag.StringVar(&repo, "repo", "", "")
-	flag.StringVar(&commitSHA, "commit", "", "")
-	flag.BoolVar(&withStats, "stats", false, "")
-	flag.StringVar(&file, "file", "", "")
-	flag.StringVar(&format, "format", "json", "")
-	flag.Var(&trustedOrgs, "trusted-orgs", "")
-	flag.BoolVar(&isDebug, "debug", false, "")
+	registerReportFlags(flag.CommandLine)
+	flag.StringVar(&reposFile, "repos-file", "", "")
+	flag.StringVar(&outputDir, "output-dir", "", "")
+	flag.IntVar(&concurrency, "concurrency", reporter.DefaultConcurrency, "")
 	flag.BoolVar(&isVersion, "version", false, "")
 }
 
+// registerReportFlags binds the report options to fs so the root command
+// and report-producing subcommands share them.
+func registerReportFlags(fs *flag.FlagSet) {
+	fs.Var(&repos, "repo", "")
+	fs.Var(&aggregate, "aggregate", "")
+	fs.StringVar(&commitSHA, "commit", "", "")
+	fs.StringVar(&asOf, "as-of", "", "")
+	fs.BoolVar(&withStats, "stats", false, "")
+	fs.BoolVar(&withExplain, "explain", false, "")
+	fs.StringVar(&file, "file", "", "")
+	fs.StringVar(&format, "format", "json", "")
+	fs.StringVar(&tmplFile, "template", "", "")
+	fs.StringVar(&columns, "columns", "", "")
+	fs.Var(&trustedOrgs, "trusted-orgs", "")
+	fs.StringVar(&trustFile, "trust", "", "")
+	fs.BoolVar(&repoTrust, "repo-trust", false, "")
+	fs.Int64Var(&dormancy, "dormancy-days", report.DefaultDormancyDays, "")
+	fs.Int64Var(&reactWindow, "reactivation-window", report.DefaultReactivationWindowDays, "")
+	fs.BoolVar(&sensitive, "sensitive", false, "")
+	fs.Var(&sensPaths, "sensitive-paths", "")
+	fs.StringVar(&modelFile, "model", "", "")
+	fs.StringVar(&modelVer, "model-version", "", "")
+	fs.Var(&compare, "compare", "")
+	fs.StringVar(&policyFile, "policy", "", "")
+	fs.Float64Var(&riskThresh, "risk-threshold", report.DefaultRiskThreshold, "")
+	fs.StringVar(&signingKey, "signing-key", "", "")
+	fs.StringVar(&tiers, "tiers", "", "")
+	fs.Float64Var(&failBelow, "fail-below", 0, "")
+	fs.BoolVar(&failAny, "fail-if-any-below", false, "")
+	fs.StringVar(&sortBy, "sort", "", "")
+	fs.IntVar(&top, "top", 0, "")
+	fs.Func("min-score", "", scoreFlag(&minScore))
+	fs.Func("max-score", "", scoreFlag(&maxScore))
+	fs.Var(&authors, "author", "")
+	fs.BoolVar(&isDebug, "debug", false, "")
+}
+
 func usage() {
 	flag.Usage = func() {
 		fmt.Fprint(os.Stderr, usageMsg)
 	}
 	flag.Usage()
-	os.Exit(1)
+	os.Exit(exitUsage)
 }
 
 const appName = "reputer"
 
+// serverTokenEnv names the environment variable holding the bearer token
+// API clients must send; kept out of flags so it does not show in process
+// listings.
+const serverTokenEnv = "REPUTER_SERVER_TOKEN"
+
+// Exit codes.
+const (
+	exitError          = 1
+	exitUsage          = 2 // also used by the flag package for invalid flags
+	exitBelowThreshold = 3
+)
+
+// exitCode maps a command error to the process exit code.
+func exitCode(err error) int {
+	if errors.Is(err, reporter.ErrBelowThreshold) {
+		return exitBelowThreshold
+	}
+	return exitError
+}
+
+// scoreFlag returns a flag setter that parses an optional score bound.
+func scoreFlag(p **float64) func(string) error {
+	return func(v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid score %q: %w", v, err)
+		}
+		*p = &f
+		return nil
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func initLogging() {
 	logLevel := "info"
 	if isDebug {
@@ -87,6 +304,35 @@ func initLogging() {
 
 // Execute runs the reputer CLI.
 func Execute() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "verify":
+			executeVerify(os.Args[2:])
+			return
+		case "diff":
+			executeDiff(os.Args[2:])
+			return
+		case "schema":
+			executeSchema(os.Args[2:])
+			return
+		case "user":
+			executeUser(os.Args[2:])
+			return
+		case "pr":
+			executePullRequest(os.Args[2:])
+			return
+		case "org":
+			executeOrg(os.Args[2:])
+			return
+		case "deps":
+			executeDeps(os.Args[2:])
+			return
+		case "serve":
+			executeServe(os.Args[2:])
+			return
+		}
+	}
+
 	flag.Parse()
 	initLogging()
 
@@ -95,22 +341,347 @@ func Execute() {
 		os.Exit(0)
 	}
 
-	if repo == "" {
+	if len(repos) == 0 && len(aggregate) == 0 && reposFile == "" {
 		slog.Error("repo is required")
 		usage()
 	}
 
+	opt := reportOptions()
+	opt.ReposFile = reposFile
+	opt.OutputDir = outputDir
+	opt.Concurrency = concurrency
+
+	if err := reporter.ListCommitAuthors(context.Background(), opt); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// reportOptions builds the report options from the parsed report flags.
+func reportOptions() *reporter.ListCommitAuthorsOptions {
 	opt := &reporter.ListCommitAuthorsOptions{
-		Repo:        repo,
+		Reports:     aggregate,
 		Commit:      commitSHA,
+		AsOf:        asOf,
 		Stats:       withStats,
+		Explain:     withExplain,
 		File:        file,
 		Format:      format,
+		Template:    tmplFile,
+		Columns:     splitList(columns),
 		TrustedOrgs: trustedOrgs,
+		Trust:       trustFile,
+		RepoTrust:   repoTrust,
+
+		DormancyDays:           &dormancy,
+		ReactivationWindowDays: &reactWindow,
+
+		Sensitive:      sensitive,
+		SensitivePaths: sensPaths,
+
+		Model:         modelFile,
+		ModelVersion:  modelVer,
+		CompareModels: compare,
+
+		Policy: policyFile,
+
+		RiskThreshold: &riskThresh,
+
+		SigningKey: signingKey,
+
+		Tiers:          tiers,
+		FailBelow:      failBelow,
+		FailIfAnyBelow: failAny,
+
+		Sort:     sortBy,
+		Top:      top,
+		MinScore: minScore,
+		MaxScore: maxScore,
+		Authors:  authors,
+	}
+
+	if len(repos) > 0 {
+		opt.Repo = repos[0]
+		opt.Repos = repos[1:]
+	}
+
+	return opt
+}
+
+// executeUser runs the user subcommand.
+func executeUser(args []string) {
+	fs := flag.NewFlagSet("user", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usageMsg) }
+	registerReportFlags(fs)
+	withStats = true // a single account is looked up for its stats
+
+	pos := parseArgs(fs, args)
+
+	initLogging()
+
+	if len(pos) != 1 {
+		slog.Error("exactly one user login is required")
+		usage()
+	}
+
+	opt := reportOptions()
+	opt.User = pos[0]
+
+	if err := reporter.ListCommitAuthors(context.Background(), opt); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// executePullRequest runs the pr subcommand.
+func executePullRequest(args []string) {
+	fs := flag.NewFlagSet("pr", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usageMsg) }
+	registerReportFlags(fs)
+
+	pos := parseArgs(fs, args)
+
+	initLogging()
+
+	if len(pos) != 1 {
+		slog.Error("exactly one pull request is required")
+		usage()
+	}
+	if len(repos) > 0 || len(aggregate) > 0 {
+		slog.Error("pull request names its repo; --repo and --aggregate are not supported")
+		usage()
+	}
+
+	repo, num, err := report.ParsePullRequest(pos[0])
+	if err != nil {
+		slog.Error(err.Error())
+		usage()
+	}
+
+	opt := reportOptions()
+	opt.Repo = repo
+	opt.PullRequest = num
+
+	if err := reporter.ListCommitAuthors(context.Background(), opt); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// executeOrg runs the org subcommand.
+func executeOrg(args []string) {
+	fs := flag.NewFlagSet("org", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usageMsg) }
+	registerReportFlags(fs)
+	fs.StringVar(&outputDir, "output-dir", "", "")
+	fs.IntVar(&concurrency, "concurrency", reporter.DefaultConcurrency, "")
+	fs.BoolVar(&inclArchive, "include-archived", false, "")
+	fs.BoolVar(&inclForks, "include-forks", false, "")
+	fs.StringVar(&visibility, "visibility", "", "")
+	fs.Var(&topics, "topic", "")
+
+	pos := parseArgs(fs, args)
+
+	initLogging()
+
+	if len(pos) != 1 {
+		slog.Error("exactly one org is required")
+		usage()
+	}
+	if len(repos) > 0 || len(aggregate) > 0 {
+		slog.Error("org scans its own repos; --repo and --aggregate are not supported")
+		usage()
+	}
+
+	host, org, err := report.ParseOrg(pos[0])
+	if err != nil {
+		slog.Error(err.Error())
+		usage()
+	}
+
+	opt := reportOptions()
+	opt.Org = host + "/" + org
+	opt.OutputDir = outputDir
+	opt.Concurrency = concurrency
+	opt.RepoFilter = report.RepoFilter{
+		Archived:   inclArchive,
+		Forks:      inclForks,
+		Visibility: visibility,
+		Topics:     topics,
 	}
 
 	if err := reporter.ListCommitAuthors(context.Background(), opt); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// parseArgs parses fs from args, allowing flags after positional arguments,
+// and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var pos []string
+	for {
+		_ = fs.Parse(args) // ExitOnError
+		if fs.NArg() == 0 {
+			return pos
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// executeVerify runs the verify subcommand.
+func executeVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usageMsg) }
+
+	opt := &reporter.VerifyAttestationOptions{}
+	fs.StringVar(&opt.Key, "key", "", "")
+	fs.StringVar(&opt.File, "file", "", "")
+	fs.BoolVar(&isDebug, "debug", false, "")
+	_ = fs.Parse(args) // ExitOnError
+
+	initLogging()
+
+	if fs.NArg() != 1 {
+		slog.Error("exactly one envelope file is required")
+		usage()
+	}
+	opt.Envelope = fs.Arg(0)
+
+	if err := reporter.VerifyAttestation(opt); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// executeDiff runs the diff subcommand.
+func executeDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usageMsg) }
+
+	opt := &reporter.DiffReportsOptions{}
+	fs.Float64Var(&opt.Threshold, "threshold", diff.DefaultThreshold, "")
+	fs.StringVar(&opt.Format, "format", "text", "")
+	fs.StringVar(&opt.File, "file", "", "")
+	fs.BoolVar(&isDebug, "debug", false, "")
+	_ = fs.Parse(args) // ExitOnError
+
+	initLogging()
+
+	if fs.NArg() != 2 {
+		slog.Error("old and new report files are required")
+		usage()
+	}
+	opt.Old = fs.Arg(0)
+	opt.New = fs.Arg(1)
+
+	if err := reporter.DiffReports(opt); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// executeDeps runs the deps subcommand.
+func executeDeps(args []string) {
+	fs := flag.NewFlagSet("deps", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usageMsg) }
+
+	opt := &reporter.ScanDependenciesOptions{}
+	var orgs stringSlice
+	fs.BoolVar(&opt.DirectOnly, "direct-only", false, "")
+	fs.StringVar(&opt.Fixtures, "fixtures", "", "")
+	fs.IntVar(&opt.Concurrency, "concurrency", reporter.DefaultConcurrency, "")
+	fs.Var(&orgs, "trusted-orgs", "")
+	riskThreshold := report.DefaultRiskThreshold
+	opt.RiskThreshold = &riskThreshold
+	fs.Float64Var(opt.RiskThreshold, "risk-threshold", report.DefaultRiskThreshold, "")
+	fs.StringVar(&opt.Tiers, "tiers", "", "")
+	fs.Float64Var(&opt.FailBelow, "fail-below", 0, "")
+	fs.StringVar(&opt.Format, "format", "text", "")
+	fs.StringVar(&opt.File, "file", "", "")
+	fs.BoolVar(&isDebug, "debug", false, "")
+
+	pos := parseArgs(fs, args)
+
+	initLogging()
+
+	if len(pos) == 0 {
+		slog.Error("at least one manifest is required")
+		usage()
+	}
+	opt.Manifests = pos
+	opt.TrustedOrgs = orgs
+
+	if err := reporter.ScanDependencies(context.Background(), opt); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// executeServe runs the serve subcommand until interrupted.
+func executeServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usageMsg) }
+
+	opt := server.Options{AuthToken: os.Getenv(serverTokenEnv)}
+	var orgs, allow stringSlice
+	fs.StringVar(&opt.Addr, "addr", server.DefaultAddr, "")
+	fs.DurationVar(&opt.TTL, "ttl", server.DefaultTTL, "")
+	fs.BoolVar(&opt.Stats, "stats", false, "")
+	fs.Var(&orgs, "trusted-orgs", "")
+	fs.Var(&allow, "allow", "")
+	fs.BoolVar(&opt.Insecure, "insecure", false, "")
+	fs.BoolVar(&isDebug, "debug", false, "")
+	_ = fs.Parse(args) // ExitOnError
+
+	initLogging()
+
+	if fs.NArg() != 0 {
+		slog.Error("serve takes no arguments")
+		usage()
+	}
+	if opt.TTL <= 0 {
+		slog.Error("ttl must be positive", "ttl", opt.TTL)
+		usage()
+	}
+	for _, a := range allow {
+		if parts := strings.Split(a, "/"); len(parts) > 2 || slices.Contains(parts, "") {
+			slog.Error("allow must be a host or host/org", "allow", a)
+			usage()
+		}
+	}
+	opt.TrustedOrgs = orgs
+	opt.Allow = allow
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := server.New(opt).ListenAndServe(ctx)
+	stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// executeSchema runs the schema subcommand.
+func executeSchema(args []string) {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usageMsg) }
+
+	var out string
+	fs.StringVar(&out, "file", "", "")
+	fs.BoolVar(&isDebug, "debug", false, "")
+	_ = fs.Parse(args) // ExitOnError
+
+	initLogging()
+
+	if fs.NArg() != 0 {
+		slog.Error("schema takes no arguments")
+		usage()
+	}
+
+	if err := reporter.WriteSchema(out); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitError)
 	}
 }
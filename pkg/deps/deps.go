@@ -0,0 +1,171 @@
+package deps
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/report"
+)
+
+// maxWeakest is the number of lowest-reputation authors listed per
+// dependency.
+const maxWeakest = 3
+
+// Report is the contributor risk of a project's dependencies.
+type Report struct {
+	GeneratedOn   time.Time     `json:"generated_on" yaml:"generatedOn"`
+	Manifests     []string      `json:"manifests" yaml:"manifests"`
+	RiskThreshold float64       `json:"risk_threshold" yaml:"riskThreshold"`
+	Tiers         []report.Tier `json:"tiers,omitempty" yaml:"tiers,omitempty"`
+	Summary       *Summary      `json:"summary" yaml:"summary"`
+	Dependencies  []*Result     `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+}
+
+// Summary counts the dependencies by outcome.
+type Summary struct {
+	Dependencies int `json:"dependencies" yaml:"dependencies"`
+	// Repos is the number of distinct source repos scored.
+	Repos int `json:"repos" yaml:"repos"`
+	// Unscored is the number of dependencies whose repo was not resolved
+	// or could not be scanned.
+	Unscored int `json:"unscored" yaml:"unscored"`
+	// LowReputation is the number of dependencies whose repo reputation is
+	// below the risk threshold.
+	LowReputation int `json:"low_reputation" yaml:"lowReputation"`
+	// Tiers counts the scored dependencies per reputation tier.
+	Tiers map[string]int `json:"tiers,omitempty" yaml:"tiers,omitempty"`
+}
+
+// Result is the outcome of assessing one dependency.
+type Result struct {
+	Dependency `yaml:",inline"`
+	Repo       string `json:"repo,omitempty" yaml:"repo,omitempty"`
+	// Error explains why the dependency has no risk: its repo was not
+	// resolved or could not be scanned.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	Risk  *Risk  `json:"risk,omitempty" yaml:"risk,omitempty"`
+}
+
+// Risk summarizes the contributors of a dependency's source repo.
+type Risk struct {
+	// Reputation is the repo's author reputation averaged over commits.
+	Reputation        float64 `json:"reputation" yaml:"reputation"`
+	Tier              string  `json:"tier" yaml:"tier"`
+	LowReputation     bool    `json:"low_reputation,omitempty" yaml:"lowReputation,omitempty"`
+	TotalCommits      int64   `json:"total_commits" yaml:"totalCommits"`
+	TotalContributors int64   `json:"total_contributors" yaml:"totalContributors"`
+	// Summary is the repo summary, when the report carries author stats.
+	Summary *report.Summary `json:"summary,omitempty" yaml:"summary,omitempty"`
+	// Weakest lists the repo's lowest-reputation authors, lowest first.
+	Weakest []Author `json:"weakest,omitempty" yaml:"weakest,omitempty"`
+}
+
+// Author is a contributor of a dependency's repo.
+type Author struct {
+	Username   string  `json:"username" yaml:"username"`
+	Reputation float64 `json:"reputation" yaml:"reputation"`
+}
+
+// Assess summarizes the reputation report of a dependency's repo. The
+// repo summary is computed when the report has none but carries author
+// stats; without stats the reputation is the plain author average.
+func Assess(r *report.Report, tiers []report.Tier, threshold float64) *Risk {
+	s := r.Summary
+	if s == nil && hasStats(r) {
+		s = report.Summarize(r.Contributors, threshold)
+	}
+
+	rk := &Risk{
+		TotalCommits:      r.TotalCommits,
+		TotalContributors: r.TotalContributors,
+		Summary:           s,
+	}
+	if s != nil {
+		rk.Reputation = s.AvgReputation
+	} else {
+		rk.Reputation = averageReputation(r.Contributors)
+	}
+	rk.Tier = report.TierFor(tiers, rk.Reputation)
+	rk.LowReputation = rk.Reputation < threshold
+
+	authors := make([]Author, 0, len(r.Contributors))
+	for _, a := range r.Contributors {
+		if a != nil {
+			authors = append(authors, Author{Username: a.Username, Reputation: a.Reputation})
+		}
+	}
+	sort.SliceStable(authors, func(i, j int) bool {
+		if authors[i].Reputation != authors[j].Reputation {
+			return authors[i].Reputation < authors[j].Reputation
+		}
+		return authors[i].Username < authors[j].Username
+	})
+	if len(authors) > maxWeakest {
+		authors = authors[:maxWeakest]
+	}
+	rk.Weakest = authors
+
+	return rk
+}
+
+// New returns the report of the assessed dependencies, in the given order,
+// with its summary.
+func New(manifests []string, results []*Result, tiers []report.Tier, threshold float64) *Report {
+	if len(tiers) == 0 {
+		tiers = report.DefaultTiers
+	}
+
+	d := &Report{
+		GeneratedOn:   time.Now().UTC(),
+		Manifests:     manifests,
+		RiskThreshold: threshold,
+		Tiers:         tiers,
+		Dependencies:  results,
+		Summary:       &Summary{Dependencies: len(results)},
+	}
+
+	repos := make(map[string]bool)
+	for _, r := range results {
+		if r.Risk == nil {
+			d.Summary.Unscored++
+			continue
+		}
+		repos[r.Repo] = true
+		if r.Risk.LowReputation {
+			d.Summary.LowReputation++
+		}
+		if d.Summary.Tiers == nil {
+			d.Summary.Tiers = make(map[string]int)
+		}
+		d.Summary.Tiers[r.Risk.Tier]++
+	}
+	d.Summary.Repos = len(repos)
+
+	return d
+}
+
+// hasStats reports whether any author of the report carries stats.
+func hasStats(r *report.Report) bool {
+	for _, a := range r.Contributors {
+		if a != nil && a.Stats != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// averageReputation returns the plain average reputation of the authors.
+func averageReputation(authors []*report.Author) float64 {
+	var sum float64
+	var n int
+	for _, a := range authors {
+		if a != nil {
+			sum += a.Reputation
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return report.ToFixed(sum/float64(n), 2)
+}
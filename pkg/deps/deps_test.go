@@ -0,0 +1,101 @@
+package deps
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() *report.Report {
+	return &report.Report{
+		Repo:              "github.com/o/a",
+		TotalCommits:      10,
+		TotalContributors: 5,
+		Contributors: []*report.Author{
+			{Username: "a", Reputation: 0.9, Stats: &report.Stats{Commits: 6}},
+			{Username: "b", Reputation: 0.2, Stats: &report.Stats{Commits: 2}},
+			{Username: "c", Reputation: 0.4, Stats: &report.Stats{Commits: 1}},
+			{Username: "d", Reputation: 0.3, Stats: &report.Stats{Commits: 1}},
+			nil,
+		},
+	}
+}
+
+func TestAssess(t *testing.T) {
+	rk := Assess(testReport(), nil, 0.5)
+	require.NotNil(t, rk.Summary, "summary is computed from stats")
+	assert.InDelta(t, 0.65, rk.Reputation, 0.001)
+	assert.Equal(t, "medium", rk.Tier)
+	assert.False(t, rk.LowReputation)
+	assert.Equal(t, int64(10), rk.TotalCommits)
+	assert.Equal(t, []Author{{"b", 0.2}, {"d", 0.3}, {"c", 0.4}}, rk.Weakest)
+
+	r := testReport()
+	r.StripDetails(false)
+	rk = Assess(r, nil, 0.5)
+	assert.Nil(t, rk.Summary)
+	assert.InDelta(t, 0.45, rk.Reputation, 0.001, "without stats the plain average is used")
+
+	r.Summary = &report.Summary{AvgReputation: 0.35}
+	rk = Assess(r, nil, 0.5)
+	assert.InDelta(t, 0.35, rk.Reputation, 0.001, "the report summary is kept")
+	assert.True(t, rk.LowReputation)
+	assert.Equal(t, "low", rk.Tier)
+}
+
+func testDeps() *Report {
+	return New([]string{"go.mod"}, []*Result{
+		{Dependency: Dependency{Name: "github.com/o/a", Version: "v1.0.0", Ecosystem: EcosystemGo}, Repo: "github.com/o/a", Risk: Assess(testReport(), nil, 0.5)},
+		{Dependency: Dependency{Name: "github.com/o/a/sub", Ecosystem: EcosystemGo}, Repo: "github.com/o/a", Risk: &Risk{Reputation: 0.2, Tier: "low", LowReputation: true, Weakest: []Author{{"x", 0.1}}}},
+		{Dependency: Dependency{Name: "golang.org/x/sync", Ecosystem: EcosystemGo}, Error: "source repo not found:\nno | mapping"},
+	}, nil, 0.5)
+}
+
+func TestNew(t *testing.T) {
+	d := testDeps()
+	assert.Equal(t, report.DefaultTiers, d.Tiers)
+	assert.Equal(t, &Summary{
+		Dependencies:  3,
+		Repos:         1,
+		Unscored:      1,
+		LowReputation: 1,
+		Tiers:         map[string]int{"medium": 1, "low": 1},
+	}, d.Summary)
+}
+
+func TestText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Text(&buf, testDeps()))
+	out := buf.String()
+
+	assert.Contains(t, out, "go.mod: 3 dependencies from 1 repos, 1 low reputation, 1 not scored (medium 1, low 1)")
+	assert.Contains(t, out, "github.com/o/a@v1.0.0")
+	assert.Contains(t, out, "! github.com/o/a/sub")
+	assert.Contains(t, out, "lowest: x 0.10")
+	assert.Contains(t, out, "? golang.org/x/sync")
+	assert.Contains(t, out, "source repo not found:; no | mapping")
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, testDeps()))
+	out := buf.String()
+
+	assert.Contains(t, out, "## Dependency reputation for `go.mod`")
+	assert.Contains(t, out, "| `github.com/o/a` | v1.0.0 | github.com/o/a | 0.65 | 5 | 40% | @b 0.20, @d 0.30, @c 0.40 |")
+	assert.Contains(t, out, "### Not scored")
+	assert.Contains(t, out, "| `golang.org/x/sync` | source repo not found:; no \\| mapping |")
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, JSON(&buf, testDeps()))
+	assert.Contains(t, buf.String(), `"name":"github.com/o/a","version":"v1.0.0","ecosystem":"go","repo":"github.com/o/a","risk":{"reputation":0.65`)
+
+	buf.Reset()
+	require.NoError(t, YAML(&buf, testDeps()))
+	assert.Contains(t, buf.String(), "    - name: github.com/o/a\n      version: v1.0.0\n")
+}
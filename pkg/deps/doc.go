@@ -0,0 +1,9 @@
+// Package deps assesses the contributor risk of a project's dependencies.
+//
+// It parses dependency manifests (go.mod, package.json, and
+// requirements.txt), maps each dependency to the repo its source lives in,
+// and summarizes the reputation report of that repo into a per-dependency
+// risk. Repos can be resolved online, through Go vanity imports and the
+// npm and PyPI registries, or offline from a fixture mapping. The result
+// can be written as text, JSON, YAML, or Markdown.
+package deps
@@ -0,0 +1,152 @@
+package deps
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mchmarny/reputer/pkg/render"
+	"gopkg.in/yaml.v3"
+)
+
+// JSON writes the report as JSON.
+func JSON(w io.Writer, d *Report) error {
+	if err := json.NewEncoder(w).Encode(d); err != nil {
+		return fmt.Errorf("error encoding dependencies: %w", err)
+	}
+	return nil
+}
+
+// YAML writes the report as YAML.
+func YAML(w io.Writer, d *Report) error {
+	if err := yaml.NewEncoder(w).Encode(d); err != nil {
+		return fmt.Errorf("error encoding dependencies: %w", err)
+	}
+	return nil
+}
+
+// Text writes the report as plain text, one dependency per line.
+func Text(w io.Writer, d *Report) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "%s: %s\n", strings.Join(d.Manifests, ", "), summaryLine(d))
+
+	for _, r := range d.Dependencies {
+		name := r.Name
+		if r.Version != "" {
+			name += "@" + r.Version
+		}
+		if r.Risk == nil {
+			fmt.Fprintf(b, "  ? %-48s %s\n", name, oneLine(r.Error))
+			continue
+		}
+		fmt.Fprintf(b, "  %s %-48s %.2f %-8s %s%s\n", riskMarker(r.Risk), name,
+			r.Risk.Reputation, r.Risk.Tier, r.Repo, weakestSummary(r.Risk))
+	}
+
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("error writing dependencies: %w", err)
+	}
+	return nil
+}
+
+// Markdown writes the report as Markdown with score-tier badges.
+func Markdown(w io.Writer, d *Report) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "## Dependency reputation for %s\n\n", codeList(d.Manifests))
+	fmt.Fprintf(b, "%s\n", summaryLine(d))
+
+	var unscored []*Result
+	scored := false
+	for _, r := range d.Dependencies {
+		if r.Risk == nil {
+			unscored = append(unscored, r)
+			continue
+		}
+		if !scored {
+			fmt.Fprintf(b, "\n| | Dependency | Version | Repo | Reputation | Contributors | Low-reputation commits | Lowest |\n| --- | --- | --- | --- | --- | --- | --- | --- |\n")
+			scored = true
+		}
+		var lowShare string
+		if r.Risk.Summary != nil {
+			lowShare = fmt.Sprintf("%.0f%%", r.Risk.Summary.LowReputationCommitShare*100)
+		}
+		lowest := make([]string, 0, len(r.Risk.Weakest))
+		for _, a := range r.Risk.Weakest {
+			lowest = append(lowest, fmt.Sprintf("@%s %.2f", a.Username, a.Reputation))
+		}
+		fmt.Fprintf(b, "| %s | `%s` | %s | %s | %.2f | %d | %s | %s |\n",
+			render.Badge(d.Tiers, r.Risk.Reputation), r.Name, r.Version, r.Repo,
+			r.Risk.Reputation, r.Risk.TotalContributors, lowShare, strings.Join(lowest, ", "))
+	}
+
+	if len(unscored) > 0 {
+		fmt.Fprintf(b, "\n### Not scored\n\n| Dependency | Reason |\n| --- | --- |\n")
+		for _, r := range unscored {
+			fmt.Fprintf(b, "| `%s` | %s |\n", r.Name, strings.ReplaceAll(oneLine(r.Error), "|", "\\|"))
+		}
+	}
+
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("error writing dependencies: %w", err)
+	}
+	return nil
+}
+
+// summaryLine describes the summary counts on one line, with the tier
+// counts from highest to lowest tier.
+func summaryLine(d *Report) string {
+	s := d.Summary
+	line := fmt.Sprintf("%d dependencies from %d repos, %d low reputation, %d not scored",
+		s.Dependencies, s.Repos, s.LowReputation, s.Unscored)
+
+	parts := make([]string, 0, len(d.Tiers))
+	for _, t := range d.Tiers {
+		if n := s.Tiers[t.Name]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", t.Name, n))
+		}
+	}
+	if len(parts) > 0 {
+		line += " (" + strings.Join(parts, ", ") + ")"
+	}
+
+	return line
+}
+
+// riskMarker flags low-reputation dependencies in text output.
+func riskMarker(r *Risk) string {
+	if r.LowReputation {
+		return "!"
+	}
+	return " "
+}
+
+// weakestSummary lists the lowest-reputation authors of a low-reputation
+// dependency in text output.
+func weakestSummary(r *Risk) string {
+	if !r.LowReputation || len(r.Weakest) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(r.Weakest))
+	for _, a := range r.Weakest {
+		parts = append(parts, fmt.Sprintf("%s %.2f", a.Username, a.Reputation))
+	}
+	return "  lowest: " + strings.Join(parts, ", ")
+}
+
+// codeList formats the names as inline code, comma-separated.
+func codeList(names []string) string {
+	parts := make([]string, 0, len(names))
+	for _, n := range names {
+		parts = append(parts, "`"+n+"`")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// oneLine joins the lines of a multi-line message.
+func oneLine(s string) string {
+	return strings.ReplaceAll(s, "\n", "; ")
+}
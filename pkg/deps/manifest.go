@@ -0,0 +1,220 @@
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// Dependency ecosystems.
+const (
+	EcosystemGo   = "go"
+	EcosystemNPM  = "npm"
+	EcosystemPyPI = "pypi"
+)
+
+// Dependency is a package a project depends on.
+type Dependency struct {
+	Name      string `json:"name" yaml:"name"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	Ecosystem string `json:"ecosystem" yaml:"ecosystem"`
+	// Indirect is set for Go modules required only by other dependencies.
+	Indirect bool `json:"indirect,omitempty" yaml:"indirect,omitempty"`
+	// Dev is set for npm packages needed only for development.
+	Dev bool `json:"dev,omitempty" yaml:"dev,omitempty"`
+}
+
+// key identifies the dependency across manifests.
+func (d Dependency) key() string {
+	return d.Ecosystem + ":" + d.Name
+}
+
+// ParseManifest reads the dependencies of a go.mod, package.json, or
+// requirements file, chosen by the file name. Requirements files are
+// matched by the requirements*.txt pattern.
+func ParseManifest(path string) ([]Dependency, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // G304: path is a user-supplied manifest
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest %s: %w", path, err)
+	}
+
+	var list []Dependency
+	name := filepath.Base(path)
+	switch {
+	case name == "go.mod":
+		list, err = ParseGoMod(bytes.NewReader(b))
+	case name == "package.json":
+		list, err = ParsePackageJSON(bytes.NewReader(b))
+	case strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt"):
+		list, err = ParseRequirements(bytes.NewReader(b))
+	default:
+		return nil, fmt.Errorf("unsupported manifest: %s (must be go.mod, package.json, or requirements*.txt)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing manifest %s: %w", path, err)
+	}
+
+	return list, nil
+}
+
+// ParseGoMod parses the requirements of a go.mod file. Modules replaced
+// by another module, for all versions or for the required version, are
+// reported under the replacement's path and version; modules replaced by a
+// local directory are dropped.
+func ParseGoMod(r io.Reader) ([]Dependency, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading go.mod: %w", err)
+	}
+
+	f, err := modfile.Parse("go.mod", b, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Dependency, 0, len(f.Require))
+	for _, req := range f.Require {
+		mod, ok := replacement(f.Replace, req.Mod)
+		if !ok {
+			continue
+		}
+		list = append(list, Dependency{
+			Name:      mod.Path,
+			Version:   mod.Version,
+			Ecosystem: EcosystemGo,
+			Indirect:  req.Indirect,
+		})
+	}
+
+	return list, nil
+}
+
+// replacement returns the module that replaces mod, or mod itself when it
+// is not replaced. A replacement of the exact version wins over one of all
+// versions. It returns false when mod is replaced by a local directory,
+// which go.mod records as a replacement without a version.
+func replacement(replaces []*modfile.Replace, mod module.Version) (module.Version, bool) {
+	var match *modfile.Replace
+	for _, rep := range replaces {
+		if rep.Old.Path != mod.Path {
+			continue
+		}
+		if rep.Old.Version == mod.Version {
+			match = rep
+			break
+		}
+		if rep.Old.Version == "" {
+			match = rep
+		}
+	}
+
+	switch {
+	case match == nil:
+		return mod, true
+	case match.New.Version == "":
+		return module.Version{}, false
+	default:
+		return match.New, true
+	}
+}
+
+// packageJSON is the part of package.json that lists dependencies.
+type packageJSON struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+}
+
+// ParsePackageJSON parses the dependencies, optional dependencies, and dev
+// dependencies of a package.json file, sorted by name.
+func ParsePackageJSON(r io.Reader) ([]Dependency, error) {
+	var p packageJSON
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, fmt.Errorf("error decoding package.json: %w", err)
+	}
+
+	var list []Dependency
+	add := func(m map[string]string, dev bool) {
+		for name, version := range m {
+			list = append(list, Dependency{Name: name, Version: version, Ecosystem: EcosystemNPM, Dev: dev})
+		}
+	}
+	add(p.Dependencies, false)
+	add(p.OptionalDependencies, false)
+	add(p.DevDependencies, true)
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list, nil
+}
+
+// requirementName matches the project name at the start of a requirement.
+var requirementName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+
+// pythonSeparators are the runs of characters PEP 503 normalizes to "-".
+var pythonSeparators = regexp.MustCompile(`[-_.]+`)
+
+// ParseRequirements parses a pip requirements file. Options (such as -r
+// and -e), URLs, and local paths are skipped. Names are normalized as in
+// PEP 503, and the version is set for pinned (==) requirements.
+func ParseRequirements(r io.Reader) ([]Dependency, error) {
+	var list []Dependency
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line, _, _ := strings.Cut(s.Text(), " #")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+
+		name := requirementName.FindString(line)
+		if name == "" {
+			continue
+		}
+
+		d := Dependency{
+			Name:      strings.ToLower(pythonSeparators.ReplaceAllString(name, "-")),
+			Ecosystem: EcosystemPyPI,
+		}
+		if _, v, ok := strings.Cut(line, "=="); ok {
+			v, _, _ = strings.Cut(v, ";")
+			v, _, _ = strings.Cut(v, ",")
+			d.Version = strings.TrimSpace(v)
+		}
+		list = append(list, d)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Merge combines the dependencies of several manifests, keeping the first
+// of each name per ecosystem. With directOnly, indirect Go modules are
+// skipped.
+func Merge(lists [][]Dependency, directOnly bool) []Dependency {
+	var out []Dependency
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, d := range list {
+			if (directOnly && d.Indirect) || seen[d.key()] {
+				continue
+			}
+			seen[d.key()] = true
+			out = append(out, d)
+		}
+	}
+	return out
+}
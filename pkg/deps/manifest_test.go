@@ -0,0 +1,118 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGoMod = `module example.com/app
+
+go 1.24
+
+require github.com/o/single v1.0.0
+
+require (
+	"github.com/o/quoted" v0.2.0
+	golang.org/x/sync v0.19.0 // indirect
+	example.com/old v1.1.0
+	example.com/local v0.0.0
+	example.com/pinned v1.0.0 // a comment; indirect is not a marker here
+)
+
+// Replacements of one version apply only to that version.
+replace (
+	example.com/old v1.1.0 => github.com/o/new v1.2.0 // exact version
+	example.com/old => github.com/o/other v2.0.0
+	example.com/local => ../local
+	example.com/pinned v0.9.0 => github.com/o/fork v0.9.1
+)
+
+retract [v0.1.0, v0.1.5]
+`
+
+func TestParseGoMod(t *testing.T) {
+	list, err := ParseGoMod(strings.NewReader(testGoMod))
+	require.NoError(t, err)
+	assert.Equal(t, []Dependency{
+		{Name: "github.com/o/single", Version: "v1.0.0", Ecosystem: EcosystemGo},
+		{Name: "github.com/o/quoted", Version: "v0.2.0", Ecosystem: EcosystemGo},
+		{Name: "golang.org/x/sync", Version: "v0.19.0", Ecosystem: EcosystemGo, Indirect: true},
+		{Name: "github.com/o/new", Version: "v1.2.0", Ecosystem: EcosystemGo},
+		{Name: "example.com/pinned", Version: "v1.0.0", Ecosystem: EcosystemGo},
+	}, list, "an exact-version replace wins, and one of another version does not apply")
+
+	_, err = ParseGoMod(strings.NewReader("require github.com/o/a\n"))
+	require.ErrorContains(t, err, "go.mod:1: usage: require")
+}
+
+func TestParsePackageJSON(t *testing.T) {
+	list, err := ParsePackageJSON(strings.NewReader(`{
+		"name": "app",
+		"dependencies": {"react": "^18.0.0", "@scope/pkg": "1.0.0"},
+		"devDependencies": {"jest": "29.0.0"}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, []Dependency{
+		{Name: "@scope/pkg", Version: "1.0.0", Ecosystem: EcosystemNPM},
+		{Name: "jest", Version: "29.0.0", Ecosystem: EcosystemNPM, Dev: true},
+		{Name: "react", Version: "^18.0.0", Ecosystem: EcosystemNPM},
+	}, list)
+
+	_, err = ParsePackageJSON(strings.NewReader("{"))
+	require.ErrorContains(t, err, "error decoding package.json")
+}
+
+func TestParseRequirements(t *testing.T) {
+	list, err := ParseRequirements(strings.NewReader(`# pinned
+Requests==2.31.0 ; python_version >= "3.8"
+zope.interface>=5,<7
+-r other.txt
+-e git+https://github.com/o/r.git#egg=r
+https://example.com/pkg.tar.gz
+flask  # web
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []Dependency{
+		{Name: "requests", Version: "2.31.0", Ecosystem: EcosystemPyPI},
+		{Name: "zope-interface", Ecosystem: EcosystemPyPI},
+		{Name: "flask", Ecosystem: EcosystemPyPI},
+	}, list)
+}
+
+func TestParseManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "requirements-dev.txt")
+	require.NoError(t, os.WriteFile(path, []byte("pytest\n"), 0o600))
+
+	list, err := ParseManifest(path)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "pytest", list[0].Name)
+
+	path = filepath.Join(dir, "Cargo.toml")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	_, err = ParseManifest(path)
+	require.ErrorContains(t, err, "unsupported manifest")
+
+	_, err = ParseManifest(filepath.Join(dir, "go.mod"))
+	require.ErrorContains(t, err, "error reading manifest")
+}
+
+func TestMerge(t *testing.T) {
+	a := []Dependency{
+		{Name: "github.com/o/a", Version: "v1.0.0", Ecosystem: EcosystemGo},
+		{Name: "github.com/o/b", Ecosystem: EcosystemGo, Indirect: true},
+	}
+	b := []Dependency{
+		{Name: "github.com/o/a", Version: "v2.0.0", Ecosystem: EcosystemGo},
+		{Name: "github.com/o/a", Ecosystem: EcosystemNPM},
+	}
+
+	assert.Equal(t, []Dependency{a[0], a[1], b[1]}, Merge([][]Dependency{a, b}, false))
+	assert.Equal(t, []Dependency{a[0], b[1]}, Merge([][]Dependency{a, b}, true))
+}
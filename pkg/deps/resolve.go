@@ -0,0 +1,332 @@
+package deps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultNPMRegistry is the npm registry used to resolve npm packages.
+	DefaultNPMRegistry = "https://registry.npmjs.org"
+	// DefaultPyPIRegistry is the PyPI index used to resolve Python projects.
+	DefaultPyPIRegistry = "https://pypi.org"
+
+	httpTimeout = 30 * time.Second
+	// maxResponseSize bounds the registry and vanity import responses read.
+	maxResponseSize = 10 << 20
+)
+
+// ErrUnresolved is returned when a dependency's source repo cannot be
+// determined or is not on a supported git provider.
+var ErrUnresolved = errors.New("source repo not found")
+
+// supportedHosts are the git providers whose repos can be scored.
+var supportedHosts = map[string]bool{
+	"github.com": true,
+	"gitlab.com": true,
+}
+
+// Resolver is a function that returns the URI of a dependency's source
+// repo, e.g. github.com/owner/name, or an error wrapping [ErrUnresolved].
+type Resolver func(ctx context.Context, d Dependency) (string, error)
+
+// Registries are the endpoints an online resolver queries.
+type Registries struct {
+	// Client sends the requests (optional, defaults to a client with a
+	// 30 second timeout).
+	Client *http.Client
+	// NPM and PyPI are the registry base URLs (optional, default:
+	// DefaultNPMRegistry and DefaultPyPIRegistry).
+	NPM  string
+	PyPI string
+}
+
+// OnlineResolver returns a resolver that maps Go modules by their github.com
+// path or their go-import meta tags, npm packages by their registry repository, and
+// Python projects by their PyPI project URLs.
+func OnlineResolver(reg Registries) Resolver {
+	if reg.Client == nil {
+		reg.Client = &http.Client{Timeout: httpTimeout}
+	}
+	if reg.NPM == "" {
+		reg.NPM = DefaultNPMRegistry
+	}
+	if reg.PyPI == "" {
+		reg.PyPI = DefaultPyPIRegistry
+	}
+
+	return func(ctx context.Context, d Dependency) (string, error) {
+		switch d.Ecosystem {
+		case EcosystemGo:
+			if repo, ok := goModuleRepo(d.Name); ok {
+				return repo, nil
+			}
+			return reg.goImport(ctx, d.Name)
+		case EcosystemNPM:
+			return reg.npm(ctx, d.Name)
+		case EcosystemPyPI:
+			return reg.pypi(ctx, d.Name)
+		default:
+			return "", fmt.Errorf("unsupported ecosystem: %s", d.Ecosystem)
+		}
+	}
+}
+
+// FixtureResolver returns a resolver that maps dependencies by name, or
+// by ecosystem:name, from m without network access. Go modules not in m
+// are mapped by their path when it is on github.com.
+func FixtureResolver(m map[string]string) Resolver {
+	return func(_ context.Context, d Dependency) (string, error) {
+		for _, k := range []string{d.key(), d.Name} {
+			if repo, ok := m[k]; ok {
+				if repo, ok := repoFromURL(repo); ok {
+					return repo, nil
+				}
+				return "", fmt.Errorf("%w: unsupported repo %s for %s", ErrUnresolved, m[k], d.Name)
+			}
+		}
+		if d.Ecosystem == EcosystemGo {
+			if repo, ok := goModuleRepo(d.Name); ok {
+				return repo, nil
+			}
+		}
+		return "", fmt.Errorf("%w: %s is not in the fixture mapping", ErrUnresolved, d.Name)
+	}
+}
+
+// LoadMapping reads a YAML or JSON fixture mapping of dependency names to
+// repo URIs.
+func LoadMapping(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // G304: path is a user-supplied fixture file
+	if err != nil {
+		return nil, fmt.Errorf("error reading mapping %s: %w", path, err)
+	}
+
+	m := make(map[string]string)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(b, &m)
+	default:
+		err = yaml.Unmarshal(b, &m)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing mapping %s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// goModuleRepo maps a module on github.com, whose repos are always
+// owner/name, to its repo, e.g. github.com/owner/name/v2 to
+// github.com/owner/name. Modules on gitlab.com may sit in nested groups,
+// so where their repo ends is only known from their go-import meta tag.
+func goModuleRepo(path string) (string, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 3 || parts[0] != "github.com" {
+		return "", false
+	}
+	return strings.Join(parts[:3], "/"), true
+}
+
+// metaTag matches an HTML meta tag; its attributes are matched separately.
+var (
+	metaTag  = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttr = regexp.MustCompile(`(?is)(name|content)\s*=\s*["']([^"']*)["']`)
+)
+
+// goImport resolves a vanity import path from its go-import meta tag.
+func (reg Registries) goImport(ctx context.Context, path string) (string, error) {
+	b, err := reg.get(ctx, "https://"+path+"?go-get=1")
+	if err != nil {
+		return "", fmt.Errorf("error resolving module %s: %w", path, err)
+	}
+
+	for _, tag := range metaTag.FindAll(b, -1) {
+		var name, content string
+		for _, m := range metaAttr.FindAllSubmatch(tag, -1) {
+			switch strings.ToLower(string(m[1])) {
+			case "name":
+				name = string(m[2])
+			case "content":
+				content = string(m[2])
+			}
+		}
+		if name != "go-import" {
+			continue
+		}
+
+		f := strings.Fields(content)
+		if len(f) != 3 || (path != f[0] && !strings.HasPrefix(path, f[0]+"/")) {
+			continue
+		}
+		if repo, ok := repoFromURL(f[2]); ok {
+			return repo, nil
+		}
+		return "", fmt.Errorf("%w: %s is hosted at %s", ErrUnresolved, path, f[2])
+	}
+
+	return "", fmt.Errorf("%w: no go-import meta tag for %s", ErrUnresolved, path)
+}
+
+// npm resolves an npm package from its registry repository field.
+func (reg Registries) npm(ctx context.Context, name string) (string, error) {
+	b, err := reg.get(ctx, strings.TrimSuffix(reg.NPM, "/")+"/"+url.PathEscape(name))
+	if err != nil {
+		return "", fmt.Errorf("error resolving npm package %s: %w", name, err)
+	}
+
+	var p struct {
+		Repository json.RawMessage `json:"repository"`
+	}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return "", fmt.Errorf("error decoding npm package %s: %w", name, err)
+	}
+
+	// The repository is either a URL or an object with one.
+	var u string
+	if err := json.Unmarshal(p.Repository, &u); err != nil {
+		var o struct {
+			URL string `json:"url"`
+		}
+		_ = json.Unmarshal(p.Repository, &o)
+		u = o.URL
+	}
+	// A bare owner/name is shorthand for a GitHub repo.
+	if !strings.Contains(u, ":") && strings.Count(u, "/") == 1 {
+		u = "github.com/" + u
+	}
+
+	if repo, ok := repoFromURL(u); ok {
+		return repo, nil
+	}
+	return "", fmt.Errorf("%w: npm package %s has no supported repository", ErrUnresolved, name)
+}
+
+// pypiURLKeys are the PyPI project URL labels that name the source repo,
+// in order of preference.
+var pypiURLKeys = []string{"source", "source code", "repository", "code", "github", "homepage"}
+
+// pypi resolves a Python project from its PyPI project URLs.
+func (reg Registries) pypi(ctx context.Context, name string) (string, error) {
+	b, err := reg.get(ctx, strings.TrimSuffix(reg.PyPI, "/")+"/pypi/"+url.PathEscape(name)+"/json")
+	if err != nil {
+		return "", fmt.Errorf("error resolving PyPI project %s: %w", name, err)
+	}
+
+	var p struct {
+		Info struct {
+			HomePage    string            `json:"home_page"`
+			ProjectURLs map[string]string `json:"project_urls"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return "", fmt.Errorf("error decoding PyPI project %s: %w", name, err)
+	}
+
+	urls := make(map[string]string, len(p.Info.ProjectURLs))
+	for k, v := range p.Info.ProjectURLs {
+		urls[strings.ToLower(k)] = v
+	}
+
+	candidates := make([]string, 0, len(pypiURLKeys)+1)
+	for _, k := range pypiURLKeys {
+		candidates = append(candidates, urls[k])
+	}
+	candidates = append(candidates, p.Info.HomePage)
+
+	for _, u := range candidates {
+		if repo, ok := repoFromURL(u); ok {
+			return repo, nil
+		}
+	}
+	return "", fmt.Errorf("%w: PyPI project %s has no supported source URL", ErrUnresolved, name)
+}
+
+// get fetches a URL, failing on non-200 responses.
+func (reg Registries) get(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := reg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(resp.Body, maxResponseSize)); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", u, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// repoFromURL maps a repo URL in any of the common git forms, such as
+// git+https://github.com/owner/name.git, git@github.com:owner/name.git,
+// github:owner/name, or github.com/owner/name, to its URI on a supported
+// provider.
+func repoFromURL(u string) (string, bool) {
+	u = strings.TrimSpace(u)
+	u = strings.TrimPrefix(u, "git+")
+	for _, scheme := range []string{"https://", "http://", "ssh://", "git://"} {
+		u = strings.TrimPrefix(u, scheme)
+	}
+	u = strings.TrimPrefix(u, "git@")
+	u = strings.TrimPrefix(u, "www.")
+
+	switch {
+	case strings.HasPrefix(u, "github:"):
+		u = "github.com/" + strings.TrimPrefix(u, "github:")
+	case strings.HasPrefix(u, "gitlab:"):
+		u = "gitlab.com/" + strings.TrimPrefix(u, "gitlab:")
+	}
+	u = strings.Replace(u, ":", "/", 1)
+
+	u, _, _ = strings.Cut(u, "#")
+	u, _, _ = strings.Cut(u, "?")
+
+	host := strings.ToLower(u[:max(strings.Index(u, "/"), 0)])
+	if !supportedHosts[host] {
+		return "", false
+	}
+
+	// GitLab projects may sit in nested groups; their web paths end at the
+	// "/-/" separator, e.g. gitlab.com/group/sub/project/-/tree/main.
+	parts := strings.Split(strings.Trim(u, "/"), "/")
+	n := 3
+	if host == "gitlab.com" {
+		path, _, _ := strings.Cut(strings.Trim(u, "/"), "/-/")
+		parts = strings.Split(path, "/")
+		n = len(parts)
+	}
+	if len(parts) < 3 {
+		return "", false
+	}
+
+	parts[n-1] = strings.TrimSuffix(parts[n-1], ".git")
+	if slices.Contains(parts[1:n], "") {
+		return "", false
+	}
+
+	return host + "/" + strings.Join(parts[1:n], "/"), true
+}
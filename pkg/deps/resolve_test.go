@@ -0,0 +1,178 @@
+package deps
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		repo string
+	}{
+		{"https://github.com/o/r", "github.com/o/r"},
+		{"git+https://github.com/o/r.git", "github.com/o/r"},
+		{"git+ssh://git@github.com/o/r.git", "github.com/o/r"},
+		{"git@github.com:o/r.git", "github.com/o/r"},
+		{"git://github.com/o/r.git#main", "github.com/o/r"},
+		{"github:o/r", "github.com/o/r"},
+		{"https://www.GitHub.com/o/r/tree/main/pkg", "github.com/o/r"},
+		{"https://gitlab.com/g/p", "gitlab.com/g/p"},
+		{"git+https://gitlab.com/g/sub/p.git", "gitlab.com/g/sub/p"},
+		{"https://gitlab.com/g/sub/p/-/tree/main", "gitlab.com/g/sub/p"},
+	}
+	for _, tt := range tests {
+		repo, ok := repoFromURL(tt.url)
+		assert.True(t, ok, tt.url)
+		assert.Equal(t, tt.repo, repo, tt.url)
+	}
+
+	for _, u := range []string{"", "https://go.googlesource.com/sync", "https://github.com/o", "https://github.com/o/.git"} {
+		_, ok := repoFromURL(u)
+		assert.False(t, ok, u)
+	}
+}
+
+func TestFixtureResolver(t *testing.T) {
+	resolve := FixtureResolver(map[string]string{
+		"gopkg.in/yaml.v3": "https://github.com/go-yaml/yaml",
+		"npm:react":        "github:facebook/react",
+		"left-pad":         "https://example.com/left-pad",
+	})
+	ctx := context.Background()
+
+	repo, err := resolve(ctx, Dependency{Name: "gopkg.in/yaml.v3", Ecosystem: EcosystemGo})
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/go-yaml/yaml", repo)
+
+	repo, err = resolve(ctx, Dependency{Name: "react", Ecosystem: EcosystemNPM})
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/facebook/react", repo)
+
+	repo, err = resolve(ctx, Dependency{Name: "github.com/o/r/v2", Ecosystem: EcosystemGo})
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/o/r", repo, "Go paths on a provider need no mapping")
+
+	_, err = resolve(ctx, Dependency{Name: "left-pad", Ecosystem: EcosystemNPM})
+	require.ErrorIs(t, err, ErrUnresolved)
+
+	_, err = resolve(ctx, Dependency{Name: "golang.org/x/sync", Ecosystem: EcosystemGo})
+	require.ErrorIs(t, err, ErrUnresolved)
+}
+
+func TestLoadMapping(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "repos.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("react: github.com/facebook/react\n"), 0o600))
+	m, err := LoadMapping(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"react": "github.com/facebook/react"}, m)
+
+	path = filepath.Join(dir, "repos.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"react": 1}`), 0o600))
+	_, err = LoadMapping(path)
+	require.ErrorContains(t, err, "error parsing mapping")
+}
+
+// roundTripper serves requests with a function.
+type roundTripper func(*http.Request) *http.Response
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r), nil
+}
+
+// respond returns a response with the given status and body.
+func respond(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}
+}
+
+func TestOnlineResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/react":
+			_, _ = w.Write([]byte(`{"repository": {"type": "git", "url": "git+https://github.com/facebook/react.git"}}`))
+		case "/@scope%2Fpkg":
+			_, _ = w.Write([]byte(`{"repository": "o/pkg"}`))
+		case "/pypi/requests/json":
+			_, _ = w.Write([]byte(`{"info": {"home_page": "https://requests.readthedocs.io", "project_urls": {"Source": "https://github.com/psf/requests"}}}`))
+		case "/pypi/six/json":
+			_, _ = w.Write([]byte(`{"info": {"home_page": "https://github.com/benjaminp/six"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	resolve := OnlineResolver(Registries{NPM: srv.URL, PyPI: srv.URL})
+	ctx := context.Background()
+
+	tests := []struct {
+		dep  Dependency
+		repo string
+	}{
+		{Dependency{Name: "github.com/o/r/v3", Ecosystem: EcosystemGo}, "github.com/o/r"},
+		{Dependency{Name: "react", Ecosystem: EcosystemNPM}, "github.com/facebook/react"},
+		{Dependency{Name: "@scope/pkg", Ecosystem: EcosystemNPM}, "github.com/o/pkg"},
+		{Dependency{Name: "requests", Ecosystem: EcosystemPyPI}, "github.com/psf/requests"},
+		{Dependency{Name: "six", Ecosystem: EcosystemPyPI}, "github.com/benjaminp/six"},
+	}
+	for _, tt := range tests {
+		repo, err := resolve(ctx, tt.dep)
+		require.NoError(t, err, tt.dep.Name)
+		assert.Equal(t, tt.repo, repo, tt.dep.Name)
+	}
+
+	_, err := resolve(ctx, Dependency{Name: "missing", Ecosystem: EcosystemNPM})
+	require.ErrorContains(t, err, "404")
+
+	_, err = resolve(ctx, Dependency{Name: "x", Ecosystem: "cargo"})
+	require.ErrorContains(t, err, "unsupported ecosystem")
+}
+
+func TestOnlineResolverGoImport(t *testing.T) {
+	pages := map[string]string{
+		"gopkg.in/yaml.v3":       `<html><head><meta name="go-import" content="gopkg.in/yaml.v3 git https://gopkg.in/yaml.v3"><meta content="gopkg.in/yaml.v3 _ https://github.com/go-yaml/yaml/tree/v3.0.1{/dir}" name="go-source"></head></html>`,
+		"example.com/mod":        `<meta name="go-import" content="example.com/mod git https://github.com/o/mod.git">`,
+		"golang.org/x/sync":      `<meta name="go-import" content="golang.org/x/sync git https://go.googlesource.com/sync">`,
+		"gitlab.com/g/sub/p/pkg": `<meta name="go-import" content="gitlab.com/g/sub/p git https://gitlab.com/g/sub/p.git">`,
+	}
+	client := &http.Client{Transport: roundTripper(func(r *http.Request) *http.Response {
+		page, ok := pages[r.URL.Host+r.URL.Path]
+		if !ok || r.URL.Query().Get("go-get") != "1" {
+			return respond(http.StatusNotFound, "")
+		}
+		return respond(http.StatusOK, page)
+	})}
+	resolve := OnlineResolver(Registries{Client: client})
+	ctx := context.Background()
+
+	repo, err := resolve(ctx, Dependency{Name: "example.com/mod", Ecosystem: EcosystemGo})
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/o/mod", repo)
+
+	repo, err = resolve(ctx, Dependency{Name: "gitlab.com/g/sub/p/pkg", Ecosystem: EcosystemGo})
+	require.NoError(t, err)
+	assert.Equal(t, "gitlab.com/g/sub/p", repo, "GitLab modules are resolved through nested groups")
+
+	_, err = resolve(ctx, Dependency{Name: "golang.org/x/sync", Ecosystem: EcosystemGo})
+	require.ErrorIs(t, err, ErrUnresolved)
+	assert.ErrorContains(t, err, "go.googlesource.com")
+
+	_, err = resolve(ctx, Dependency{Name: "gopkg.in/yaml.v3", Ecosystem: EcosystemGo})
+	require.ErrorIs(t, err, ErrUnresolved, "gopkg.in serves its own repos")
+}
@@ -0,0 +1,258 @@
+package diff
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/report"
+)
+
+// DefaultThreshold is the minimum absolute reputation change reported.
+const DefaultThreshold = 0.1
+
+// signalEpsilon ignores signal contribution changes lost to rounding.
+const signalEpsilon = 0.0001
+
+// Diff is the difference between two reports.
+type Diff struct {
+	Old           Source         `json:"old" yaml:"old"`
+	New           Source         `json:"new" yaml:"new"`
+	Threshold     float64        `json:"threshold" yaml:"threshold"`
+	RiskThreshold float64        `json:"risk_threshold" yaml:"riskThreshold"`
+	Tiers         []report.Tier  `json:"tiers,omitempty" yaml:"tiers,omitempty"`
+	Model         *ModelChange   `json:"model,omitempty" yaml:"model,omitempty"`
+	Added         []*Contributor `json:"added,omitempty" yaml:"added,omitempty"`
+	Removed       []*Contributor `json:"removed,omitempty" yaml:"removed,omitempty"`
+	Changed       []*Change      `json:"changed,omitempty" yaml:"changed,omitempty"`
+}
+
+// Empty reports whether the reports differ in nothing the diff tracks.
+func (d *Diff) Empty() bool {
+	return d.Model == nil && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Source identifies one of the compared reports.
+type Source struct {
+	Repo         string    `json:"repo,omitempty" yaml:"repo,omitempty"`
+	AtCommit     string    `json:"at_commit,omitempty" yaml:"atCommit,omitempty"`
+	GeneratedOn  time.Time `json:"generated_on,omitempty" yaml:"generatedOn,omitempty"`
+	ModelVersion string    `json:"model_version,omitempty" yaml:"modelVersion,omitempty"`
+	ModelHash    string    `json:"model_hash,omitempty" yaml:"modelHash,omitempty"`
+}
+
+// ModelChange records a scoring model difference between the reports.
+// Reputation changes may then reflect the model rather than the author.
+type ModelChange struct {
+	OldVersion string `json:"old_version" yaml:"oldVersion"`
+	NewVersion string `json:"new_version" yaml:"newVersion"`
+	OldHash    string `json:"old_hash,omitempty" yaml:"oldHash,omitempty"`
+	NewHash    string `json:"new_hash,omitempty" yaml:"newHash,omitempty"`
+}
+
+// Contributor is an author present in only one of the reports.
+type Contributor struct {
+	Username   string  `json:"username" yaml:"username"`
+	Reputation float64 `json:"reputation" yaml:"reputation"`
+	// LowReputation is set when the reputation is below the risk threshold.
+	LowReputation bool `json:"low_reputation,omitempty" yaml:"lowReputation,omitempty"`
+}
+
+// Change is a reputation change at or beyond the threshold.
+type Change struct {
+	Username      string         `json:"username" yaml:"username"`
+	OldReputation float64        `json:"old_reputation" yaml:"oldReputation"`
+	NewReputation float64        `json:"new_reputation" yaml:"newReputation"`
+	Delta         float64        `json:"delta" yaml:"delta"`
+	Stats         []StatChange   `json:"stats,omitempty" yaml:"stats,omitempty"`
+	Signals       []SignalChange `json:"signals,omitempty" yaml:"signals,omitempty"`
+}
+
+// StatChange is a stat whose value differs between the reports. Only
+// available when both reports include stats.
+type StatChange struct {
+	Name string `json:"name" yaml:"name"`
+	Old  any    `json:"old" yaml:"old"`
+	New  any    `json:"new" yaml:"new"`
+}
+
+// SignalChange is a signal whose score contribution differs between the
+// reports. Only available when both reports include the breakdown.
+type SignalChange struct {
+	Name            string  `json:"name" yaml:"name"`
+	OldContribution float64 `json:"old_contribution" yaml:"oldContribution"`
+	NewContribution float64 `json:"new_contribution" yaml:"newContribution"`
+	Delta           float64 `json:"delta" yaml:"delta"`
+}
+
+// Compare returns the difference from prev to curr. Reputation changes
+// smaller than threshold are ignored. Contributors are flagged as
+// low-reputation against the current report's risk threshold, or
+// [report.DefaultRiskThreshold] when it has no summary, and badged with
+// its tiers.
+func Compare(prev, curr *report.Report, threshold float64) *Diff {
+	if prev == nil {
+		prev = &report.Report{}
+	}
+	if curr == nil {
+		curr = &report.Report{}
+	}
+
+	d := &Diff{
+		Old:           source(prev),
+		New:           source(curr),
+		Threshold:     threshold,
+		RiskThreshold: report.DefaultRiskThreshold,
+		Tiers:         curr.Tiers,
+	}
+	if curr.Summary != nil {
+		d.RiskThreshold = curr.Summary.RiskThreshold
+	}
+
+	if d.Old.ModelVersion != d.New.ModelVersion || d.Old.ModelHash != d.New.ModelHash {
+		d.Model = &ModelChange{
+			OldVersion: d.Old.ModelVersion,
+			NewVersion: d.New.ModelVersion,
+			OldHash:    d.Old.ModelHash,
+			NewHash:    d.New.ModelHash,
+		}
+	}
+
+	before := index(prev)
+	after := index(curr)
+
+	for key, a := range after {
+		b, ok := before[key]
+		if !ok {
+			d.Added = append(d.Added, d.contributor(a))
+			continue
+		}
+		delta := report.ToFixed(a.Reputation-b.Reputation, 2)
+		if math.Abs(delta) < threshold || delta == 0 {
+			continue
+		}
+		d.Changed = append(d.Changed, &Change{
+			Username:      a.Username,
+			OldReputation: b.Reputation,
+			NewReputation: a.Reputation,
+			Delta:         delta,
+			Stats:         statChanges(b.Stats, a.Stats),
+			Signals:       signalChanges(b.Breakdown, a.Breakdown),
+		})
+	}
+
+	for key, b := range before {
+		if _, ok := after[key]; !ok {
+			d.Removed = append(d.Removed, d.contributor(b))
+		}
+	}
+
+	byReputation := func(list []*Contributor) {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Reputation != list[j].Reputation {
+				return list[i].Reputation < list[j].Reputation
+			}
+			return list[i].Username < list[j].Username
+		})
+	}
+	byReputation(d.Added)
+	byReputation(d.Removed)
+
+	sort.Slice(d.Changed, func(i, j int) bool {
+		ai, aj := math.Abs(d.Changed[i].Delta), math.Abs(d.Changed[j].Delta)
+		if ai != aj {
+			return ai > aj
+		}
+		return d.Changed[i].Username < d.Changed[j].Username
+	})
+
+	return d
+}
+
+// contributor returns the diff entry for an author in only one report.
+func (d *Diff) contributor(a *report.Author) *Contributor {
+	return &Contributor{
+		Username:      a.Username,
+		Reputation:    a.Reputation,
+		LowReputation: a.Reputation < d.RiskThreshold,
+	}
+}
+
+// source describes a report for the diff header.
+func source(r *report.Report) Source {
+	s := Source{Repo: r.Repo, AtCommit: r.AtCommit, GeneratedOn: r.GeneratedOn}
+	if r.Meta != nil {
+		s.ModelVersion = r.Meta.ModelVersion
+		s.ModelHash = r.Meta.ModelHash
+	}
+	return s
+}
+
+// index maps the report's contributors by username, qualified by host for
+// the authors of a combined report.
+func index(r *report.Report) map[string]*report.Author {
+	m := make(map[string]*report.Author, len(r.Contributors))
+	for _, a := range r.Contributors {
+		if a != nil {
+			m[a.Host+"/"+a.Username] = a
+		}
+	}
+	return m
+}
+
+// statChanges returns the stats that differ, sorted by name.
+func statChanges(prev, curr *report.Stats) []StatChange {
+	if prev == nil || curr == nil {
+		return nil
+	}
+
+	before := prev.Values()
+	after := curr.Values()
+
+	var list []StatChange
+	for name, v := range after {
+		if !reflect.DeepEqual(before[name], v) {
+			list = append(list, StatChange{Name: name, Old: before[name], New: v})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// signalChanges returns the signals whose contribution differs, largest
+// change first.
+func signalChanges(prev, curr *report.Breakdown) []SignalChange {
+	if prev == nil || curr == nil {
+		return nil
+	}
+
+	before := make(map[string]float64, len(prev.Signals))
+	for _, s := range prev.Signals {
+		before[s.Name] = s.Contribution
+	}
+
+	var list []SignalChange
+	for _, s := range curr.Signals {
+		delta := report.ToFixed(s.Contribution-before[s.Name], 4)
+		if math.Abs(delta) < signalEpsilon {
+			continue
+		}
+		list = append(list, SignalChange{
+			Name:            s.Name,
+			OldContribution: before[s.Name],
+			NewContribution: s.Contribution,
+			Delta:           delta,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		ai, aj := math.Abs(list[i].Delta), math.Abs(list[j].Delta)
+		if ai != aj {
+			return ai > aj
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
@@ -0,0 +1,148 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReports() (*report.Report, *report.Report) {
+	oldSignals := score.Signals{AgeDays: 900, Commits: 10, TotalCommits: 20, TotalContributors: 3, PRsMerged: 20}
+	newSignals := score.Signals{AgeDays: 900, Commits: 10, TotalCommits: 20, TotalContributors: 3, PRsMerged: 2, PRsClosed: 18}
+	oldResult := score.Compute(oldSignals)
+	newResult := score.Compute(newSignals)
+
+	prev := &report.Report{
+		Repo:        "github.com/o/r",
+		AtCommit:    "aaa",
+		GeneratedOn: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Meta:        &report.Meta{ModelVersion: "3.1.0"},
+		Contributors: []*report.Author{
+			{Username: "alice", Reputation: 0.8, Stats: &report.Stats{Commits: 10, PRsMerged: 20}, Breakdown: &oldResult},
+			{Username: "bob", Reputation: 0.6},
+			{Username: "dave", Reputation: 0.55},
+		},
+	}
+	curr := &report.Report{
+		Repo:        "github.com/o/r",
+		AtCommit:    "bbb",
+		GeneratedOn: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Meta:        &report.Meta{ModelVersion: "3.2.0"},
+		Summary:     &report.Summary{RiskThreshold: 0.5},
+		Contributors: []*report.Author{
+			{Username: "alice", Reputation: 0.55, Stats: &report.Stats{Commits: 10, PRsMerged: 2, PRsClosed: 18}, Breakdown: &newResult},
+			{Username: "bob", Reputation: 0.65},
+			{Username: "carol", Reputation: 0.81},
+			{Username: "eve", Reputation: 0.2},
+		},
+	}
+	return prev, curr
+}
+
+func compareTest() *Diff {
+	prev, curr := testReports()
+	return Compare(prev, curr, DefaultThreshold)
+}
+
+func TestCompare(t *testing.T) {
+	d := compareTest()
+
+	require.NotNil(t, d.Model)
+	assert.Equal(t, "3.1.0", d.Model.OldVersion)
+	assert.Equal(t, "3.2.0", d.Model.NewVersion)
+
+	require.Len(t, d.Added, 2)
+	assert.Equal(t, "eve", d.Added[0].Username)
+	assert.True(t, d.Added[0].LowReputation)
+	assert.Equal(t, "carol", d.Added[1].Username)
+	assert.False(t, d.Added[1].LowReputation)
+
+	require.Len(t, d.Removed, 1)
+	assert.Equal(t, "dave", d.Removed[0].Username)
+
+	// bob moved by 0.05, below the threshold.
+	require.Len(t, d.Changed, 1)
+	c := d.Changed[0]
+	assert.Equal(t, "alice", c.Username)
+	assert.InDelta(t, -0.25, c.Delta, 0.0001)
+	assert.Equal(t, []StatChange{
+		{Name: "prs_closed", Old: int64(0), New: int64(18)},
+		{Name: "prs_merged", Old: int64(20), New: int64(2)},
+	}, c.Stats)
+	require.NotEmpty(t, c.Signals)
+	assert.Equal(t, score.SignalPRAcceptance, c.Signals[0].Name)
+	assert.Negative(t, c.Signals[0].Delta)
+}
+
+func TestCompareThreshold(t *testing.T) {
+	prev, curr := testReports()
+	d := Compare(prev, curr, 0.05)
+	require.Len(t, d.Changed, 2)
+	assert.Equal(t, "alice", d.Changed[0].Username)
+	assert.Equal(t, "bob", d.Changed[1].Username)
+}
+
+func TestCompareSame(t *testing.T) {
+	prev, _ := testReports()
+	d := Compare(prev, prev, DefaultThreshold)
+	assert.True(t, d.Empty())
+
+	var buf bytes.Buffer
+	require.NoError(t, Text(&buf, d))
+	assert.Contains(t, buf.String(), "No changes.")
+
+	assert.True(t, Compare(nil, nil, DefaultThreshold).Empty())
+}
+
+func TestText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Text(&buf, compareTest()))
+	out := buf.String()
+
+	assert.Contains(t, out, "github.com/o/r: aaa (2026-01-01) -> bbb (2026-02-01)")
+	assert.Contains(t, out, "Model changed: 3.1.0 -> 3.2.0")
+	assert.Contains(t, out, "+ eve")
+	assert.Contains(t, out, "[low reputation]")
+	assert.Contains(t, out, "- dave")
+	assert.Contains(t, out, "0.80 -> 0.55 (-0.25)")
+	assert.Contains(t, out, "stats: prs_closed 0 -> 18; prs_merged 20 -> 2")
+	assert.Contains(t, out, "signals: pr_acceptance -")
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, compareTest()))
+	out := buf.String()
+
+	assert.Contains(t, out, "## Reputation changes for `github.com/o/r`")
+	assert.Contains(t, out, "from 3.1.0 to 3.2.0")
+	assert.Contains(t, out, "| 🔴 | @eve | 0.20 |")
+	assert.Contains(t, out, "| @dave | 0.55 |")
+	assert.Contains(t, out, "| @alice | 🟢 0.80 | 🟡 0.55 | -0.25 | prs_closed 0 -> 18; prs_merged 20 -> 2 |")
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, JSON(&buf, compareTest()))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Contains(t, got, "added")
+	assert.Contains(t, got, "removed")
+	assert.Contains(t, got, "changed")
+	assert.Equal(t, "3.2.0", got["model"].(map[string]any)["new_version"])
+}
+
+func TestStatSummaryLimit(t *testing.T) {
+	list := make([]StatChange, maxStatChanges+2)
+	for i := range list {
+		list[i] = StatChange{Name: "s", Old: 0, New: i}
+	}
+	assert.Contains(t, statSummary(list), "and 2 more")
+}
@@ -0,0 +1,7 @@
+// Package diff compares two reputation reports of the same repository.
+//
+// It reports contributors who appeared or departed between the runs,
+// reputation changes at or beyond a threshold together with the stat and
+// signal changes behind them, and differences in the scoring model. The
+// result can be written as text, JSON, or Markdown.
+package diff
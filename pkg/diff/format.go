@@ -0,0 +1,155 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/render"
+)
+
+// maxStatChanges limits how many stat changes are listed per author in
+// text and Markdown output.
+const maxStatChanges = 5
+
+// JSON writes the diff as JSON.
+func JSON(w io.Writer, d *Diff) error {
+	if err := json.NewEncoder(w).Encode(d); err != nil {
+		return fmt.Errorf("error encoding diff: %w", err)
+	}
+	return nil
+}
+
+// Text writes the diff as plain text.
+func Text(w io.Writer, d *Diff) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "%s: %s -> %s\n", d.New.Repo, sourceLabel(d.Old), sourceLabel(d.New))
+	if d.Model != nil {
+		fmt.Fprintf(b, "Model changed: %s -> %s (score changes may reflect the model)\n", d.Model.OldVersion, d.Model.NewVersion)
+	}
+	if d.Empty() {
+		fmt.Fprintln(b, "No changes.")
+	}
+
+	if len(d.Added) > 0 {
+		fmt.Fprintf(b, "\nNew contributors (%d):\n", len(d.Added))
+		for _, c := range d.Added {
+			fmt.Fprintf(b, "  + %-24s %.2f%s\n", c.Username, c.Reputation, lowMarker(c))
+		}
+	}
+
+	if len(d.Removed) > 0 {
+		fmt.Fprintf(b, "\nDeparted contributors (%d):\n", len(d.Removed))
+		for _, c := range d.Removed {
+			fmt.Fprintf(b, "  - %-24s %.2f\n", c.Username, c.Reputation)
+		}
+	}
+
+	if len(d.Changed) > 0 {
+		fmt.Fprintf(b, "\nReputation changes of %.2f or more (%d):\n", d.Threshold, len(d.Changed))
+		for _, c := range d.Changed {
+			fmt.Fprintf(b, "  ~ %-24s %.2f -> %.2f (%+.2f)\n", c.Username, c.OldReputation, c.NewReputation, c.Delta)
+			if s := statSummary(c.Stats); s != "" {
+				fmt.Fprintf(b, "      stats: %s\n", s)
+			}
+			if s := signalSummary(c.Signals); s != "" {
+				fmt.Fprintf(b, "      signals: %s\n", s)
+			}
+		}
+	}
+
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("error writing diff: %w", err)
+	}
+	return nil
+}
+
+// Markdown writes the diff as Markdown with score-tier badges.
+func Markdown(w io.Writer, d *Diff) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "## Reputation changes for `%s`\n\n", d.New.Repo)
+	fmt.Fprintf(b, "%s → %s\n", sourceLabel(d.Old), sourceLabel(d.New))
+	if d.Model != nil {
+		fmt.Fprintf(b, "\n> [!NOTE]\n> The scoring model changed from %s to %s; score changes may reflect the model rather than the contributors.\n",
+			d.Model.OldVersion, d.Model.NewVersion)
+	}
+	if d.Empty() {
+		fmt.Fprintln(b, "\nNo changes.")
+	}
+
+	if len(d.Added) > 0 {
+		fmt.Fprintf(b, "\n### New contributors\n\n| | Contributor | Reputation |\n| --- | --- | --- |\n")
+		for _, c := range d.Added {
+			fmt.Fprintf(b, "| %s | @%s | %.2f |\n", render.Badge(d.Tiers, c.Reputation), c.Username, c.Reputation)
+		}
+	}
+
+	if len(d.Removed) > 0 {
+		fmt.Fprintf(b, "\n### Departed contributors\n\n| Contributor | Reputation |\n| --- | --- |\n")
+		for _, c := range d.Removed {
+			fmt.Fprintf(b, "| @%s | %.2f |\n", c.Username, c.Reputation)
+		}
+	}
+
+	if len(d.Changed) > 0 {
+		fmt.Fprintf(b, "\n### Reputation changes of %.2f or more\n\n| Contributor | Old | New | Change | Stat changes |\n| --- | --- | --- | --- | --- |\n", d.Threshold)
+		for _, c := range d.Changed {
+			fmt.Fprintf(b, "| @%s | %s %.2f | %s %.2f | %+.2f | %s |\n", c.Username,
+				render.Badge(d.Tiers, c.OldReputation), c.OldReputation,
+				render.Badge(d.Tiers, c.NewReputation), c.NewReputation,
+				c.Delta, statSummary(c.Stats))
+		}
+	}
+
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("error writing diff: %w", err)
+	}
+	return nil
+}
+
+// sourceLabel describes a compared report by commit and generation date.
+func sourceLabel(s Source) string {
+	label := s.AtCommit
+	if label == "" {
+		label = "HEAD"
+	}
+	if !s.GeneratedOn.IsZero() {
+		label += " (" + s.GeneratedOn.UTC().Format(time.DateOnly) + ")"
+	}
+	return label
+}
+
+// lowMarker flags contributors below the risk threshold in text output.
+func lowMarker(c *Contributor) string {
+	if c.LowReputation {
+		return "  [low reputation]"
+	}
+	return ""
+}
+
+// statSummary lists the first stat changes on one line.
+func statSummary(list []StatChange) string {
+	parts := make([]string, 0, min(len(list), maxStatChanges))
+	for i, s := range list {
+		if i == maxStatChanges {
+			parts = append(parts, fmt.Sprintf("and %d more", len(list)-maxStatChanges))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %v -> %v", s.Name, s.Old, s.New))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// signalSummary lists signal contribution changes on one line.
+func signalSummary(list []SignalChange) string {
+	parts := make([]string, 0, len(list))
+	for _, s := range list {
+		parts = append(parts, fmt.Sprintf("%s %+.4f", s.Name, s.Delta))
+	}
+	return strings.Join(parts, "; ")
+}
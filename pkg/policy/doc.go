@@ -0,0 +1,9 @@
+// Package policy evaluates policy-as-code scoring rules.
+//
+// A policy is an ordered list of rules loaded from YAML or JSON. Each rule
+// has a CEL boolean expression evaluated over an author's stats and
+// computed score, and an action (cap, floor, set, or flag) applied when the
+// expression matches. Expressions are compiled with cel-go in an
+// environment that declares the author variables, so unknown variables or
+// stats fields and type errors fail when the policy is loaded.
+package policy
@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+)
+
+// newEnv returns the CEL environment rules are compiled in. Its variables
+// describe a scored author; stats is bound to statsType, a struct whose
+// fields are selected by their JSON names. Numbers of different types may
+// be compared but not combined in arithmetic without a conversion.
+func newEnv(statsType reflect.Type) (*cel.Env, error) {
+	if statsType == nil {
+		return nil, errors.New("stats type must be specified")
+	}
+	if statsType.Kind() == reflect.Pointer {
+		statsType = statsType.Elem()
+	}
+
+	env, err := cel.NewEnv(
+		ext.NativeTypes(ext.ParseStructTag("json"), statsType),
+		cel.CrossTypeNumericComparisons(true),
+		cel.Variable("username", cel.StringType),
+		cel.Variable("reputation", cel.DoubleType),
+		cel.Variable("completeness", cel.DoubleType),
+		cel.Variable("confidence", cel.DoubleType),
+		cel.Variable("repo", cel.StringType),
+		cel.Variable("total_commits", cel.IntType),
+		cel.Variable("total_contributors", cel.IntType),
+		cel.Variable("context", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("stats", cel.ObjectType(path.Base(statsType.PkgPath())+"."+statsType.Name())),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating policy environment: %w", err)
+	}
+
+	return env, nil
+}
+
+// compile type-checks a rule expression, which must evaluate to a bool, and
+// plans it for evaluation. Constant regular expressions are compiled once.
+func compile(env *cel.Env, expr string) (cel.Program, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("expression must evaluate to bool, got %s", ast.OutputType())
+	}
+
+	prg, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+	if err != nil {
+		return nil, err
+	}
+
+	return prg, nil
+}
+
+// evalBool evaluates a compiled rule expression against vars.
+func evalBool(prg cel.Program, vars map[string]any) (bool, error) {
+	out, _, err := prg.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expected bool, got %v", out.Type())
+	}
+
+	return b, nil
+}
@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStats mirrors the shape of report.Stats, which this package cannot import.
+type testStats struct {
+	AgeDays           int64             `json:"age_days"`
+	Commits           int64             `json:"commits"`
+	UnverifiedCommits int64             `json:"unverified_commits"`
+	TrustedOrgMember  bool              `json:"trusted_org_member,omitempty"`
+	AuthorAssociation string            `json:"author_association,omitempty"`
+	SensitiveFiles    []string          `json:"sensitive_files,omitempty"`
+	SignalStatus      map[string]string `json:"signal_status,omitempty"`
+}
+
+var statsType = reflect.TypeFor[testStats]()
+
+func testVars() map[string]any {
+	return map[string]any{
+		"username":           "octocat",
+		"reputation":         0.42,
+		"completeness":       1.0,
+		"confidence":         0.9,
+		"repo":               "github.com/o/r",
+		"total_commits":      int64(100),
+		"total_contributors": int64(5),
+		"context":            map[string]string{"name": "Octo Cat"},
+		"stats": &testStats{
+			AgeDays:           10,
+			Commits:           4,
+			UnverifiedCommits: 4,
+			TrustedOrgMember:  true,
+			AuthorAssociation: "CONTRIBUTOR",
+			SensitiveFiles:    []string{".github/workflows/test.yaml", "go.mod"},
+			SignalStatus:      map[string]string{"burst": "errored"},
+		},
+	}
+}
+
+func TestCompileEval(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"stats.age_days < 14", true},
+		{"reputation > 0.4", true},
+		{"reputation <= 1", true},
+		{"stats.commits == stats.unverified_commits", true},
+		{"double(stats.commits) / 8.0 == 0.5", true},
+		{"int(reputation * 100.0) == 42", true},
+		{"username == 'octocat' && context.name == 'Octo Cat'", true},
+		{"stats.trusted_org_member && !(stats.author_association in ['OWNER', 'MEMBER'])", true},
+		{"size(stats.sensitive_files) == 2", true},
+		{"stats.sensitive_files.exists(f, f.startsWith('.github/workflows/'))", true},
+		{"stats.sensitive_files.all(f, f.startsWith('.github/'))", false},
+		{"username.matches('^oct[a-z]+$')", true},
+		{"'burst' in stats.signal_status && stats.signal_status['burst'] != 'collected'", true},
+		{"has(stats.signal_status.burst)", true},
+		{"confidence < 0.8 || total_commits > total_contributors", true},
+		{"repo.endsWith('/r') ? true : false", true},
+	}
+
+	env, err := newEnv(statsType)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			prg, err := compile(env, tt.expr)
+			require.NoError(t, err)
+			got, err := evalBool(prg, testVars())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		errMsg string
+	}{
+		{"", "Syntax error"},
+		{"1 +", "Syntax error"},
+		{"nope", "undeclared reference"},
+		{"stats.nope > 1", "undefined field"},
+		{"stats.age_dyas < 14", "undefined field"},
+		{"reputation + 1 > 0.5", "no matching overload"},
+		{"username > 1", "no matching overload"},
+		{"stats.age_days", "must evaluate to bool"},
+		{"username.matches(1)", "no matching overload"},
+	}
+
+	env, err := newEnv(statsType)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := compile(env, tt.expr)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	_, err = newEnv(nil)
+	require.Error(t, err)
+}
+
+func TestEvalErrors(t *testing.T) {
+	env, err := newEnv(statsType)
+	require.NoError(t, err)
+
+	for _, expr := range []string{
+		"stats.sensitive_files[5] == 'x'",
+		"stats.commits / (stats.commits - 4) > 1",
+	} {
+		prg, err := compile(env, expr)
+		require.NoError(t, err, expr)
+		_, err = evalBool(prg, testVars())
+		require.Error(t, err, expr)
+	}
+}
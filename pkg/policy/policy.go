@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule actions.
+const (
+	// ActionCap lowers the score to at most Value.
+	ActionCap = "cap"
+	// ActionFloor raises the score to at least Value.
+	ActionFloor = "floor"
+	// ActionSet replaces the score with Value.
+	ActionSet = "set"
+	// ActionFlag records the match without changing the score.
+	ActionFlag = "flag"
+)
+
+// Policy is an ordered set of scoring rules.
+type Policy struct {
+	Rules []*Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule adjusts or flags an author's score when its expression evaluates to true.
+type Rule struct {
+	Name        string  `json:"name" yaml:"name"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	When        string  `json:"when" yaml:"when"`
+	Action      string  `json:"action" yaml:"action"`
+	Value       float64 `json:"value,omitempty" yaml:"value,omitempty"`
+
+	prg cel.Program
+}
+
+// Match records a rule that matched an author.
+type Match struct {
+	Rule        string   `json:"rule" yaml:"rule"`
+	Action      string   `json:"action" yaml:"action"`
+	Value       *float64 `json:"value,omitempty" yaml:"value,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Load reads and compiles a policy from a YAML or JSON file. The stats
+// variable of rule expressions is bound to statsType.
+func Load(path string, statsType reflect.Type) (*Policy, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // G304: path is a user-supplied policy file
+	if err != nil {
+		return nil, fmt.Errorf("error reading policy %s: %w", path, err)
+	}
+
+	p, err := Parse(b, filepath.Ext(path), statsType)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing policy %s: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// Parse decodes and compiles a policy. The ext selects the decoder:
+// ".json" for JSON, anything else for YAML. Unknown fields are rejected.
+func Parse(b []byte, ext string, statsType reflect.Type) (*Policy, error) {
+	var p Policy
+
+	switch strings.ToLower(ext) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&p); err != nil {
+			return nil, fmt.Errorf("error decoding JSON policy: %w", err)
+		}
+	default:
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err := dec.Decode(&p); err != nil {
+			return nil, fmt.Errorf("error decoding YAML policy: %w", err)
+		}
+	}
+
+	if err := p.Compile(statsType); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Compile validates every rule and type-checks its expression, with the
+// stats variable bound to statsType. Unknown variables or fields and type
+// mismatches are reported here rather than when the rule is evaluated.
+func (p *Policy) Compile(statsType reflect.Type) error {
+	if p == nil {
+		return errors.New("policy must be specified")
+	}
+
+	env, err := newEnv(statsType)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{})
+	for i, r := range p.Rules {
+		if r == nil {
+			return fmt.Errorf("rule %d must be specified", i)
+		}
+		if r.Name == "" {
+			return fmt.Errorf("rule %d: name must be specified", i)
+		}
+		if _, ok := seen[r.Name]; ok {
+			return fmt.Errorf("rule %s: duplicate name", r.Name)
+		}
+		seen[r.Name] = struct{}{}
+
+		switch r.Action {
+		case ActionCap, ActionFloor, ActionSet:
+			if r.Value < 0 || r.Value > 1 {
+				return fmt.Errorf("rule %s: value must be in [0, 1]", r.Name)
+			}
+		case ActionFlag:
+		default:
+			return fmt.Errorf("rule %s: unsupported action %q (must be cap, floor, set, or flag)", r.Name, r.Action)
+		}
+
+		if strings.TrimSpace(r.When) == "" {
+			return fmt.Errorf("rule %s: when must be specified", r.Name)
+		}
+		prg, err := compile(env, r.When)
+		if err != nil {
+			return fmt.Errorf("rule %s: invalid expression: %w", r.Name, err)
+		}
+		r.prg = prg
+	}
+
+	return nil
+}
+
+// Evaluate runs every compiled rule against vars and applies matching
+// adjustments to score in rule order. All rules see the same vars, so a
+// rule's match does not depend on adjustments made by earlier rules.
+// Returns the adjusted score and the matched rules.
+func (p *Policy) Evaluate(vars map[string]any, score float64) (float64, []Match, error) {
+	if p == nil {
+		return score, nil, nil
+	}
+
+	var matches []Match
+
+	for _, r := range p.Rules {
+		if r.prg == nil {
+			return score, nil, fmt.Errorf("rule %s: policy must be compiled before evaluation", r.Name)
+		}
+
+		ok, err := evalBool(r.prg, vars)
+		if err != nil {
+			return score, nil, fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		m := Match{Rule: r.Name, Action: r.Action, Description: r.Description}
+		switch r.Action {
+		case ActionCap:
+			score = min(score, r.Value)
+		case ActionFloor:
+			score = max(score, r.Value)
+		case ActionSet:
+			score = r.Value
+		}
+		if r.Action != ActionFlag {
+			v := r.Value
+			m.Value = &v
+		}
+		matches = append(matches, m)
+	}
+
+	return score, matches, nil
+}
@@ -0,0 +1,142 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+rules:
+- name: young-account-cap
+  description: Accounts younger than 14 days are capped at 0.2
+  when: stats.age_days < 14
+  action: cap
+  value: 0.2
+- name: trusted-floor
+  when: stats.trusted_org_member
+  action: floor
+  value: 0.6
+- name: unverified-release-tooling
+  when: stats.unverified_commits == stats.commits && size(stats.sensitive_files) > 0
+  action: flag
+`
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(testPolicy), ".yaml", statsType)
+	require.NoError(t, err)
+	require.Len(t, p.Rules, 3)
+	assert.Equal(t, "young-account-cap", p.Rules[0].Name)
+	assert.NotNil(t, p.Rules[0].prg)
+
+	_, err = Parse([]byte(`{"rules": [{"name": "x", "when": "true", "action": "flag"}]}`), ".json", statsType)
+	require.NoError(t, err)
+
+	_, err = Parse([]byte(`{"rules": [], "bogus": 1}`), ".json", statsType)
+	require.Error(t, err)
+
+	_, err = Parse([]byte("rules: []\nbogus: 1\n"), ".yaml", statsType)
+	require.Error(t, err)
+}
+
+func TestCompileRuleErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  []*Rule
+		errMsg string
+	}{
+		{"nil rule", []*Rule{nil}, "must be specified"},
+		{"missing name", []*Rule{{When: "true", Action: ActionFlag}}, "name must be specified"},
+		{"duplicate name", []*Rule{
+			{Name: "a", When: "true", Action: ActionFlag},
+			{Name: "a", When: "true", Action: ActionFlag},
+		}, "duplicate name"},
+		{"bad action", []*Rule{{Name: "a", When: "true", Action: "boost"}}, "unsupported action"},
+		{"value out of range", []*Rule{{Name: "a", When: "true", Action: ActionCap, Value: 2}}, "value must be in"},
+		{"missing when", []*Rule{{Name: "a", Action: ActionFlag}}, "when must be specified"},
+		{"bad expression", []*Rule{{Name: "a", When: "1 +", Action: ActionFlag}}, "invalid expression"},
+		{"unknown field", []*Rule{{Name: "a", When: "stats.nope > 1", Action: ActionFlag}}, "rule a: invalid expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Policy{Rules: tt.rules}).Compile(statsType)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	var p *Policy
+	require.Error(t, p.Compile(statsType))
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(testPolicy), ".yaml", statsType)
+	require.NoError(t, err)
+
+	// Young trusted account: capped at 0.2, then floored at 0.6, then flagged.
+	score, matches, err := p.Evaluate(testVars(), 0.42)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6, score, 0.0001)
+	require.Len(t, matches, 3)
+	assert.Equal(t, "young-account-cap", matches[0].Rule)
+	assert.Equal(t, ActionCap, matches[0].Action)
+	assert.Equal(t, "Accounts younger than 14 days are capped at 0.2", matches[0].Description)
+	require.NotNil(t, matches[0].Value)
+	assert.InDelta(t, 0.2, *matches[0].Value, 0.0001)
+	assert.Equal(t, ActionFlag, matches[2].Action)
+	assert.Nil(t, matches[2].Value)
+
+	// No rules match.
+	vars := testVars()
+	vars["stats"] = &testStats{AgeDays: 1000, Commits: 4}
+	score, matches, err = p.Evaluate(vars, 0.42)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.42, score, 0.0001)
+	assert.Empty(t, matches)
+}
+
+func TestEvaluateSet(t *testing.T) {
+	p := &Policy{Rules: []*Rule{{Name: "deny", When: "username == 'octocat'", Action: ActionSet, Value: 0}}}
+	require.NoError(t, p.Compile(statsType))
+
+	score, matches, err := p.Evaluate(testVars(), 0.9)
+	require.NoError(t, err)
+	assert.Zero(t, score)
+	require.Len(t, matches, 1)
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	p := &Policy{Rules: []*Rule{{Name: "bad", When: "stats.sensitive_files[5] == 'x'", Action: ActionFlag}}}
+	_, _, err := p.Evaluate(testVars(), 0.5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "compiled")
+
+	require.NoError(t, p.Compile(statsType))
+	_, _, err = p.Evaluate(testVars(), 0.5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rule bad")
+
+	var n *Policy
+	score, matches, err := n.Evaluate(testVars(), 0.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, score, 0.0001)
+	assert.Nil(t, matches)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0o600))
+
+	p, err := Load(path, statsType)
+	require.NoError(t, err)
+	assert.Len(t, p.Rules, 3)
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"), statsType)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading policy")
+}
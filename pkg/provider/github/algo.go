@@ -1,39 +1,164 @@
 package github
 
 import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/policy"
 	"github.com/mchmarny/reputer/pkg/report"
 	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/mchmarny/reputer/pkg/trust"
 )
 
 // calculateReputation scores an author by delegating to the score package.
-func calculateReputation(author *report.Author, totalCommits int64, totalContributors int) {
+// A nil model scores with the built-in model.
+func calculateReputation(author *report.Author, m *score.Model, totalCommits int64, totalContributors int) {
 	if author == nil || author.Stats == nil {
 		return
 	}
 
-	s := author.Stats
-
-	author.Reputation = score.Compute(score.Signals{
-		Suspended:         s.Suspended,
-		Commits:           s.Commits,
-		UnverifiedCommits: s.UnverifiedCommits,
-		TotalCommits:      totalCommits,
-		TotalContributors: totalContributors,
-		AgeDays:           s.AgeDays,
-		OrgMember:         s.OrgMember,
-		LastCommitDays:    s.LastCommitDays,
-		Followers:         s.Followers,
-		Following:         s.Following,
-		PublicRepos:       s.PublicRepos,
-		AuthorAssociation: s.AuthorAssociation,
-		HasBio:            s.HasBio,
-		HasCompany:        s.HasCompany,
-		HasLocation:       s.HasLocation,
-		HasWebsite:        s.HasWebsite,
-		PRsMerged:         s.PRsMerged,
-		PRsClosed:         s.PRsClosed,
-		RecentPRRepoCount: s.RecentPRRepoCount,
-		ForkedRepos:       s.ForkedRepos,
-		TrustedOrgMember:  s.TrustedOrgMember,
-	})
+	if m == nil {
+		m = score.DefaultModel()
+	}
+
+	r := m.Compute(author.Stats.Signals(totalCommits, totalContributors))
+
+	author.Reputation = r.Score
+	author.Confidence = r.Confidence
+	author.Breakdown = &r
+}
+
+// signalStatus maps the outcome of the auxiliary fetches to per-signal
+// collection statuses. Association falls back to org membership when the
+// repo has no PRs by the author, and trusted-org checks only matter when
+// none of them matched.
+func signalStatus(prErr, recentErr, ownedErr, assocErr error, assoc, memberStatus, trustedStatus string, trusted bool) map[string]string {
+	assocStatus := fetchStatus(assocErr)
+	if assocStatus == report.SignalCollected && assoc == "" {
+		assocStatus = memberStatus
+	}
+	if !trusted {
+		assocStatus = worstStatus(assocStatus, trustedStatus)
+	}
+
+	return map[string]string{
+		score.SignalPRAcceptance: fetchStatus(prErr),
+		score.SignalBurst:        fetchStatus(recentErr),
+		score.SignalForkRatio:    fetchStatus(ownedErr),
+		score.SignalAssociation:  assocStatus,
+	}
+}
+
+// compareReputation scores an author with each comparison model.
+func compareReputation(author *report.Author, models []*score.Model, totalCommits int64, totalContributors int) {
+	if author == nil || author.Stats == nil || len(models) == 0 {
+		return
+	}
+
+	signals := author.Stats.Signals(totalCommits, totalContributors)
+	author.Comparison = make([]report.ModelScore, 0, len(models))
+	for _, m := range models {
+		author.Comparison = append(author.Comparison, report.ModelScore{
+			ModelName:    m.Name,
+			ModelVersion: m.Version,
+			Reputation:   m.Compute(signals).Score,
+		})
+	}
+}
+
+// applyPolicy evaluates the policy against the scored author, adjusts the
+// reputation, and records the matching rules on the author.
+func applyPolicy(author *report.Author, p *policy.Policy, repo string, totalCommits int64, totalContributors int) error {
+	if author == nil || p == nil {
+		return nil
+	}
+
+	rep, matches, err := p.Evaluate(author.Vars(repo, totalCommits, totalContributors), author.Reputation)
+	if err != nil {
+		return fmt.Errorf("error evaluating policy for %s: %w", author.Username, err)
+	}
+
+	author.Reputation = rep
+	author.Policy = matches
+
+	return nil
+}
+
+// applyTrust applies the trust file's allowlist or denylist to the scored
+// author and records the override. Explicit trust decisions are applied last
+// so they take precedence over policy rules.
+func applyTrust(author *report.Author, c *trust.Config) {
+	if author == nil || c == nil {
+		return
+	}
+
+	rep, o := c.Apply(author.Username, author.Reputation)
+	if o == nil {
+		return
+	}
+
+	author.Reputation = rep
+	author.Overrides = append(author.Overrides, *o)
+}
+
+// detectReactivation returns the gap in days between an author's previous
+// public activity and their first commit to the repo, and whether that gap
+// marks the account as reactivated. Accounts stay flagged for windowDays
+// after the first repo commit; a non-positive dormancyDays disables detection.
+func detectReactivation(prev, first, now time.Time, dormancyDays, windowDays int64) (int64, bool) {
+	if prev.IsZero() || first.IsZero() {
+		return 0, false
+	}
+
+	gap := daysBetween(prev, first)
+	if dormancyDays <= 0 {
+		return gap, false
+	}
+
+	return gap, gap >= dormancyDays && daysBetween(first, now) <= windowDays
+}
+
+// daysBetween returns the number of whole days (rounded up) from start to end,
+// floored at zero.
+func daysBetween(start, end time.Time) int64 {
+	days := int64(math.Ceil(end.Sub(start).Hours() / hoursInDay))
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// profileSensitivePaths returns the number of commits that touched at least one
+// sensitive path and the sorted, de-duplicated list of sensitive files touched.
+func profileSensitivePaths(commitFiles [][]string, patterns []string) (int64, []string) {
+	var commits int64
+	seen := make(map[string]struct{})
+
+	for _, files := range commitFiles {
+		touched := false
+		for _, f := range files {
+			if !report.MatchAnyPath(patterns, f) {
+				continue
+			}
+			touched = true
+			seen[f] = struct{}{}
+		}
+		if touched {
+			commits++
+		}
+	}
+
+	if len(seen) == 0 {
+		return commits, nil
+	}
+
+	list := make([]string, 0, len(seen))
+	for f := range seen {
+		list = append(list, f)
+	}
+	sort.Strings(list)
+
+	return commits, list
 }
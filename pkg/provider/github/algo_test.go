@@ -1,12 +1,19 @@
 package github
 
 import (
+	"errors"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/mchmarny/reputer/pkg/logging"
+	"github.com/mchmarny/reputer/pkg/policy"
 	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/mchmarny/reputer/pkg/trust"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
@@ -126,12 +133,214 @@ func TestCalculateReputation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			calculateReputation(tt.author, tt.totalCommits, tt.totalContributors)
+			calculateReputation(tt.author, nil, tt.totalCommits, tt.totalContributors)
 			if tt.author == nil {
 				return
 			}
 			assert.InDelta(t, tt.wantScore, tt.author.Reputation, 0.02,
 				"%s: got=%.4f want=%.4f", tt.author.Username, tt.author.Reputation, tt.wantScore)
+			if tt.author.Stats != nil {
+				require.NotNil(t, tt.author.Breakdown)
+				assert.InDelta(t, tt.author.Reputation, tt.author.Breakdown.Score, 0.0001)
+			}
 		})
 	}
 }
+
+func TestDetectReactivation(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		prev     time.Time
+		first    time.Time
+		dormancy int64
+		window   int64
+		wantGap  int64
+		wantFlag bool
+	}{
+		{"zero prev", time.Time{}, now, 365, 90, 0, false},
+		{"zero first", now, time.Time{}, 365, 90, 0, false},
+		{"active account", now.Add(-40 * day), now.Add(-30 * day), 365, 90, 10, false},
+		{"dormant then recent", now.Add(-1000 * day), now.Add(-10 * day), 365, 90, 990, true},
+		{"dormant outside window", now.Add(-1000 * day), now.Add(-200 * day), 365, 90, 800, false},
+		{"at threshold", now.Add(-375 * day), now.Add(-10 * day), 365, 90, 365, true},
+		{"detection disabled", now.Add(-1000 * day), now.Add(-10 * day), 0, 90, 990, false},
+		{"prev after first", now.Add(-5 * day), now.Add(-10 * day), 365, 90, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gap, flag := detectReactivation(tt.prev, tt.first, now, tt.dormancy, tt.window)
+			assert.Equal(t, tt.wantGap, gap)
+			assert.Equal(t, tt.wantFlag, flag)
+		})
+	}
+}
+
+func TestProfileSensitivePaths(t *testing.T) {
+	commitFiles := [][]string{
+		{".github/workflows/test.yaml", "README.md"},
+		{"pkg/score/score.go"},
+		{"go.mod", "go.sum", ".github/workflows/test.yaml"},
+		nil,
+	}
+
+	commits, files := profileSensitivePaths(commitFiles, report.DefaultSensitivePaths)
+	assert.Equal(t, int64(2), commits)
+	assert.Equal(t, []string{".github/workflows/test.yaml", "go.mod", "go.sum"}, files)
+
+	commits, files = profileSensitivePaths(commitFiles, []string{"*.md"})
+	assert.Equal(t, int64(1), commits)
+	assert.Equal(t, []string{"README.md"}, files)
+
+	commits, files = profileSensitivePaths(commitFiles, []string{"*.rs"})
+	assert.Zero(t, commits)
+	assert.Nil(t, files)
+}
+
+func TestCompareReputation(t *testing.T) {
+	a := report.MakeAuthor("compare")
+	a.Stats.AgeDays = 365
+	a.Stats.Commits = 10
+	a.Stats.PublicRepos = 5
+
+	custom := score.DefaultModel()
+	custom.Name = "custom"
+	custom.Version = "0.1.0"
+	custom.Signals[score.SignalAge] = score.SignalSpec{Weight: 0.25, Ceiling: 730, Curve: score.CurveLog}
+	custom.Signals[score.SignalBurst] = score.SignalSpec{Weight: 0.0, Ceiling: 5, Curve: score.CurveLinear}
+
+	calculateReputation(a, nil, 100, 10)
+	compareReputation(a, []*score.Model{score.DefaultModel(), custom}, 100, 10)
+
+	require.Len(t, a.Comparison, 2)
+	assert.Equal(t, score.ModelVersion, a.Comparison[0].ModelVersion)
+	assert.InDelta(t, a.Reputation, a.Comparison[0].Reputation, 0.0001)
+	assert.Equal(t, "custom", a.Comparison[1].ModelName)
+	assert.NotEqual(t, a.Reputation, a.Comparison[1].Reputation)
+
+	b := report.MakeAuthor("none")
+	compareReputation(b, nil, 100, 10)
+	assert.Nil(t, b.Comparison)
+}
+
+func TestApplyPolicy(t *testing.T) {
+	p, err := policy.Parse([]byte(`
+rules:
+- name: young-account-cap
+  when: stats.age_days < 14
+  action: cap
+  value: 0.2
+- name: high-scorer
+  when: reputation > 0.1
+  action: flag
+`), ".yaml", reflect.TypeFor[report.Stats]())
+	require.NoError(t, err)
+
+	a := report.MakeAuthor("young")
+	a.Stats.AgeDays = 7
+	a.Reputation = 0.45
+
+	require.NoError(t, applyPolicy(a, p, "github.com/o/r", 100, 10))
+	assert.InDelta(t, 0.2, a.Reputation, 0.0001)
+	require.Len(t, a.Policy, 2)
+	assert.Equal(t, "young-account-cap", a.Policy[0].Rule)
+	assert.Equal(t, "high-scorer", a.Policy[1].Rule)
+
+	require.NoError(t, applyPolicy(a, nil, "github.com/o/r", 100, 10))
+	require.NoError(t, applyPolicy(nil, p, "github.com/o/r", 100, 10))
+
+	_, err = policy.Parse([]byte("rules:\n- {name: bad, when: stats.nope, action: flag}\n"), ".yaml", reflect.TypeFor[report.Stats]())
+	require.Error(t, err, "unknown fields fail on load")
+
+	bad, err := policy.Parse([]byte("rules:\n- {name: bad, when: \"stats.sensitive_files[5] == 'x'\", action: flag}\n"), ".yaml", reflect.TypeFor[report.Stats]())
+	require.NoError(t, err)
+	require.Error(t, applyPolicy(a, bad, "github.com/o/r", 100, 10))
+}
+
+func TestSignalStatus(t *testing.T) {
+	failed := errors.New("timeout")
+	ok := report.SignalCollected
+
+	s := signalStatus(nil, nil, nil, nil, "CONTRIBUTOR", ok, ok, false)
+	for _, status := range s {
+		assert.Equal(t, ok, status)
+	}
+
+	s = signalStatus(failed, nil, failed, nil, "CONTRIBUTOR", ok, ok, false)
+	assert.Equal(t, report.SignalErrored, s[score.SignalPRAcceptance])
+	assert.Equal(t, ok, s[score.SignalBurst])
+	assert.Equal(t, report.SignalErrored, s[score.SignalForkRatio])
+
+	// A failed association search is errored even when org membership is known.
+	s = signalStatus(nil, nil, nil, failed, "", ok, ok, false)
+	assert.Equal(t, report.SignalErrored, s[score.SignalAssociation])
+
+	// No PRs in the repo falls back to org membership.
+	s = signalStatus(nil, nil, nil, nil, "", report.SignalErrored, ok, false)
+	assert.Equal(t, report.SignalErrored, s[score.SignalAssociation])
+	s = signalStatus(nil, nil, nil, nil, "MEMBER", report.SignalErrored, ok, false)
+	assert.Equal(t, ok, s[score.SignalAssociation])
+
+	// Failed trusted-org checks only matter when no trusted org matched.
+	s = signalStatus(nil, nil, nil, nil, "NONE", ok, report.SignalErrored, false)
+	assert.Equal(t, report.SignalErrored, s[score.SignalAssociation])
+	s = signalStatus(nil, nil, nil, nil, "NONE", ok, report.SignalErrored, true)
+	assert.Equal(t, ok, s[score.SignalAssociation])
+}
+
+func TestCalculateReputationConfidence(t *testing.T) {
+	a := report.MakeAuthor("partial")
+	a.Stats.AgeDays = 800
+	a.Stats.Commits = 10
+	a.Stats.SignalStatus = map[string]string{score.SignalPRAcceptance: report.SignalErrored}
+
+	calculateReputation(a, nil, 100, 5)
+	assert.Less(t, a.Confidence, 1.0)
+	assert.Greater(t, a.Confidence, 0.0)
+	require.NotNil(t, a.Breakdown)
+	assert.InDelta(t, a.Confidence, a.Breakdown.Confidence, 0.0001)
+}
+
+func TestReferenceTime(t *testing.T) {
+	at := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, at, referenceTime(report.Query{AsOf: at}))
+	assert.WithinDuration(t, time.Now(), referenceTime(report.Query{}), time.Minute)
+}
+
+func TestApplyTrust(t *testing.T) {
+	c, err := trust.Parse([]byte(`
+allow:
+- user: maintainer
+  floor: 0.8
+deny:
+- user: mallory
+  reason: compromised
+`), ".yaml")
+	require.NoError(t, err)
+
+	a := report.MakeAuthor("maintainer")
+	a.Reputation = 0.4
+	applyTrust(a, c)
+	assert.InDelta(t, 0.8, a.Reputation, 0.0001)
+	require.Len(t, a.Overrides, 1)
+	assert.Equal(t, trust.OverrideAllow, a.Overrides[0].Type)
+
+	m := report.MakeAuthor("mallory")
+	m.Reputation = 0.9
+	applyTrust(m, c)
+	assert.Zero(t, m.Reputation)
+	require.Len(t, m.Overrides, 1)
+	assert.Equal(t, "compromised", m.Overrides[0].Reason)
+
+	o := report.MakeAuthor("other")
+	o.Reputation = 0.5
+	applyTrust(o, c)
+	assert.InDelta(t, 0.5, o.Reputation, 0.0001)
+	assert.Empty(t, o.Overrides)
+
+	applyTrust(o, nil)
+	applyTrust(nil, c)
+}
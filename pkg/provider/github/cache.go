@@ -0,0 +1,43 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/cache"
+)
+
+// accountFetchTimeout bounds a single account-level lookup.
+const accountFetchTimeout = 5 * time.Minute
+
+// getAccount returns the account-level lookup (profile, PR and repo counts,
+// org membership) cached in accounts under key, calling fetch when there is
+// none. Without a cache, fetch is called directly. Callers scanning other
+// repos may be waiting on the same lookup, so fetch runs detached from the
+// cancellation of ctx, like the server's report queries.
+func getAccount[T any](ctx context.Context, accounts *cache.Cache, key string, fetch func(context.Context) (T, error)) (T, error) {
+	if accounts == nil {
+		return fetch(ctx)
+	}
+
+	return cache.Get(accounts, key, func() (T, error) {
+		fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), accountFetchTimeout)
+		defer cancel()
+		return fetch(fctx)
+	})
+}
+
+// cacheKey joins the parts of a cache key. Logins and orgs are matched
+// case-insensitively.
+func cacheKey(kind string, parts ...string) string {
+	return kind + "/" + strings.ToLower(strings.Join(parts, "/"))
+}
+
+// timeKey formats a query time for a cache key.
+func timeKey(t time.Time) string {
+	if t.IsZero() {
+		return "now"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
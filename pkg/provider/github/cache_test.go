@@ -0,0 +1,52 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheKey(t *testing.T) {
+	assert.Equal(t, "user/octocat", cacheKey("user", "OctoCat"))
+	assert.Equal(t, "member/acme/octocat", cacheKey("member", "Acme", "octocat"))
+	assert.Equal(t, "now", timeKey(time.Time{}))
+	assert.Equal(t, "2024-01-02T03:04:05Z", timeKey(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+}
+
+func TestGetAccountDetached(t *testing.T) {
+	accounts := cache.New(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := getAccount(ctx, accounts, "test/detached", func(ctx context.Context) (int, error) {
+			close(started)
+			<-release
+			return 1, ctx.Err()
+		})
+		done <- err
+	}()
+
+	<-started
+	cancel()
+	close(release)
+	require.NoError(t, <-done, "the first caller's cancellation does not cancel the lookup")
+
+	v, err := getAccount(context.Background(), accounts, "test/detached", func(context.Context) (int, error) {
+		return 0, errors.New("not cached")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	_, err = getAccount(context.Background(), nil, "test/detached", func(context.Context) (int, error) {
+		return 0, errors.New("not cached")
+	})
+	require.Error(t, err, "without a cache every lookup is fetched")
+}
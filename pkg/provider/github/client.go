@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	hub "github.com/google/go-github/v72/github"
@@ -17,6 +18,14 @@ const (
 	rateLimitThreshold = 10
 )
 
+// shared is the client reused across queries while the token is unchanged,
+// so repos scanned in one run share its connections and HTTP cache.
+var shared struct {
+	sync.Mutex
+	token  string
+	client *hub.Client
+}
+
 // getClient returns a GitHub client.
 func getClient() (*hub.Client, error) {
 	token := os.Getenv("GITHUB_TOKEN")
@@ -24,6 +33,12 @@ func getClient() (*hub.Client, error) {
 		return nil, fmt.Errorf("GITHUB_TOKEN environment variable must be set")
 	}
 
+	shared.Lock()
+	defer shared.Unlock()
+	if shared.client != nil && shared.token == token {
+		return shared.client, nil
+	}
+
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{
 			AccessToken: token,
@@ -37,7 +52,10 @@ func getClient() (*hub.Client, error) {
 		},
 	}
 
-	return hub.NewClient(tc), nil
+	shared.token = token
+	shared.client = hub.NewClient(tc)
+
+	return shared.client, nil
 }
 
 // waitForRateLimit pauses execution when the remaining rate limit is low.
@@ -2,29 +2,51 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"time"
 
 	hub "github.com/google/go-github/v72/github"
+	"github.com/mchmarny/reputer/pkg/cache"
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/mchmarny/reputer/pkg/trust"
+	"golang.org/x/sync/errgroup"
 )
 
+// eventsWindowDays is how far back the GitHub events API reaches.
+const eventsWindowDays = 90
+
+// errOutOfRange is returned when a source cannot answer for the requested time.
+var errOutOfRange = errors.New("requested time is outside the range this source covers")
+
+// beforeQualifier returns a search qualifier limiting field to at or before t,
+// or an empty string when t is zero.
+func beforeQualifier(field string, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" %s:<=%s", field, t.UTC().Format(time.RFC3339))
+}
+
 // prStats holds merged and closed-without-merge PR counts.
 type prStats struct {
 	Merged int64
 	Closed int64
 }
 
-// fetchPRStats returns global PR merge/close counts for a user.
+// fetchPRStats returns global PR merge/close counts for a user, limited to
+// PRs merged or closed at or before the given time when it is not zero.
 // Uses GitHub search API: 2 calls per user.
-func fetchPRStats(ctx context.Context, client *hub.Client, username string) prStats {
+func fetchPRStats(ctx context.Context, client *hub.Client, username string, before time.Time) (prStats, error) {
 	var stats prStats
 
 	mergedResult, mergedResp, err := client.Search.Issues(ctx,
-		fmt.Sprintf("author:%s type:pr is:merged", username),
+		fmt.Sprintf("author:%s type:pr is:merged", username)+beforeQualifier("merged", before),
 		&hub.SearchOptions{ListOptions: hub.ListOptions{PerPage: 1}})
 	if err != nil {
-		slog.Debug(fmt.Sprintf("search merged PRs for %s: %v", username, err))
-		return stats
+		return stats, fmt.Errorf("error searching merged PRs for %s: %w", username, err)
 	}
 	waitForRateLimit(mergedResp)
 	if mergedResult.Total != nil {
@@ -32,23 +54,28 @@ func fetchPRStats(ctx context.Context, client *hub.Client, username string) prSt
 	}
 
 	closedResult, closedResp, err := client.Search.Issues(ctx,
-		fmt.Sprintf("author:%s type:pr is:unmerged is:closed", username),
+		fmt.Sprintf("author:%s type:pr is:unmerged is:closed", username)+beforeQualifier("closed", before),
 		&hub.SearchOptions{ListOptions: hub.ListOptions{PerPage: 1}})
 	if err != nil {
-		slog.Debug(fmt.Sprintf("search closed PRs for %s: %v", username, err))
-		return stats
+		return stats, fmt.Errorf("error searching closed PRs for %s: %w", username, err)
 	}
 	waitForRateLimit(closedResp)
 	if closedResult.Total != nil {
 		stats.Closed = int64(*closedResult.Total)
 	}
 
-	return stats
+	return stats, nil
 }
 
 // fetchRecentPRRepoCount returns the number of distinct repos the user
-// opened PRs in, based on recent public events (last ~90 days).
-func fetchRecentPRRepoCount(ctx context.Context, client *hub.Client, username string) int64 {
+// opened PRs in, based on recent public events (last ~90 days). When before
+// is not zero, later events are ignored; returns errOutOfRange when before
+// predates the events the API retains.
+func fetchRecentPRRepoCount(ctx context.Context, client *hub.Client, username string, before time.Time) (int64, error) {
+	if !before.IsZero() && daysBetween(before, time.Now().UTC()) > eventsWindowDays {
+		return 0, errOutOfRange
+	}
+
 	repos := make(map[string]struct{})
 	page := 1
 
@@ -56,12 +83,14 @@ func fetchRecentPRRepoCount(ctx context.Context, client *hub.Client, username st
 		events, resp, err := client.Activity.ListEventsPerformedByUser(ctx, username, true,
 			&hub.ListOptions{Page: page, PerPage: pageSize})
 		if err != nil {
-			slog.Debug(fmt.Sprintf("list events for %s page %d: %v", username, page, err))
-			break
+			return int64(len(repos)), fmt.Errorf("error listing events for %s page %d: %w", username, page, err)
 		}
 		waitForRateLimit(resp)
 
 		for _, e := range events {
+			if !before.IsZero() && e.GetCreatedAt().After(before) {
+				continue
+			}
 			if e.GetType() == "PullRequestEvent" && e.Repo != nil {
 				repos[e.Repo.GetName()] = struct{}{}
 			}
@@ -73,56 +102,302 @@ func fetchRecentPRRepoCount(ctx context.Context, client *hub.Client, username st
 		page++
 	}
 
-	return int64(len(repos))
+	return int64(len(repos)), nil
 }
 
-// fetchForkedRepoCount returns the number of the user's owned repos that are forks.
-func fetchForkedRepoCount(ctx context.Context, client *hub.Client, username string) int64 {
-	var forked int64
-	page := 1
+// ownedRepos holds counts of the repos a user owns.
+type ownedRepos struct {
+	Total  int64
+	Forked int64
+}
 
-	for page <= 3 {
+// maxOwnedRepoPages guards against accounts with thousands of repos. Beyond
+// it, both the repo count and original repo signals are long saturated, so
+// the counts are kept as they are.
+const maxOwnedRepoPages = 50
+
+// fetchOwnedRepos returns the number of repos the user owns and how many of
+// them are forks, limited to repos created at or before the given time when
+// it is not zero. Repos are listed oldest first, so listing stops at the
+// first repo created after that time.
+func fetchOwnedRepos(ctx context.Context, client *hub.Client, username string, before time.Time) (ownedRepos, error) {
+	var owned ownedRepos
+
+	for page := 1; page <= maxOwnedRepoPages; page++ {
 		repos, resp, err := client.Repositories.ListByUser(ctx, username,
 			&hub.RepositoryListByUserOptions{
 				Type:        "owner",
+				Sort:        "created",
+				Direction:   "asc",
 				ListOptions: hub.ListOptions{Page: page, PerPage: pageSize},
 			})
 		if err != nil {
-			slog.Debug(fmt.Sprintf("list repos for %s page %d: %v", username, page, err))
-			break
+			return owned, fmt.Errorf("error listing repos for %s page %d: %w", username, page, err)
 		}
 		waitForRateLimit(resp)
 
 		for _, r := range repos {
+			if !before.IsZero() && r.GetCreatedAt().After(before) {
+				return owned, nil
+			}
+			owned.Total++
 			if r.GetFork() {
-				forked++
+				owned.Forked++
 			}
 		}
 
 		if len(repos) < pageSize {
-			break
+			return owned, nil
 		}
-		page++
 	}
 
-	return forked
+	slog.Debug(fmt.Sprintf("owned repos of %s counted up to %d pages", username, maxOwnedRepoPages))
+	return owned, nil
 }
 
 // fetchAuthorAssociation returns the author_association for a user in a repo.
-// Queries the user's PRs in the target repo and reads the association from the first result.
-func fetchAuthorAssociation(ctx context.Context, client *hub.Client, username, owner, repo string) string {
+// Queries the user's PRs in the target repo, opened at or before the given
+// time when it is not zero, and reads the association from the first result.
+func fetchAuthorAssociation(ctx context.Context, client *hub.Client, username, owner, repo string, before time.Time) (string, error) {
 	result, resp, err := client.Search.Issues(ctx,
-		fmt.Sprintf("author:%s type:pr repo:%s/%s", username, owner, repo),
+		fmt.Sprintf("author:%s type:pr repo:%s/%s", username, owner, repo)+beforeQualifier("created", before),
 		&hub.SearchOptions{ListOptions: hub.ListOptions{PerPage: 1}})
 	if err != nil {
-		slog.Debug(fmt.Sprintf("search PRs for %s in %s/%s: %v", username, owner, repo, err))
-		return ""
+		return "", fmt.Errorf("error searching PRs for %s in %s/%s: %w", username, owner, repo, err)
 	}
 	waitForRateLimit(resp)
 
 	if result.Total != nil && *result.Total > 0 && len(result.Issues) > 0 {
-		return result.Issues[0].GetAuthorAssociation()
+		return result.Issues[0].GetAuthorAssociation(), nil
+	}
+
+	return "", nil
+}
+
+// fetchPrevActivity returns the most recent public commit or issue/PR activity
+// by the user strictly before the given date. Returns zero time when none is
+// found. Either search failing is an error, since a partial result could
+// read as a long dormancy.
+func fetchPrevActivity(ctx context.Context, client *hub.Client, username string, before time.Time) (time.Time, error) {
+	var prev time.Time
+	day := before.UTC().Format(time.DateOnly)
+
+	commits, commitsResp, err := client.Search.Commits(ctx,
+		fmt.Sprintf("author:%s committer-date:<%s", username, day),
+		&hub.SearchOptions{Sort: "committer-date", Order: "desc", ListOptions: hub.ListOptions{PerPage: 1}})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error searching commits for %s before %s: %w", username, day, err)
+	}
+	waitForRateLimit(commitsResp)
+	if len(commits.Commits) > 0 {
+		prev = commits.Commits[0].GetCommit().GetCommitter().GetDate().Time
+	}
+
+	issues, issuesResp, err := client.Search.Issues(ctx,
+		fmt.Sprintf("author:%s created:<%s", username, day),
+		&hub.SearchOptions{Sort: "created", Order: "desc", ListOptions: hub.ListOptions{PerPage: 1}})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error searching issues for %s before %s: %w", username, day, err)
+	}
+	waitForRateLimit(issuesResp)
+	if len(issues.Issues) > 0 {
+		if d := issues.Issues[0].GetCreatedAt(); d.After(prev) {
+			prev = d.Time
+		}
+	}
+
+	return prev, nil
+}
+
+// fetchStatus classifies a fetch error as a signal collection status.
+// Not-found responses mean the source has no data for the author.
+func fetchStatus(err error) string {
+	if err == nil {
+		return report.SignalCollected
+	}
+
+	if errors.Is(err, errOutOfRange) {
+		return report.SignalMissing
+	}
+
+	var er *hub.ErrorResponse
+	if errors.As(err, &er) && er.Response != nil && er.Response.StatusCode == http.StatusNotFound {
+		return report.SignalMissing
+	}
+
+	return report.SignalErrored
+}
+
+// worstStatus returns the least complete of the given statuses.
+func worstStatus(statuses ...string) string {
+	worst := report.SignalCollected
+	for _, s := range statuses {
+		switch {
+		case s == report.SignalErrored:
+			return s
+		case s == report.SignalMissing:
+			worst = s
+		}
+	}
+	return worst
+}
+
+// fetchCommit resolves a commit reference, which may be a short SHA, to its
+// full SHA and committer date.
+func fetchCommit(ctx context.Context, client *hub.Client, owner, repo, ref string) (string, time.Time, error) {
+	c, resp, err := client.Repositories.GetCommit(ctx, owner, repo, ref, &hub.ListOptions{PerPage: 1})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error getting commit %s in %s/%s: %w", ref, owner, repo, err)
+	}
+	waitForRateLimit(resp)
+
+	d := c.GetCommit().GetCommitter().GetDate()
+	if c.GetSHA() == "" || d.IsZero() {
+		return "", time.Time{}, fmt.Errorf("commit %s in %s/%s has no SHA or committer date", ref, owner, repo)
+	}
+
+	return c.GetSHA(), d.UTC(), nil
+}
+
+// fetchTrustFile reads the trust file at [trust.RepoPath] from the repo's
+// default branch. Returns nil when the repo has no trust file.
+func fetchTrustFile(ctx context.Context, client *hub.Client, owner, repo string) (*trust.Config, error) {
+	fc, _, resp, err := client.Repositories.GetContents(ctx, owner, repo, trust.RepoPath, nil)
+	if err != nil {
+		if fetchStatus(err) == report.SignalMissing {
+			slog.Debug(fmt.Sprintf("no trust file in %s/%s", owner, repo))
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting %s in %s/%s: %w", trust.RepoPath, owner, repo, err)
+	}
+	waitForRateLimit(resp)
+
+	if fc == nil {
+		return nil, fmt.Errorf("%s in %s/%s is not a file", trust.RepoPath, owner, repo)
+	}
+
+	content, err := fc.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s in %s/%s: %w", trust.RepoPath, owner, repo, err)
+	}
+
+	c, err := trust.Parse([]byte(content), ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s in %s/%s: %w", trust.RepoPath, owner, repo, err)
+	}
+
+	return c, nil
+}
+
+// fetchCommitFiles returns the paths of files changed by each of the commits,
+// newest first, fetched concurrently. Only the first maxSensitiveCommits are
+// fetched, so callers report how many were; any failed fetch fails the
+// profile.
+func fetchCommitFiles(ctx context.Context, client *hub.Client, owner, repo string, shas []string) ([][]string, error) {
+	if len(shas) > maxSensitiveCommits {
+		slog.Debug(fmt.Sprintf("profiling %d of %d commits in %s/%s", maxSensitiveCommits, len(shas), owner, repo))
+		shas = shas[:maxSensitiveCommits]
+	}
+
+	commitFiles := make([][]string, len(shas))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(sensitiveConcurrency)
+
+	for i, sha := range shas {
+		g.Go(func() error {
+			files, err := fetchFiles(gctx, client, owner, repo, sha)
+			commitFiles[i] = files
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return commitFiles, nil
+}
+
+// fetchFiles returns the paths of all files changed by a commit. Commits
+// list their files a page at a time.
+func fetchFiles(ctx context.Context, client *hub.Client, owner, repo, sha string) ([]string, error) {
+	var files []string
+	for page := 1; page != 0; {
+		c, resp, err := client.Repositories.GetCommit(ctx, owner, repo, sha, &hub.ListOptions{Page: page, PerPage: pageSize})
+		if err != nil {
+			return nil, fmt.Errorf("error getting commit %s in %s/%s: %w", sha, owner, repo, err)
+		}
+		waitForRateLimit(resp)
+
+		for _, f := range c.Files {
+			files = append(files, f.GetFilename())
+		}
+		page = resp.NextPage
+	}
+
+	return files, nil
+}
+
+// fetchCommitCount returns the number of commits reachable from sha (the
+// default branch when empty) up to until, from the page count of a
+// one-commit-per-page listing.
+func fetchCommitCount(ctx context.Context, client *hub.Client, owner, repo, sha string, until time.Time) (int64, error) {
+	opts := &hub.CommitsListOptions{SHA: sha, Until: until, ListOptions: hub.ListOptions{PerPage: 1}}
+	p, resp, err := client.Repositories.ListCommits(ctx, owner, repo, opts)
+	if err != nil {
+		return 0, fmt.Errorf("error counting commits in %s/%s: %w", owner, repo, err)
 	}
+	waitForRateLimit(resp)
+
+	if resp.LastPage > 0 {
+		return int64(resp.LastPage), nil
+	}
+	return int64(len(p)), nil
+}
+
+// fetchContributorCount returns the number of the repo's contributors with a
+// GitHub account, from the page count of a one-per-page listing.
+func fetchContributorCount(ctx context.Context, client *hub.Client, owner, repo string) (int, error) {
+	opts := &hub.ListContributorsOptions{ListOptions: hub.ListOptions{PerPage: 1}}
+	p, resp, err := client.Repositories.ListContributors(ctx, owner, repo, opts)
+	if err != nil {
+		return 0, fmt.Errorf("error counting contributors in %s/%s: %w", owner, repo, err)
+	}
+	waitForRateLimit(resp)
+
+	if resp.LastPage > 0 {
+		return resp.LastPage, nil
+	}
+	return len(p), nil
+}
+
+// fetchUser returns the account profile of username, cached per run.
+func fetchUser(ctx context.Context, client *hub.Client, accounts *cache.Cache, username string) (*hub.User, error) {
+	return getAccount(ctx, accounts, cacheKey("user", username), func(ctx context.Context) (*hub.User, error) {
+		u, r, err := client.Users.Get(ctx, username)
+		if err != nil {
+			return nil, fmt.Errorf("error getting user %s: %w", username, err)
+		}
+		waitForRateLimit(r)
+
+		slog.Debug("user",
+			"page_next", r.NextPage,
+			"page_last", r.LastPage,
+			"status", r.StatusCode,
+			"rate_limit", r.Rate.Limit,
+			"rate_remaining", r.Rate.Remaining)
+
+		return u, nil
+	})
+}
 
-	return ""
+// fetchOrgMember reports whether username is a public member of org, cached
+// per run.
+func fetchOrgMember(ctx context.Context, client *hub.Client, accounts *cache.Cache, org, username string) (bool, error) {
+	return getAccount(ctx, accounts, cacheKey("member", org, username), func(ctx context.Context) (bool, error) {
+		ok, r, err := client.Organizations.IsMember(ctx, org, username)
+		waitForRateLimit(r)
+		return ok, err
+	})
 }
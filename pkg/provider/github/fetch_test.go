@@ -1,13 +1,144 @@
 package github
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
+	hub "github.com/google/go-github/v72/github"
+	"github.com/mchmarny/reputer/pkg/report"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// testClient returns a client whose API requests are served by handler.
+func testClient(t *testing.T, handler http.HandlerFunc) *hub.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client := hub.NewClient(nil)
+	u, err := url.Parse(srv.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = u
+	return client
+}
+
+func TestFetchPrevActivity(t *testing.T) {
+	before := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/commits":
+			fmt.Fprint(w, `{"total_count":1,"items":[{"commit":{"committer":{"date":"2020-01-02T00:00:00Z"}}}]}`)
+		case "/search/issues":
+			fmt.Fprint(w, `{"total_count":1,"items":[{"created_at":"2021-03-04T00:00:00Z"}]}`)
+		}
+	})
+	prev, err := fetchPrevActivity(context.Background(), client, "user", before)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), prev.UTC(), "the later of the two")
+
+	client = testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search/issues" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+			return
+		}
+		fmt.Fprint(w, `{"total_count":0,"items":[]}`)
+	})
+	_, err = fetchPrevActivity(context.Background(), client, "user", before)
+	require.Error(t, err, "a failed search is not read as no activity")
+	assert.Equal(t, report.SignalErrored, fetchStatus(err))
+}
+
+func TestFetchCommitFiles(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.Query().Get("page") {
+		case "/repos/o/r/commits/big?1":
+			w.Header().Set("Link", `<http://`+r.Host+`/repos/o/r/commits/big?page=2>; rel="next"`)
+			fmt.Fprint(w, `{"sha":"big","files":[{"filename":"main.go"}]}`)
+		case "/repos/o/r/commits/big?2":
+			fmt.Fprint(w, `{"sha":"big","files":[{"filename":".github/workflows/ci.yaml"}]}`)
+		case "/repos/o/r/commits/small?1":
+			fmt.Fprint(w, `{"sha":"small","files":[{"filename":"README.md"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	files, err := fetchCommitFiles(context.Background(), client, "o", "r", []string{"big", "small"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"main.go", ".github/workflows/ci.yaml"}, {"README.md"}}, files, "files past the first page are included")
+
+	_, err = fetchCommitFiles(context.Background(), client, "o", "r", []string{"small", "gone"})
+	require.Error(t, err)
+}
+
 func TestPRStatsZeroValue(t *testing.T) {
 	var s prStats
 	assert.Zero(t, s.Merged)
 	assert.Zero(t, s.Closed)
 }
+
+func TestFetchStatus(t *testing.T) {
+	notFound := &hub.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	serverErr := &hub.ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadGateway}}
+
+	assert.Equal(t, report.SignalCollected, fetchStatus(nil))
+	assert.Equal(t, report.SignalMissing, fetchStatus(notFound))
+	assert.Equal(t, report.SignalMissing, fetchStatus(fmt.Errorf("wrapped: %w", notFound)))
+	assert.Equal(t, report.SignalErrored, fetchStatus(serverErr))
+	assert.Equal(t, report.SignalErrored, fetchStatus(errors.New("connection reset")))
+	assert.Equal(t, report.SignalMissing, fetchStatus(errOutOfRange))
+}
+
+func TestBeforeQualifier(t *testing.T) {
+	assert.Empty(t, beforeQualifier("merged", time.Time{}))
+
+	at := time.Date(2023, 6, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	assert.Equal(t, " merged:<=2023-06-01T10:00:00Z", beforeQualifier("merged", at))
+}
+
+func TestFetchRecentPRRepoCountOutOfRange(t *testing.T) {
+	// Events older than the API retains are never requested.
+	_, err := fetchRecentPRRepoCount(context.Background(), nil, "user", time.Now().AddDate(-1, 0, 0))
+	require.ErrorIs(t, err, errOutOfRange)
+}
+
+func TestWorstStatus(t *testing.T) {
+	assert.Equal(t, report.SignalCollected, worstStatus())
+	assert.Equal(t, report.SignalCollected, worstStatus(report.SignalCollected, report.SignalCollected))
+	assert.Equal(t, report.SignalMissing, worstStatus(report.SignalCollected, report.SignalMissing))
+	assert.Equal(t, report.SignalErrored, worstStatus(report.SignalMissing, report.SignalErrored, report.SignalCollected))
+}
+
+func TestRecordCommit(t *testing.T) {
+	now := time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)
+	commit := func(sha string, day int, verified bool) *hub.RepositoryCommit {
+		return &hub.RepositoryCommit{
+			SHA: hub.Ptr(sha),
+			Commit: &hub.Commit{
+				Author:       &hub.CommitAuthor{Email: hub.Ptr("a@example.com")},
+				Committer:    &hub.CommitAuthor{Date: &hub.Timestamp{Time: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}},
+				Verification: &hub.SignatureVerification{Verified: hub.Ptr(verified)},
+			},
+		}
+	}
+
+	a := report.MakeAuthor("a")
+	ac := &authorCommits{}
+	recordCommit(a, ac, commit("s2", 10, true), now)
+	recordCommit(a, ac, commit("s1", 1, false), now)
+
+	assert.Equal(t, int64(2), a.Stats.Commits)
+	assert.Equal(t, int64(1), a.Stats.UnverifiedCommits)
+	assert.Equal(t, int64(1), a.Stats.LastCommitDays)
+	assert.Equal(t, []string{"s2", "s1"}, ac.shas)
+	assert.Equal(t, []string{"a@example.com"}, ac.emails)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ac.first)
+}
@@ -0,0 +1,48 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	hub "github.com/google/go-github/v72/github"
+	"github.com/mchmarny/reputer/pkg/report"
+)
+
+const host = "github.com"
+
+// ListRepos is a GitHub repo lister. It returns the URIs of the org's
+// repos that pass the filter, sorted by name.
+func ListRepos(ctx context.Context, org string, f report.RepoFilter) ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, fmt.Errorf("error creating GitHub client: %w", err)
+	}
+
+	var list []string
+	for page := 1; ; page++ {
+		opts := &hub.RepositoryListByOrgOptions{
+			Type:        "all",
+			Sort:        "full_name",
+			ListOptions: hub.ListOptions{Page: page, PerPage: pageSize},
+		}
+
+		p, r, err := client.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing repos of %s: %w", org, err)
+		}
+		waitForRateLimit(r)
+
+		for _, repo := range p {
+			if !f.Match(repo.GetArchived(), repo.GetFork(), repo.GetVisibility(), repo.Topics) {
+				slog.Debug("skipping repo", "repo", repo.GetFullName())
+				continue
+			}
+			list = append(list, host+"/"+repo.GetFullName())
+		}
+
+		if len(p) < pageSize {
+			return list, nil
+		}
+	}
+}
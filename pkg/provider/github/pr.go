@@ -0,0 +1,287 @@
+package github
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"slices"
+	"strings"
+	"sync"
+
+	hub "github.com/google/go-github/v72/github"
+	"github.com/mchmarny/reputer/pkg/cache"
+	"github.com/mchmarny/reputer/pkg/report"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	coAuthorTrailer = "co-authored-by:"
+	noReplyDomain   = "@users.noreply.github.com"
+)
+
+// coAuthor is a Co-authored-by trailer of a commit message.
+type coAuthor struct {
+	name  string
+	email string
+}
+
+// GetPullRequest is a GitHub pull request provider. It scores the pull
+// request's opener and the authors and co-authors of its commits against
+// the repo, and flags commits not authored by the opener.
+func GetPullRequest(ctx context.Context, q report.Query) (*report.Report, error) {
+	slog.Debug("get pull request",
+		"repo", q.Repo,
+		"number", q.PullRequest,
+		"as_of", q.AsOf,
+		"stats", q.Stats)
+
+	client, err := getClient()
+	if err != nil {
+		return nil, fmt.Errorf("error creating GitHub client: %w", err)
+	}
+
+	if err := prepareQuery(ctx, client, &q); err != nil {
+		return nil, err
+	}
+
+	pr, r, err := client.PullRequests.Get(ctx, q.Owner, q.Name, q.PullRequest)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pull request %s#%d: %w", q.Repo, q.PullRequest, err)
+	}
+	waitForRateLimit(r)
+
+	commits, err := listPullRequestCommits(ctx, client, q)
+	if err != nil {
+		return nil, err
+	}
+
+	info, logins, trailers := describePullRequest(pr.GetUser().GetLogin(), commits, func(email string) string {
+		return searchUserByEmail(ctx, client, email)
+	})
+	info.Number = q.PullRequest
+	info.HeadSHA = pr.GetHead().GetSHA()
+
+	info.Unresolved, err = unresolvedLogins(ctx, client, q.Accounts, trailers)
+	if err != nil {
+		return nil, err
+	}
+	logins = slices.DeleteFunc(logins, func(login string) bool {
+		return slices.Contains(info.Unresolved, login)
+	})
+
+	totalCommits, totalContributors, err := fetchRepoTotals(ctx, client, q)
+	if err != nil {
+		return nil, err
+	}
+
+	rpt := makeReport(&q, totalCommits, totalContributors)
+	rpt.PullRequest = info
+
+	var mu sync.Mutex
+	authors := make([]*report.Author, 0, len(logins))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrency)
+
+	for _, login := range logins {
+		g.Go(func() error {
+			a, err := scoreUser(gctx, client, q, login, totalCommits, totalContributors)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			authors = append(authors, a)
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("error loading pull request authors: %w", err)
+	}
+
+	rpt.Contributors = authors
+
+	if !q.Stats {
+		rpt.StripDetails(q.Explain)
+	}
+
+	return rpt, nil
+}
+
+// listPullRequestCommits lists the commits of the queried pull request,
+// oldest first.
+func listPullRequestCommits(ctx context.Context, client *hub.Client, q report.Query) ([]*hub.RepositoryCommit, error) {
+	var list []*hub.RepositoryCommit
+	for page := 1; ; page++ {
+		opts := &hub.ListOptions{Page: page, PerPage: pageSize}
+
+		p, r, err := client.PullRequests.ListCommits(ctx, q.Owner, q.Name, q.PullRequest, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing commits of %s#%d: %w", q.Repo, q.PullRequest, err)
+		}
+		waitForRateLimit(r)
+
+		list = append(list, p...)
+
+		if len(p) < pageSize {
+			return list, nil
+		}
+	}
+}
+
+// describePullRequest builds the pull request description from its opener
+// and commits, and returns the logins to score: the opener, then commit
+// authors and co-authors in commit order. Co-author emails are resolved to
+// logins from GitHub noreply addresses, the pull request's own commits, and
+// finally lookup, which returns an empty login when the email is unknown.
+// It also returns the logins known only from noreply trailers, which the
+// commit authors control and may name accounts that do not exist.
+func describePullRequest(opener string, commits []*hub.RepositoryCommit, lookup func(email string) string) (*report.PullRequest, []string, []string) {
+	info := &report.PullRequest{Author: opener}
+
+	var logins []string
+	seen := make(map[string]bool)
+	linked := make(map[string]bool)
+	add := func(login string, isLinked bool) {
+		key := strings.ToLower(login)
+		if login == "" {
+			return
+		}
+		if isLinked {
+			linked[key] = true
+		}
+		if !seen[key] {
+			seen[key] = true
+			logins = append(logins, login)
+		}
+	}
+	add(opener, true)
+
+	byEmail := make(map[string]string)
+	noReply := make(map[string]bool)
+	for _, c := range commits {
+		if email, login := c.GetCommit().GetAuthor().GetEmail(), c.GetAuthor().GetLogin(); email != "" && login != "" {
+			byEmail[strings.ToLower(email)] = login
+		}
+	}
+
+	for _, c := range commits {
+		pc := report.PullRequestCommit{
+			SHA:    c.GetSHA(),
+			Author: c.GetAuthor().GetLogin(),
+		}
+		if pc.Author == "" {
+			pc.AuthorName = c.GetCommit().GetAuthor().GetName()
+		}
+		pc.Foreign = !strings.EqualFold(pc.Author, opener)
+		add(pc.Author, true)
+
+		for _, ca := range parseCoAuthors(c.GetCommit().GetMessage()) {
+			key := strings.ToLower(ca.email)
+			login, ok := byEmail[key]
+			if !ok {
+				login = noReplyLogin(ca.email)
+				noReply[key] = login != ""
+				if login == "" {
+					login = lookup(ca.email)
+				}
+				byEmail[key] = login
+			}
+
+			if login == "" {
+				pc.CoAuthors = append(pc.CoAuthors, ca.name)
+				continue
+			}
+			pc.CoAuthors = append(pc.CoAuthors, login)
+			add(login, !noReply[key])
+		}
+
+		if pc.Foreign {
+			info.ForeignCommits++
+		}
+		info.Commits = append(info.Commits, pc)
+	}
+
+	var trailers []string
+	for _, login := range logins {
+		if !linked[strings.ToLower(login)] {
+			trailers = append(trailers, login)
+		}
+	}
+
+	return info, logins, trailers
+}
+
+// unresolvedLogins returns the logins that match no account. Other lookup
+// errors are returned, since they say nothing about the account.
+func unresolvedLogins(ctx context.Context, client *hub.Client, accounts *cache.Cache, logins []string) ([]string, error) {
+	var list []string
+	for _, login := range logins {
+		_, err := fetchUser(ctx, client, accounts, login)
+		switch {
+		case err == nil:
+		case fetchStatus(err) == report.SignalMissing:
+			slog.Debug(fmt.Sprintf("co-author %s not found, leaving unscored", login))
+			list = append(list, login)
+		default:
+			return nil, fmt.Errorf("error resolving co-author %s: %w", login, err)
+		}
+	}
+
+	return list, nil
+}
+
+// parseCoAuthors returns the Co-authored-by trailers of a commit message.
+func parseCoAuthors(msg string) []coAuthor {
+	var list []coAuthor
+
+	s := bufio.NewScanner(strings.NewReader(msg))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if len(line) <= len(coAuthorTrailer) || !strings.EqualFold(line[:len(coAuthorTrailer)], coAuthorTrailer) {
+			continue
+		}
+
+		addr, err := mail.ParseAddress(strings.TrimSpace(line[len(coAuthorTrailer):]))
+		if err != nil {
+			slog.Debug("invalid co-author trailer", "line", line, "error", err)
+			continue
+		}
+		list = append(list, coAuthor{name: addr.Name, email: addr.Address})
+	}
+
+	return list
+}
+
+// noReplyLogin returns the login of a GitHub noreply email address
+// ([id+]login@users.noreply.github.com), or empty for any other address.
+func noReplyLogin(email string) string {
+	local, ok := strings.CutSuffix(strings.ToLower(email), noReplyDomain)
+	if !ok {
+		return ""
+	}
+	if _, login, ok := strings.Cut(local, "+"); ok {
+		return login
+	}
+	return local
+}
+
+// searchUserByEmail returns the login of the single account with the given
+// public email, or empty when there is none or the search fails.
+func searchUserByEmail(ctx context.Context, client *hub.Client, email string) string {
+	res, r, err := client.Search.Users(ctx, email+" in:email", nil)
+	if err != nil {
+		slog.Debug("error searching user by email", "error", err)
+		return ""
+	}
+	waitForRateLimit(r)
+
+	if res.GetTotal() != 1 || len(res.Users) != 1 {
+		return ""
+	}
+
+	return res.Users[0].GetLogin()
+}
@@ -0,0 +1,95 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	hub "github.com/google/go-github/v72/github"
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prCommit(sha, login, name, email, msg string) *hub.RepositoryCommit {
+	c := &hub.RepositoryCommit{
+		SHA: hub.Ptr(sha),
+		Commit: &hub.Commit{
+			Author:  &hub.CommitAuthor{Name: hub.Ptr(name), Email: hub.Ptr(email)},
+			Message: hub.Ptr(msg),
+		},
+	}
+	if login != "" {
+		c.Author = &hub.User{Login: hub.Ptr(login)}
+	}
+	return c
+}
+
+func TestDescribePullRequest(t *testing.T) {
+	commits := []*hub.RepositoryCommit{
+		prCommit("c1", "Opener", "Opener", "opener@example.com", "fix\n\nCo-authored-by: Helper <helper@example.com>"),
+		prCommit("c2", "mallory", "Mallory", "mallory@example.com", "tweak"),
+		prCommit("c3", "", "Jane Doe", "jane@example.com", "docs\n\nco-authored-by: Bob <12+bob@users.noreply.github.com>\nCo-Authored-By: Mallory <mallory@example.com>\nCo-authored-by: Ghost <ghost@example.com>"),
+		prCommit("c4", "Opener", "Opener", "opener@example.com", "more\n\nCo-authored-by: Bob <12+bob@users.noreply.github.com>\nCo-authored-by: Me <opener@users.noreply.github.com>"),
+	}
+
+	var lookups []string
+	lookup := func(email string) string {
+		lookups = append(lookups, email)
+		if email == "helper@example.com" {
+			return "helper"
+		}
+		return ""
+	}
+
+	info, logins, trailers := describePullRequest("opener", commits, lookup)
+
+	assert.Equal(t, []string{"opener", "helper", "mallory", "bob"}, logins)
+	assert.Equal(t, []string{"bob"}, trailers, "only logins named solely by noreply trailers")
+	assert.Equal(t, []string{"helper@example.com", "ghost@example.com"}, lookups)
+	assert.Equal(t, "opener", info.Author)
+	assert.Equal(t, 2, info.ForeignCommits)
+	assert.Equal(t, []report.PullRequestCommit{
+		{SHA: "c1", Author: "Opener", CoAuthors: []string{"helper"}},
+		{SHA: "c2", Author: "mallory", Foreign: true},
+		{SHA: "c3", AuthorName: "Jane Doe", CoAuthors: []string{"bob", "mallory", "Ghost"}, Foreign: true},
+		{SHA: "c4", Author: "Opener", CoAuthors: []string{"bob", "opener"}},
+	}, info.Commits)
+}
+
+func TestUnresolvedLogins(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/pr-real":
+			fmt.Fprint(w, `{"login":"pr-real"}`)
+		case "/users/pr-broken":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	})
+
+	list, err := unresolvedLogins(context.Background(), client, nil, []string{"pr-real", "pr-made-up"})
+	require.NoError(t, err, "a co-author without an account does not fail the run")
+	assert.Equal(t, []string{"pr-made-up"}, list)
+
+	_, err = unresolvedLogins(context.Background(), client, nil, []string{"pr-broken"})
+	require.Error(t, err)
+}
+
+func TestParseCoAuthors(t *testing.T) {
+	msg := "subject\n\nbody mentions co-authored-by: nobody\n  Co-authored-by: A B <a@example.com>  \nCo-authored-by: <b@example.com>\nCo-authored-by:\n"
+	assert.Equal(t, []coAuthor{
+		{name: "A B", email: "a@example.com"},
+		{name: "", email: "b@example.com"},
+	}, parseCoAuthors(msg))
+	assert.Empty(t, parseCoAuthors(""))
+}
+
+func TestNoReplyLogin(t *testing.T) {
+	assert.Equal(t, "octocat", noReplyLogin("583231+octocat@users.noreply.github.com"))
+	assert.Equal(t, "octocat", noReplyLogin("OctoCat@users.noreply.github.com"))
+	assert.Empty(t, noReplyLogin("octocat@example.com"))
+}
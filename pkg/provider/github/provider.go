@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"math"
+	"slices"
 	"sync"
 	"time"
 
 	hub "github.com/google/go-github/v72/github"
 	"github.com/mchmarny/reputer/pkg/report"
 	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/mchmarny/reputer/pkg/trust"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -18,6 +19,11 @@ const (
 	pageSize       = 100
 	hoursInDay     = 24
 	maxConcurrency = 10
+
+	// maxSensitiveCommits bounds the commits per author whose files are
+	// fetched for the sensitive-path profile, newest first.
+	maxSensitiveCommits  = 100
+	sensitiveConcurrency = 5
 )
 
 // ListAuthors is a GitHub commit provider.
@@ -26,6 +32,7 @@ func ListAuthors(ctx context.Context, q report.Query) (*report.Report, error) {
 		"owner", q.Owner,
 		"repo", q.Repo,
 		"commit", q.Commit,
+		"as_of", q.AsOf,
 		"stats", q.Stats)
 
 	client, err := getClient()
@@ -33,13 +40,20 @@ func ListAuthors(ctx context.Context, q report.Query) (*report.Report, error) {
 		return nil, fmt.Errorf("error creating GitHub client: %w", err)
 	}
 
+	if err := prepareQuery(ctx, client, &q); err != nil {
+		return nil, err
+	}
+	now := referenceTime(q)
+
 	list := make(map[string]*report.Author)
+	walked := make(map[string]*authorCommits)
 	pageCounter := 1
 	totalCommitCounter := int64(0)
 
 	for {
 		opts := &hub.CommitsListOptions{
-			SHA: q.Commit,
+			SHA:   q.Commit,
+			Until: q.AsOf,
 			ListOptions: hub.ListOptions{
 				Page:    pageCounter,
 				PerPage: pageSize,
@@ -69,23 +83,9 @@ func ListAuthors(ctx context.Context, q report.Query) (*report.Report, error) {
 			login := c.GetAuthor().GetLogin()
 			if _, ok := list[login]; !ok {
 				list[login] = report.MakeAuthor(login)
+				walked[login] = &authorCommits{}
 			}
-
-			list[login].Stats.Commits++
-			if v := c.GetCommit().GetVerification(); v == nil || v.Verified == nil || !*v.Verified {
-				list[login].Stats.UnverifiedCommits++
-			}
-
-			// Track most recent commit date per author (commits arrive newest-first).
-			if list[login].Stats.LastCommitDays == 0 {
-				if cd := c.GetCommit().GetCommitter().GetDate(); !cd.IsZero() {
-					days := int64(math.Ceil(time.Now().UTC().Sub(cd.Time).Hours() / hoursInDay))
-					if days < 0 {
-						days = 0
-					}
-					list[login].Stats.LastCommitDays = days
-				}
-			}
+			recordCommit(list[login], walked[login], c, now)
 
 			totalCommitCounter++
 		}
@@ -97,19 +97,8 @@ func ListAuthors(ctx context.Context, q report.Query) (*report.Report, error) {
 		pageCounter++
 	}
 
-	rpt := &report.Report{
-		Repo:         q.Repo,
-		AtCommit:     q.Commit,
-		GeneratedOn:  time.Now().UTC(),
-		TotalCommits: totalCommitCounter,
-	}
-
-	rpt.Meta = &report.Meta{
-		ModelVersion: score.ModelVersion,
-		Categories:   score.Categories(),
-	}
-
 	totalContributors := len(list)
+	rpt := makeReport(&q, totalCommitCounter, totalContributors)
 
 	var mu sync.Mutex
 	authors := make([]*report.Author, 0, len(list))
@@ -118,8 +107,9 @@ func ListAuthors(ctx context.Context, q report.Query) (*report.Report, error) {
 	g.SetLimit(maxConcurrency)
 
 	for _, a := range list {
+		a.Commits = walked[a.Username].shas
 		g.Go(func() error {
-			if err := loadAuthor(gctx, client, a, q.Stats, totalCommitCounter, totalContributors, q.Owner, q.Name, q.TrustedOrgs); err != nil {
+			if err := loadAuthor(gctx, client, a, q, walked[a.Username], totalCommitCounter, totalContributors); err != nil {
 				return err
 			}
 			mu.Lock()
@@ -133,33 +123,114 @@ func ListAuthors(ctx context.Context, q report.Query) (*report.Report, error) {
 		return nil, fmt.Errorf("error loading authors: %w", err)
 	}
 
-	rpt.TotalContributors = int64(totalContributors)
+	rpt.Summary = report.Summarize(authors, q.RiskThreshold)
 	rpt.Contributors = authors
 
+	// Details are stripped only after the summary, which needs author stats.
+	if !q.Stats {
+		rpt.StripDetails(q.Explain)
+	}
+
 	return rpt, nil
 }
 
+// prepareQuery resolves the commit of a commit query to its full SHA and
+// as-of time, and merges the repo's trust file and trusted orgs into the
+// query.
+func prepareQuery(ctx context.Context, client *hub.Client, q *report.Query) error {
+	// Scoring at a commit is scored as of that commit unless a time is given.
+	if q.Commit != "" {
+		sha, date, err := fetchCommit(ctx, client, q.Owner, q.Name, q.Commit)
+		if err != nil {
+			return fmt.Errorf("error resolving commit for %s: %w", q.Repo, err)
+		}
+		q.Commit = sha
+		if q.AsOf.IsZero() {
+			q.AsOf = date
+		}
+	}
+
+	// The repo's own trust file is read from the default branch so a change
+	// under review cannot vouch for its own author.
+	if q.RepoTrust && q.Repo != "" {
+		rc, err := fetchTrustFile(ctx, client, q.Owner, q.Name)
+		if err != nil {
+			return fmt.Errorf("error loading trust file for %s: %w", q.Repo, err)
+		}
+		if q.Trust, err = q.Trust.Merge(rc); err != nil {
+			return fmt.Errorf("error loading trust file for %s: %w", q.Repo, err)
+		}
+	}
+	if q.Trust != nil {
+		q.TrustedOrgs = slices.Concat(q.TrustedOrgs, q.Trust.TrustedOrgs)
+		slices.Sort(q.TrustedOrgs)
+		q.TrustedOrgs = slices.Compact(q.TrustedOrgs)
+	}
+
+	return nil
+}
+
+// recordCommit adds a commit, listed newest first, to its author's stats.
+func recordCommit(a *report.Author, ac *authorCommits, c *hub.RepositoryCommit, now time.Time) {
+	ac.shas = append(ac.shas, c.GetSHA())
+
+	a.Stats.Commits++
+	if v := c.GetCommit().GetVerification(); v == nil || v.Verified == nil || !*v.Verified {
+		a.Stats.UnverifiedCommits++
+	} else if email := c.GetCommit().GetAuthor().GetEmail(); email != "" {
+		ac.emails = append(ac.emails, email)
+	}
+
+	// Track most recent commit date per author (commits arrive newest-first).
+	if a.Stats.LastCommitDays == 0 {
+		if cd := c.GetCommit().GetCommitter().GetDate(); !cd.IsZero() {
+			a.Stats.LastCommitDays = daysBetween(cd.Time, now)
+		}
+	}
+
+	// Oldest commit seen so far is the author's first commit in range.
+	if cd := c.GetCommit().GetCommitter().GetDate(); !cd.IsZero() {
+		ac.first = cd.Time
+	}
+}
+
+// referenceTime returns the moment the query is scored at: the as-of time
+// when set, otherwise now.
+func referenceTime(q report.Query) time.Time {
+	if !q.AsOf.IsZero() {
+		return q.AsOf.UTC()
+	}
+	return time.Now().UTC()
+}
+
+// authorCommits holds what the commit walk learned about a single author.
+type authorCommits struct {
+	// first is the date of the author's oldest commit in range.
+	first time.Time
+	// shas lists the author's commits in range, newest first.
+	shas []string
+	// emails lists the author emails of the author's verified commits.
+	emails []string
+}
+
 // loadAuthor loads the author details.
-func loadAuthor(ctx context.Context, client *hub.Client, a *report.Author, stats bool, totalCommits int64, totalContributors int, owner, repoName string, trustedOrgs []string) error {
+func loadAuthor(ctx context.Context, client *hub.Client, a *report.Author, q report.Query, ac *authorCommits, totalCommits int64, totalContributors int) error {
 	if client == nil {
 		return fmt.Errorf("client must be specified")
 	}
 	if a == nil {
 		return fmt.Errorf("author must be specified")
 	}
+	if ac == nil {
+		ac = &authorCommits{}
+	}
+	firstCommit := ac.first
+	now := referenceTime(q)
 
-	u, r, err := client.Users.Get(ctx, a.Username)
+	u, err := fetchUser(ctx, client, q.Accounts, a.Username)
 	if err != nil {
-		return fmt.Errorf("error getting user %s: %w", a.Username, err)
+		return err
 	}
-	waitForRateLimit(r)
-
-	slog.Debug("user",
-		"page_next", r.NextPage,
-		"page_last", r.LastPage,
-		"status", r.StatusCode,
-		"rate_limit", r.Rate.Limit,
-		"rate_remaining", r.Rate.Remaining)
 
 	a.Stats.Suspended = u.SuspendedAt != nil
 	a.Stats.CommitsVerified = a.Stats.UnverifiedCommits == 0 // not used by scoring; exposed in JSON for display
@@ -168,7 +239,7 @@ func loadAuthor(ctx context.Context, client *hub.Client, a *report.Author, stats
 	a.Stats.PublicRepos = int64(u.GetPublicRepos())
 
 	dc := u.GetCreatedAt().Time
-	a.Stats.AgeDays = int64(math.Ceil(time.Now().UTC().Sub(dc).Hours() / hoursInDay))
+	a.Stats.AgeDays = daysBetween(dc, now)
 	a.Context.Created = dc.Format(time.RFC3339)
 
 	if u.Name != nil {
@@ -183,25 +254,42 @@ func loadAuthor(ctx context.Context, client *hub.Client, a *report.Author, stats
 		a.Context.Company = u.GetCompany()
 	}
 
-	// Org membership check -- graceful degradation on error.
-	isMember, orgResp, memberErr := client.Organizations.IsMember(ctx, owner, a.Username)
-	waitForRateLimit(orgResp)
-	if memberErr != nil {
-		slog.Debug(fmt.Sprintf("org membership check [%s/%s]: %v", owner, a.Username, memberErr))
-	} else {
-		a.Stats.OrgMember = isMember
+	// Org membership check -- graceful degradation on error. User lookups
+	// without a repo have no owner org.
+	memberStatus := report.SignalNotApplicable
+	if q.Repo != "" {
+		isMember, memberErr := fetchOrgMember(ctx, client, q.Accounts, q.Owner, a.Username)
+		if memberErr != nil {
+			slog.Debug(fmt.Sprintf("org membership check [%s/%s]: %v", q.Owner, a.Username, memberErr))
+		} else {
+			a.Stats.OrgMember = isMember
+		}
+		memberStatus = fetchStatus(memberErr)
+	}
+
+	// Trusted domain check -- profile emails are verified by GitHub, as are
+	// the authors of verified commits. A match makes org checks unnecessary.
+	if d, ok := q.Trust.TrustedDomain(append([]string{a.Context.Email}, ac.emails...)...); ok {
+		a.Stats.TrustedDomain = d
+		a.Overrides = append(a.Overrides, trust.Override{Type: trust.OverrideTrustedDomain, Subject: d})
 	}
 
 	// Trusted org membership check -- short-circuit on first match.
+	trustedStatus := report.SignalCollected
+	trustedOrgs := q.TrustedOrgs
+	if a.Stats.TrustedDomain != "" {
+		trustedOrgs = nil
+	}
 	for _, org := range trustedOrgs {
-		isTrusted, tResp, tErr := client.Organizations.IsMember(ctx, org, a.Username)
-		waitForRateLimit(tResp)
+		isTrusted, tErr := fetchOrgMember(ctx, client, q.Accounts, org, a.Username)
 		if tErr != nil {
 			slog.Debug(fmt.Sprintf("trusted org check [%s/%s]: %v", org, a.Username, tErr))
+			trustedStatus = worstStatus(trustedStatus, fetchStatus(tErr))
 			continue
 		}
 		if isTrusted {
 			a.Stats.TrustedOrgMember = true
+			a.Overrides = append(a.Overrides, trust.Override{Type: trust.OverrideTrustedOrg, Subject: org})
 			break
 		}
 	}
@@ -212,35 +300,62 @@ func loadAuthor(ctx context.Context, client *hub.Client, a *report.Author, stats
 	a.Stats.HasLocation = u.Location != nil && *u.Location != ""
 	a.Stats.HasWebsite = u.Blog != nil && *u.Blog != ""
 
-	// Concurrent v3 signal fetches.
+	// Concurrent v3 signal fetches. Errors are recorded per signal rather
+	// than returned so one failed fetch does not cancel the others.
 	var (
 		prResult    prStats
 		recentCount int64
-		forkedCount int64
+		ownedResult ownedRepos
 		assocResult string
+		prevResult  time.Time
+		commitFiles [][]string
+
+		prErr, recentErr, ownedErr, assocErr, prevErr, filesErr error
 	)
 
 	sg, sgctx := errgroup.WithContext(ctx)
 
 	sg.Go(func() error {
-		prResult = fetchPRStats(sgctx, client, a.Username)
+		prResult, prErr = getAccount(sgctx, q.Accounts, cacheKey("prs", a.Username, timeKey(q.AsOf)), func(ctx context.Context) (prStats, error) {
+			return fetchPRStats(ctx, client, a.Username, q.AsOf)
+		})
 		return nil
 	})
 
 	sg.Go(func() error {
-		recentCount = fetchRecentPRRepoCount(sgctx, client, a.Username)
+		recentCount, recentErr = getAccount(sgctx, q.Accounts, cacheKey("recent", a.Username, timeKey(q.AsOf)), func(ctx context.Context) (int64, error) {
+			return fetchRecentPRRepoCount(ctx, client, a.Username, q.AsOf)
+		})
 		return nil
 	})
 
 	sg.Go(func() error {
-		forkedCount = fetchForkedRepoCount(sgctx, client, a.Username)
+		ownedResult, ownedErr = getAccount(sgctx, q.Accounts, cacheKey("owned", a.Username, timeKey(q.AsOf)), func(ctx context.Context) (ownedRepos, error) {
+			return fetchOwnedRepos(ctx, client, a.Username, q.AsOf)
+		})
 		return nil
 	})
 
-	sg.Go(func() error {
-		assocResult = fetchAuthorAssociation(sgctx, client, a.Username, owner, repoName)
-		return nil
-	})
+	if q.Repo != "" {
+		sg.Go(func() error {
+			assocResult, assocErr = fetchAuthorAssociation(sgctx, client, a.Username, q.Owner, q.Name, q.AsOf)
+			return nil
+		})
+	}
+
+	if !firstCommit.IsZero() {
+		sg.Go(func() error {
+			prevResult, prevErr = fetchPrevActivity(sgctx, client, a.Username, firstCommit)
+			return nil
+		})
+	}
+
+	if len(q.SensitivePaths) > 0 {
+		sg.Go(func() error {
+			commitFiles, filesErr = fetchCommitFiles(sgctx, client, q.Owner, q.Name, ac.shas)
+			return nil
+		})
+	}
 
 	if err := sg.Wait(); err != nil {
 		slog.Debug(fmt.Sprintf("v3 signal fetch error for %s: %v", a.Username, err))
@@ -249,15 +364,70 @@ func loadAuthor(ctx context.Context, client *hub.Client, a *report.Author, stats
 	a.Stats.PRsMerged = prResult.Merged
 	a.Stats.PRsClosed = prResult.Closed
 	a.Stats.RecentPRRepoCount = recentCount
-	a.Stats.ForkedRepos = forkedCount
+	a.Stats.ForkedRepos = ownedResult.Forked
 	a.Stats.AuthorAssociation = assocResult
 
-	calculateReputation(a, totalCommits, totalContributors)
+	// The profile's repo count is current; as of an earlier time, count the
+	// owned repos that existed then.
+	if !q.AsOf.IsZero() && ownedErr == nil {
+		a.Stats.PublicRepos = ownedResult.Total
+	}
+
+	for _, err := range []error{prErr, recentErr, ownedErr, assocErr, prevErr, filesErr} {
+		if err != nil {
+			slog.Debug(fmt.Sprintf("v3 signal fetch error for %s: %v", a.Username, err))
+		}
+	}
+	trusted := a.Stats.TrustedOrgMember || a.Stats.TrustedDomain != ""
+	a.Stats.SignalStatus = signalStatus(prErr, recentErr, ownedErr, assocErr,
+		assocResult, memberStatus, trustedStatus, trusted)
+
+	// Without a repo, association only reflects trusted org membership.
+	if q.Repo == "" {
+		for _, name := range repoSignals {
+			a.Stats.SignalStatus[name] = report.SignalNotApplicable
+		}
+		a.Stats.SignalStatus[score.SignalAssociation] = report.SignalNotApplicable
+		if trusted {
+			a.Stats.SignalStatus[score.SignalAssociation] = report.SignalCollected
+		}
+	}
+
+	// Dormancy is measured from the later of last public activity and account
+	// creation. A failed lookup is recorded and skips detection, rather than
+	// reading as no activity since creation.
+	if !firstCommit.IsZero() {
+		a.Stats.SignalStatus[report.SignalReactivation] = fetchStatus(prevErr)
+	}
+	if !firstCommit.IsZero() && prevErr == nil {
+		if prevResult.Before(dc) {
+			prevResult = dc
+		}
+		a.Stats.DormantDays, a.Stats.Reactivated = detectReactivation(prevResult, firstCommit,
+			now, q.DormancyDays, q.ReactivationWindowDays)
+		a.Stats.PrevActivity = prevResult.UTC().Format(time.RFC3339)
+		a.Stats.FirstRepoCommit = firstCommit.UTC().Format(time.RFC3339)
+	}
+
+	// A failed fetch leaves the profile empty and marks it unavailable,
+	// rather than reading as no sensitive changes.
+	if len(q.SensitivePaths) > 0 {
+		a.Stats.SignalStatus[report.SignalSensitivePaths] = fetchStatus(filesErr)
+		if filesErr == nil {
+			a.Stats.SensitiveCommits, a.Stats.SensitiveFiles = profileSensitivePaths(commitFiles, q.SensitivePaths)
+			a.Stats.ProfiledCommits = int64(len(commitFiles))
+		}
+	}
 
-	if !stats {
-		a.Stats = nil
-		a.Context = nil
+	calculateReputation(a, q.Model, totalCommits, totalContributors)
+	a.Completeness = a.Stats.Completeness()
+	compareReputation(a, q.CompareModels, totalCommits, totalContributors)
+
+	if err := applyPolicy(a, q.Policy, q.Repo, totalCommits, totalContributors); err != nil {
+		return err
 	}
 
+	applyTrust(a, q.Trust)
+
 	return nil
 }
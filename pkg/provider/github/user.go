@@ -0,0 +1,154 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	hub "github.com/google/go-github/v72/github"
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/mchmarny/reputer/pkg/score"
+)
+
+// repoSignals are the signals computed from an author's commits to the
+// queried repo. User lookups without a repo mark them not applicable.
+var repoSignals = []string{
+	score.SignalProvenance,
+	score.SignalProportion,
+	score.SignalRecency,
+}
+
+// GetUser is a GitHub single-user provider. Instead of walking every commit
+// in the repo, it lists only the user's commits and counts the repo's
+// commits and contributors. Without a repo, only account-level signals are
+// collected.
+func GetUser(ctx context.Context, q report.Query) (*report.Report, error) {
+	slog.Debug("get user",
+		"user", q.User,
+		"repo", q.Repo,
+		"commit", q.Commit,
+		"as_of", q.AsOf,
+		"stats", q.Stats)
+
+	client, err := getClient()
+	if err != nil {
+		return nil, fmt.Errorf("error creating GitHub client: %w", err)
+	}
+
+	if err := prepareQuery(ctx, client, &q); err != nil {
+		return nil, err
+	}
+
+	totalCommits, totalContributors, err := fetchRepoTotals(ctx, client, q)
+	if err != nil {
+		return nil, err
+	}
+
+	rpt := makeReport(&q, totalCommits, totalContributors)
+
+	a, err := scoreUser(ctx, client, q, q.User, totalCommits, totalContributors)
+	if err != nil {
+		return nil, err
+	}
+
+	rpt.Contributors = []*report.Author{a}
+
+	if !q.Stats {
+		rpt.StripDetails(q.Explain)
+	}
+
+	return rpt, nil
+}
+
+// fetchRepoTotals returns the number of commits and contributors of the
+// queried repo, or zeros when the query has no repo.
+func fetchRepoTotals(ctx context.Context, client *hub.Client, q report.Query) (int64, int, error) {
+	if q.Repo == "" {
+		return 0, 0, nil
+	}
+
+	commits, err := fetchCommitCount(ctx, client, q.Owner, q.Name, q.Commit, q.AsOf)
+	if err != nil {
+		return 0, 0, err
+	}
+	contributors, err := fetchContributorCount(ctx, client, q.Owner, q.Name)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return commits, contributors, nil
+}
+
+// makeReport returns an empty report of the query, defaulting its model.
+func makeReport(q *report.Query, totalCommits int64, totalContributors int) *report.Report {
+	rpt := &report.Report{
+		Repo:              q.Repo,
+		AtCommit:          q.Commit,
+		GeneratedOn:       time.Now().UTC(),
+		TotalCommits:      totalCommits,
+		TotalContributors: int64(totalContributors),
+	}
+	if !q.AsOf.IsZero() {
+		rpt.AsOf = &q.AsOf
+	}
+
+	if q.Model == nil {
+		q.Model = score.DefaultModel()
+	}
+	rpt.Meta = report.MakeMeta(q.Model)
+	for _, m := range q.CompareModels {
+		rpt.Meta.Comparison = append(rpt.Meta.Comparison, report.MakeMeta(m))
+	}
+
+	return rpt
+}
+
+// scoreUser collects the signals of a single account and scores it. With a
+// repo, only the account's own commits in it are listed.
+func scoreUser(ctx context.Context, client *hub.Client, q report.Query, login string, totalCommits int64, totalContributors int) (*report.Author, error) {
+	a := report.MakeAuthor(login)
+	ac := &authorCommits{}
+
+	if q.Repo != "" {
+		if err := listUserCommits(ctx, client, q, login, a, ac, referenceTime(q)); err != nil {
+			return nil, err
+		}
+	}
+	a.Commits = ac.shas
+
+	if err := loadAuthor(ctx, client, a, q, ac, totalCommits, totalContributors); err != nil {
+		return nil, fmt.Errorf("error loading user %s: %w", login, err)
+	}
+
+	return a, nil
+}
+
+// listUserCommits walks the user's commits in the queried repo.
+func listUserCommits(ctx context.Context, client *hub.Client, q report.Query, login string, a *report.Author, ac *authorCommits, now time.Time) error {
+	for page := 1; ; page++ {
+		opts := &hub.CommitsListOptions{
+			SHA:    q.Commit,
+			Author: login,
+			Until:  q.AsOf,
+			ListOptions: hub.ListOptions{
+				Page:    page,
+				PerPage: pageSize,
+			},
+		}
+
+		p, r, err := client.Repositories.ListCommits(ctx, q.Owner, q.Name, opts)
+		if err != nil {
+			return fmt.Errorf("error listing commits by %s in %s/%s: %w", login, q.Owner, q.Name, err)
+		}
+		waitForRateLimit(r)
+
+		for _, c := range p {
+			recordCommit(a, ac, c, now)
+		}
+
+		if len(p) < pageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	lab "gitlab.com/gitlab-org/api/client-go"
+)
+
+const host = "gitlab.com"
+
+// ListRepos is a GitLab repo lister. It returns the URIs of the projects in
+// the group and its subgroups that pass the filter, sorted by path. The
+// group may itself be nested, e.g. group/subgroup.
+func ListRepos(ctx context.Context, group string, f report.RepoFilter) ([]string, error) {
+	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("GITLAB_TOKEN environment variable must be set")
+	}
+
+	client, err := lab.NewClient(token, lab.WithHTTPClient(&http.Client{
+		Timeout: httpTimeout,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("error creating GitLab client: %w", err)
+	}
+
+	return listGroupProjects(ctx, client, group, f)
+}
+
+// listGroupProjects lists the projects of the group and its subgroups that
+// pass the filter.
+func listGroupProjects(ctx context.Context, client *lab.Client, group string, f report.RepoFilter) ([]string, error) {
+	var list []string
+	for page := int64(1); ; page++ {
+		opts := &lab.ListGroupProjectsOptions{
+			IncludeSubGroups: lab.Ptr(true),
+			OrderBy:          lab.Ptr("path"),
+			Sort:             lab.Ptr("asc"),
+			ListOptions: lab.ListOptions{
+				Page:    page,
+				PerPage: pageSize,
+			},
+		}
+
+		p, _, err := client.Groups.ListGroupProjects(group, opts, lab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("error listing projects of %s: %w", group, err)
+		}
+
+		for _, proj := range p {
+			if !f.Match(proj.Archived, proj.ForkedFromProject != nil, string(proj.Visibility), proj.Topics) {
+				slog.Debug("skipping project", "project", proj.PathWithNamespace)
+				continue
+			}
+			list = append(list, host+"/"+proj.PathWithNamespace)
+		}
+
+		if int64(len(p)) < pageSize {
+			return list, nil
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestListGroupProjects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/groups/g/sub/projects", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("include_subgroups"))
+		fmt.Fprint(w, `[
+			{"path_with_namespace":"g/sub/a"},
+			{"path_with_namespace":"g/sub/deep/b"},
+			{"path_with_namespace":"g/sub/old","archived":true}
+		]`)
+	}))
+	defer srv.Close()
+
+	client, err := lab.NewClient("token", lab.WithBaseURL(srv.URL))
+	require.NoError(t, err)
+
+	list, err := listGroupProjects(context.Background(), client, "g/sub", report.RepoFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gitlab.com/g/sub/a", "gitlab.com/g/sub/deep/b"}, list)
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -11,14 +12,35 @@ import (
 	"github.com/mchmarny/reputer/pkg/report"
 )
 
+// ErrUnsupported is returned when no provider supports the query's host or
+// the requested lookup on it.
+var ErrUnsupported = errors.New("unsupported git provider")
+
 var providers = map[string]CommitProvider{
 	"github.com": github.ListAuthors,
 	"gitlab.com": gitlab.ListAuthors,
 }
 
+var userProviders = map[string]CommitProvider{
+	"github.com": github.GetUser,
+}
+
+var pullRequestProviders = map[string]CommitProvider{
+	"github.com": github.GetPullRequest,
+}
+
+var repoListers = map[string]RepoLister{
+	"github.com": github.ListRepos,
+	"gitlab.com": gitlab.ListRepos,
+}
+
 // CommitProvider is a function that returns a list of authors for the given repo and commit.
 type CommitProvider func(ctx context.Context, q report.Query) (*report.Report, error)
 
+// RepoLister is a function that returns the URIs of an org's repos that
+// pass the filter.
+type RepoLister func(ctx context.Context, org string, f report.RepoFilter) ([]string, error)
+
 // GetAuthors returns a report of authors for the given repo and commit.
 func GetAuthors(ctx context.Context, q report.Query) (*report.Report, error) {
 	if err := q.Validate(); err != nil {
@@ -29,7 +51,7 @@ func GetAuthors(ctx context.Context, q report.Query) (*report.Report, error) {
 
 	p, ok := providers[q.Kind]
 	if !ok {
-		return nil, fmt.Errorf("unsupported git provider: %s", q.Kind)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, q.Kind)
 	}
 
 	r, err := p(ctx, q)
@@ -37,6 +59,7 @@ func GetAuthors(ctx context.Context, q report.Query) (*report.Report, error) {
 		return nil, fmt.Errorf("error listing authors with %v: %w", q, err)
 	}
 
+	r.SchemaVersion = report.SchemaVersion
 	r.SortAuthors()
 
 	slog.Debug("listed commits",
@@ -46,3 +69,98 @@ func GetAuthors(ctx context.Context, q report.Query) (*report.Report, error) {
 
 	return r, nil
 }
+
+// GetUser returns a report of the single user named by the query, scored
+// against the query's repo when one is set.
+func GetUser(ctx context.Context, q report.Query) (*report.Report, error) {
+	if q.User == "" {
+		return nil, errors.New("invalid query: user must be specified")
+	}
+	if err := q.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	start := time.Now()
+
+	p, ok := userProviders[q.Kind]
+	if !ok {
+		return nil, fmt.Errorf("%w for user lookup: %s", ErrUnsupported, q.Kind)
+	}
+
+	r, err := p(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user with %v: %w", q, err)
+	}
+
+	r.SchemaVersion = report.SchemaVersion
+
+	slog.Debug("scored user",
+		"user", q.User,
+		"duration", time.Since(start))
+
+	return r, nil
+}
+
+// GetPullRequest returns a report of the opener and the commit authors and
+// co-authors of the pull request named by the query.
+func GetPullRequest(ctx context.Context, q report.Query) (*report.Report, error) {
+	if q.PullRequest <= 0 {
+		return nil, errors.New("invalid query: pull request must be specified")
+	}
+	if err := q.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	start := time.Now()
+
+	p, ok := pullRequestProviders[q.Kind]
+	if !ok {
+		return nil, fmt.Errorf("%w for pull requests: %s", ErrUnsupported, q.Kind)
+	}
+
+	r, err := p(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pull request with %v: %w", q, err)
+	}
+
+	r.SchemaVersion = report.SchemaVersion
+	r.SortAuthors()
+
+	slog.Debug("scored pull request",
+		"pull_request", q.PullRequest,
+		"authors", len(r.Contributors),
+		"duration", time.Since(start))
+
+	return r, nil
+}
+
+// ListRepos returns the URIs of the repos of the organization or group,
+// given as host/org, that pass the filter.
+func ListRepos(ctx context.Context, org string, f report.RepoFilter) ([]string, error) {
+	host, name, err := report.ParseOrg(org)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	p, ok := repoListers[host]
+	if !ok {
+		return nil, fmt.Errorf("%w for orgs: %s", ErrUnsupported, host)
+	}
+
+	list, err := p(ctx, name, f)
+	if err != nil {
+		return nil, fmt.Errorf("error listing repos of %s: %w", org, err)
+	}
+
+	slog.Debug("listed repos",
+		"org", org,
+		"repos", len(list),
+		"duration", time.Since(start))
+
+	return list, nil
+}
@@ -18,7 +18,7 @@ func TestGetAuthorsUnsupportedProvider(t *testing.T) {
 	}
 
 	_, err := GetAuthors(context.Background(), q)
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrUnsupported)
 	assert.Contains(t, err.Error(), "unsupported git provider")
 }
 
@@ -27,3 +27,39 @@ func TestGetAuthorsEmptyQuery(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid query")
 }
+
+func TestGetUserUnsupportedProvider(t *testing.T) {
+	_, err := GetUser(context.Background(), report.Query{User: "u", Kind: "gitlab.com"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported git provider for user lookup")
+}
+
+func TestGetUserInvalidQuery(t *testing.T) {
+	_, err := GetUser(context.Background(), report.Query{Kind: "github.com"})
+	require.ErrorContains(t, err, "user must be specified")
+
+	_, err = GetUser(context.Background(), report.Query{User: "u"})
+	require.ErrorContains(t, err, "kind must be specified")
+}
+
+func TestGetPullRequestInvalidQuery(t *testing.T) {
+	_, err := GetPullRequest(context.Background(), report.Query{Repo: "github.com/o/r", Kind: "github.com", Owner: "o", Name: "r"})
+	require.ErrorContains(t, err, "pull request must be specified")
+
+	_, err = GetPullRequest(context.Background(), report.Query{PullRequest: 1})
+	require.ErrorContains(t, err, "repo must be specified")
+
+	_, err = GetPullRequest(context.Background(), report.Query{PullRequest: 1, Repo: "gitlab.com/g/p", Kind: "gitlab.com", Owner: "g", Name: "p"})
+	require.ErrorContains(t, err, "unsupported git provider for pull requests")
+}
+
+func TestListReposInvalid(t *testing.T) {
+	_, err := ListRepos(context.Background(), "bitbucket.org/o", report.RepoFilter{})
+	require.ErrorContains(t, err, "unsupported git provider for orgs")
+
+	_, err = ListRepos(context.Background(), "github.com/o/r", report.RepoFilter{})
+	require.ErrorContains(t, err, "invalid org")
+
+	_, err = ListRepos(context.Background(), "github.com/o", report.RepoFilter{Visibility: "secret"})
+	require.ErrorContains(t, err, "invalid visibility")
+}
@@ -0,0 +1,3 @@
+// Package render converts reputation reports into output formats other
+// than the JSON and YAML encodings of [report.Report] itself.
+package render
@@ -0,0 +1,358 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mchmarny/reputer/pkg/policy"
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/mchmarny/reputer/pkg/trust"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "reputer"
+	toolURI      = "https://github.com/mchmarny/reputer"
+
+	// maxCommitURLs limits how many authored commits each result lists.
+	maxCommitURLs = 10
+
+	// anchorFile is the repo file results are anchored to. Code scanning
+	// requires a file location, and contributor findings have no file of
+	// their own.
+	anchorFile = "README.md"
+	srcRoot    = "%SRCROOT%"
+)
+
+// SARIF levels.
+const (
+	levelError   = "error"
+	levelWarning = "warning"
+)
+
+// Rule IDs that are not tied to a single signal.
+const (
+	ruleLowReputation = "reputer/low-reputation"
+	ruleSuspended     = "reputer/suspended"
+	ruleDenied        = "reputer/trust/deny"
+	ruleForeignCommit = "reputer/pr/foreign-commit"
+	rulePolicyPrefix  = "reputer/policy/"
+	ruleSignalPrefix  = "reputer/signal/"
+)
+
+// signalDescriptions explains what a weak value of each signal means.
+var signalDescriptions = map[string]string{
+	score.SignalProvenance:   "Few verified commits or an immature account",
+	score.SignalAge:          "Young account",
+	score.SignalAssociation:  "No established association with the repo",
+	score.SignalProfile:      "Sparse public profile",
+	score.SignalProportion:   "Small share of the repo's commits",
+	score.SignalRecency:      "No recent commits to the repo",
+	score.SignalPRAcceptance: "Low PR acceptance rate",
+	score.SignalFollowers:    "Low follower ratio",
+	score.SignalRepoCount:    "Few public repositories",
+	score.SignalBurst:        "PR activity across many repos in a short time",
+	score.SignalForkRatio:    "Mostly forked, few original repositories",
+}
+
+// SARIFLog is a SARIF 2.1.0 log.
+type SARIFLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is a single analysis run.
+type SARIFRun struct {
+	Tool       SARIFTool      `json:"tool"`
+	Results    []SARIFResult  `json:"results"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+// SARIFTool describes the analysis tool.
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver describes the tool component and its rules.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes a rule results are reported against.
+type SARIFRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     SARIFMessage       `json:"shortDescription"`
+	DefaultConfiguration SARIFConfiguration `json:"defaultConfiguration"`
+}
+
+// SARIFConfiguration holds a rule's default level.
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFMessage is a plain-text message.
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is a single finding.
+type SARIFResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             SARIFMessage      `json:"message"`
+	Locations           []SARIFLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+// SARIFLocation points at a file in the repo.
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation identifies an artifact by URI.
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+// SARIFArtifactLocation is the URI of an artifact, relative to uriBaseId
+// when set.
+type SARIFArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// SARIF writes the report as a SARIF log. Contributors scoring below
+// threshold, policy matches that cap, set, or flag a score, denylist
+// overrides, and pull request commits not authored by the opener each
+// become a result. Contributor results use the rule of the
+// signal that lost the most weight; their level is error below half the
+// threshold and warning otherwise.
+func SARIF(w io.Writer, r *report.Report, threshold float64) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(BuildSARIF(r, threshold)); err != nil {
+		return fmt.Errorf("error encoding SARIF: %w", err)
+	}
+
+	return nil
+}
+
+// BuildSARIF converts the report into a SARIF log. See [SARIF].
+func BuildSARIF(r *report.Report, threshold float64) *SARIFLog {
+	b := &sarifBuilder{index: make(map[string]int)}
+
+	b.rule(ruleLowReputation, "LowReputation", "Contributor reputation is below the threshold", levelWarning)
+	b.rule(ruleSuspended, "SuspendedAccount", "Contributor account is suspended", levelError)
+	b.rule(ruleDenied, "DenylistedUser", "Contributor is denylisted in the trust file", levelError)
+	for _, name := range score.SignalNames() {
+		b.rule(ruleSignalPrefix+name, signalRuleName(name), signalDescriptions[name], levelWarning)
+	}
+
+	run := SARIFRun{Results: []SARIFResult{}}
+	if r != nil {
+		run.Properties = map[string]any{"repo": r.Repo, "risk_threshold": threshold}
+		if r.AtCommit != "" {
+			run.Properties["commit"] = r.AtCommit
+		}
+		if r.Meta != nil {
+			run.Properties["model_version"] = r.Meta.ModelVersion
+		}
+
+		for _, a := range r.Contributors {
+			if a == nil {
+				continue
+			}
+			urls := commitURLs(r.Repo, a.Commits)
+			run.Results = append(run.Results, b.authorResults(a, threshold, urls)...)
+		}
+
+		if pr := r.PullRequest; pr != nil {
+			run.Properties["pull_request"] = pr.Number
+			run.Results = append(run.Results, b.foreignCommitResults(r.Repo, pr)...)
+		}
+	}
+
+	run.Tool = SARIFTool{Driver: SARIFDriver{Name: toolName, InformationURI: toolURI, Rules: b.rules}}
+
+	return &SARIFLog{Version: sarifVersion, Schema: sarifSchema, Runs: []SARIFRun{run}}
+}
+
+// sarifBuilder collects rules and indexes them by ID.
+type sarifBuilder struct {
+	rules []SARIFRule
+	index map[string]int
+}
+
+// rule registers a rule once.
+func (b *sarifBuilder) rule(id, name, desc, level string) {
+	if _, ok := b.index[id]; ok {
+		return
+	}
+	b.index[id] = len(b.rules)
+	b.rules = append(b.rules, SARIFRule{
+		ID:                   id,
+		Name:                 name,
+		ShortDescription:     SARIFMessage{Text: desc},
+		DefaultConfiguration: SARIFConfiguration{Level: level},
+	})
+}
+
+// result builds a result for a registered rule, anchored to the repo's
+// anchor file, with the contributor's commit URLs in its properties.
+func (b *sarifBuilder) result(ruleID, level, msg, username string, urls []string) SARIFResult {
+	res := SARIFResult{
+		RuleID:              ruleID,
+		RuleIndex:           b.index[ruleID],
+		Level:               level,
+		Message:             SARIFMessage{Text: msg},
+		Locations:           anchorLocations(),
+		PartialFingerprints: map[string]string{"reputer/author": username},
+		Properties:          map[string]any{},
+	}
+	if len(urls) > 0 {
+		res.Properties["commits"] = urls
+	}
+	return res
+}
+
+// authorResults returns the results for a single contributor.
+func (b *sarifBuilder) authorResults(a *report.Author, threshold float64, urls []string) []SARIFResult {
+	var results []SARIFResult
+
+	if a.Reputation < threshold {
+		ruleID, msg := lowReputationFinding(a, threshold)
+		level := levelWarning
+		if a.Reputation < threshold/2 {
+			level = levelError
+		}
+		res := b.result(ruleID, level, msg, a.Username, urls)
+		res.Properties["reputation"] = a.Reputation
+		results = append(results, res)
+	}
+
+	for _, m := range a.Policy {
+		if m.Action == policy.ActionFloor {
+			continue
+		}
+		id := rulePolicyPrefix + m.Rule
+		desc := m.Description
+		if desc == "" {
+			desc = fmt.Sprintf("Policy rule %s matched", m.Rule)
+		}
+		b.rule(id, m.Rule, desc, levelWarning)
+		results = append(results, b.result(id, levelWarning,
+			fmt.Sprintf("%s matched policy rule %s (%s)", a.Username, m.Rule, m.Action), a.Username, urls))
+	}
+
+	for _, o := range a.Overrides {
+		if o.Type != trust.OverrideDeny {
+			continue
+		}
+		results = append(results, b.result(ruleDenied, levelError,
+			fmt.Sprintf("%s is denylisted: %s", a.Username, o.Reason), a.Username, urls))
+	}
+
+	return results
+}
+
+// foreignCommitResults returns a result for each pull request commit not
+// authored by the opener.
+func (b *sarifBuilder) foreignCommitResults(repo string, pr *report.PullRequest) []SARIFResult {
+	var results []SARIFResult
+	for _, c := range pr.Commits {
+		if !c.Foreign {
+			continue
+		}
+		b.rule(ruleForeignCommit, "ForeignCommit", "Pull request commit not authored by the pull request opener", levelWarning)
+		author := c.Author
+		if author == "" {
+			author = c.AuthorName
+		}
+		results = append(results, b.result(ruleForeignCommit, levelWarning,
+			fmt.Sprintf("commit %s in #%d opened by %s was authored by %s", c.SHA, pr.Number, pr.Author, author),
+			author, commitURLs(repo, []string{c.SHA})))
+	}
+	return results
+}
+
+// lowReputationFinding picks the rule and message for a contributor below
+// the threshold: the signal that lost the most weight when a breakdown is
+// available, otherwise the generic low-reputation rule.
+func lowReputationFinding(a *report.Author, threshold float64) (string, string) {
+	base := fmt.Sprintf("%s has reputation %.2f (below %.2f)", a.Username, a.Reputation, threshold)
+
+	if a.Breakdown == nil {
+		return ruleLowReputation, base
+	}
+	if a.Breakdown.Suspended {
+		return ruleSuspended, base + "; account is suspended"
+	}
+
+	var weakest *score.SignalResult
+	var lost float64
+	for i, s := range a.Breakdown.Signals {
+		if s.Unavailable {
+			continue
+		}
+		if l := s.Weight - s.Contribution; weakest == nil || l > lost {
+			weakest, lost = &a.Breakdown.Signals[i], l
+		}
+	}
+	if weakest == nil {
+		return ruleLowReputation, base
+	}
+
+	msg := fmt.Sprintf("%s; weakest signal: %s", base, weakest.Name)
+	if weakest.Note != "" {
+		msg += fmt.Sprintf(" (%s)", weakest.Note)
+	}
+	return ruleSignalPrefix + weakest.Name, msg
+}
+
+// anchorLocations returns the single location every result is anchored to.
+func anchorLocations() []SARIFLocation {
+	return []SARIFLocation{{
+		PhysicalLocation: SARIFPhysicalLocation{
+			ArtifactLocation: SARIFArtifactLocation{URI: anchorFile, URIBaseID: srcRoot},
+		},
+	}}
+}
+
+// commitURLs returns the URLs of up to maxCommitURLs commits in the repo.
+func commitURLs(repo string, shas []string) []string {
+	if repo == "" || len(shas) == 0 {
+		return nil
+	}
+
+	shas = shas[:min(len(shas), maxCommitURLs)]
+	urls := make([]string, 0, len(shas))
+	for _, sha := range shas {
+		urls = append(urls, fmt.Sprintf("https://%s/commit/%s", repo, sha))
+	}
+
+	return urls
+}
+
+// signalRuleName converts a signal name such as pr_acceptance into a
+// PascalCase rule name such as WeakPrAcceptance.
+func signalRuleName(signal string) string {
+	var b strings.Builder
+	b.WriteString("Weak")
+	for _, p := range strings.Split(signal, "_") {
+		if p == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+	}
+	return b.String()
+}
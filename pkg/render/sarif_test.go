@@ -0,0 +1,158 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mchmarny/reputer/pkg/policy"
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/mchmarny/reputer/pkg/trust"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() *report.Report {
+	young := score.Compute(score.Signals{AgeDays: 3, Commits: 1, TotalCommits: 10, TotalContributors: 2})
+	suspended := score.Compute(score.Signals{Suspended: true})
+
+	return &report.Report{
+		Repo:     "github.com/o/r",
+		AtCommit: "abc123",
+		Meta:     report.MakeMeta(nil),
+		Contributors: []*report.Author{
+			{Username: "trusted", Reputation: 0.9, Commits: []string{"a1"}},
+			{Username: "young", Reputation: young.Score, Breakdown: &young, Commits: []string{"b1", "b2"}},
+			{Username: "gone", Reputation: 0, Breakdown: &suspended},
+			{Username: "unexplained", Reputation: 0.4},
+			{
+				Username:   "flagged",
+				Reputation: 0.8,
+				Policy: []policy.Match{
+					{Rule: "touches-ci", Action: policy.ActionFlag, Description: "Changes CI workflows"},
+					{Rule: "maintainer-floor", Action: policy.ActionFloor},
+				},
+				Overrides: []trust.Override{{Type: trust.OverrideDeny, Subject: "flagged", Reason: "compromised"}},
+			},
+		},
+	}
+}
+
+func TestBuildSARIF(t *testing.T) {
+	log := BuildSARIF(testReport(), 0.5)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Equal(t, "reputer", run.Tool.Driver.Name)
+	assert.Equal(t, "abc123", run.Properties["commit"])
+
+	byRule := make(map[string]SARIFResult)
+	byAuthor := make(map[string]SARIFResult)
+	for _, res := range run.Results {
+		require.Less(t, res.RuleIndex, len(run.Tool.Driver.Rules))
+		assert.Equal(t, res.RuleID, run.Tool.Driver.Rules[res.RuleIndex].ID)
+		byRule[res.RuleID] = res
+		byAuthor[res.PartialFingerprints["reputer/author"]] = res
+	}
+	require.Len(t, run.Results, 5)
+	assert.NotContains(t, byAuthor, "trusted")
+
+	young := byAuthor["young"]
+	assert.Equal(t, levelError, young.Level)
+	assert.True(t, strings.HasPrefix(young.RuleID, ruleSignalPrefix))
+	assert.Contains(t, young.Message.Text, "young has reputation")
+	assert.Contains(t, young.Message.Text, "weakest signal: "+strings.TrimPrefix(young.RuleID, ruleSignalPrefix))
+	require.Len(t, young.Locations, 1)
+	assert.Equal(t, SARIFArtifactLocation{URI: anchorFile, URIBaseID: srcRoot}, young.Locations[0].PhysicalLocation.ArtifactLocation)
+	assert.Equal(t, []string{"https://github.com/o/r/commit/b1", "https://github.com/o/r/commit/b2"}, young.Properties["commits"])
+
+	assert.Equal(t, "gone", byRule[ruleSuspended].PartialFingerprints["reputer/author"])
+	assert.Equal(t, levelWarning, byRule[ruleLowReputation].Level)
+	assert.Equal(t, levelError, byRule[ruleDenied].Level)
+	assert.Contains(t, byRule[ruleDenied].Message.Text, "compromised")
+
+	// Flags are results; floors are not.
+	assert.Contains(t, byRule, rulePolicyPrefix+"touches-ci")
+	assert.NotContains(t, byRule, rulePolicyPrefix+"maintainer-floor")
+}
+
+func TestBuildSARIFPullRequest(t *testing.T) {
+	r := testReport()
+	r.PullRequest = &report.PullRequest{
+		Number: 7,
+		Author: "trusted",
+		Commits: []report.PullRequestCommit{
+			{SHA: "a1", Author: "trusted"},
+			{SHA: "b1", Author: "young", Foreign: true},
+			{SHA: "c1", AuthorName: "Jane Doe", Foreign: true},
+		},
+		ForeignCommits: 2,
+	}
+
+	run := BuildSARIF(r, 0.5).Runs[0]
+	assert.Equal(t, 7, run.Properties["pull_request"])
+
+	var foreign []SARIFResult
+	for _, res := range run.Results {
+		if res.RuleID == ruleForeignCommit {
+			foreign = append(foreign, res)
+		}
+	}
+	require.Len(t, foreign, 2)
+	assert.Equal(t, "young", foreign[0].PartialFingerprints["reputer/author"])
+	assert.Equal(t, "commit b1 in #7 opened by trusted was authored by young", foreign[0].Message.Text)
+	require.Len(t, foreign[0].Locations, 1)
+	assert.Equal(t, anchorFile, foreign[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, []string{"https://github.com/o/r/commit/b1"}, foreign[0].Properties["commits"])
+	assert.Equal(t, "Jane Doe", foreign[1].PartialFingerprints["reputer/author"])
+}
+
+func TestBuildSARIFEmpty(t *testing.T) {
+	log := BuildSARIF(nil, 0.5)
+	require.Len(t, log.Runs, 1)
+	assert.NotNil(t, log.Runs[0].Results)
+	assert.Len(t, log.Runs[0].Tool.Driver.Rules, 3+len(score.SignalNames()))
+}
+
+func TestSARIFEncodes(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, SARIF(&buf, testReport(), 0.5))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "2.1.0", decoded["version"])
+	assert.Contains(t, decoded, "$schema")
+}
+
+func TestCommitURLsLimit(t *testing.T) {
+	shas := make([]string, maxCommitURLs+5)
+	for i := range shas {
+		shas[i] = "sha"
+	}
+	assert.Len(t, commitURLs("github.com/o/r", shas), maxCommitURLs)
+	assert.Nil(t, commitURLs("", shas))
+}
+
+func TestSignalRuleName(t *testing.T) {
+	assert.Equal(t, "WeakPrAcceptance", signalRuleName(score.SignalPRAcceptance))
+	assert.Equal(t, "WeakAge", signalRuleName(score.SignalAge))
+}
+
+func TestLowReputationFinding(t *testing.T) {
+	a := &report.Author{Username: "u", Reputation: 0.3, Breakdown: &score.Result{
+		Signals: []score.SignalResult{
+			{Name: score.SignalAge, Weight: 0.15, Contribution: 0.1},
+			{Name: score.SignalRepoCount, Weight: 0.1, Contribution: 0, Note: "0 combined"},
+			{Name: score.SignalProportion, Weight: 0.15, Unavailable: true},
+		},
+	}}
+	id, msg := lowReputationFinding(a, 0.5)
+	assert.Equal(t, ruleSignalPrefix+score.SignalRepoCount, id)
+	assert.Equal(t, "u has reputation 0.30 (below 0.50); weakest signal: repo_count (0 combined)", msg)
+
+	a.Breakdown.Signals = a.Breakdown.Signals[2:]
+	id, _ = lowReputationFinding(a, 0.5)
+	assert.Equal(t, ruleLowReputation, id)
+}
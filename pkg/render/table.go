@@ -0,0 +1,230 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/report"
+)
+
+// listSeparator joins multi-value cells such as sensitive files.
+const listSeparator = ";"
+
+// authorColumns are the top-level author columns, in output order. Context
+// and stats columns follow in struct order, then policy, overrides, and the
+// repos of a combined report.
+var authorColumns = []string{"username", "reputation", "tier", "completeness", "confidence"}
+
+// Columns returns every column CSV and TSV output can contain, in their
+// default order. Names match the JSON field names of [report.Author],
+// [report.AuthorContext], and [report.Stats].
+func Columns() []string {
+	cols := append([]string{}, authorColumns...)
+	cols = append(cols, jsonNames(reflect.TypeOf(report.AuthorContext{}))...)
+	cols = append(cols, jsonNames(reflect.TypeOf(report.Stats{}))...)
+	return append(cols, "policy", "overrides", "repos")
+}
+
+// CheckColumns returns an error naming the first unknown column.
+func CheckColumns(cols []string) error {
+	known := make(map[string]bool)
+	for _, c := range Columns() {
+		known[c] = true
+	}
+	for _, c := range cols {
+		if !known[c] {
+			return fmt.Errorf("unknown column: %s", c)
+		}
+	}
+	return nil
+}
+
+// CSV writes one comma-separated row per author. See [Table].
+func CSV(w io.Writer, r *report.Report, cols []string) error {
+	return Table(w, r, ',', cols)
+}
+
+// TSV writes one tab-separated row per author. See [Table].
+func TSV(w io.Writer, r *report.Report, cols []string) error {
+	return Table(w, r, '\t', cols)
+}
+
+// Table writes the report as delimited rows, one per author, with the
+// given columns or all [Columns] when cols is empty. Report metadata is
+// written first as "# key: value" comment lines, followed by a header
+// row. Multi-value cells are joined with ";"; cells for stats that were
+// not collected are empty.
+func Table(w io.Writer, r *report.Report, comma rune, cols []string) error {
+	if len(cols) == 0 {
+		cols = Columns()
+	}
+	if err := CheckColumns(cols); err != nil {
+		return err
+	}
+
+	if r != nil {
+		for _, kv := range tableMeta(r) {
+			if _, err := fmt.Fprintf(w, "# %s: %s\n", kv[0], kv[1]); err != nil {
+				return fmt.Errorf("error writing metadata: %w", err)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(cols); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	if r != nil {
+		for _, a := range r.Contributors {
+			if a == nil {
+				continue
+			}
+			vals := authorRow(a)
+			rec := make([]string, len(cols))
+			for i, c := range cols {
+				rec[i] = vals[c]
+			}
+			if err := cw.Write(rec); err != nil {
+				return fmt.Errorf("error writing row for %s: %w", a.Username, err)
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error flushing rows: %w", err)
+	}
+
+	return nil
+}
+
+// tableMeta returns the report-level key/value pairs written before the header.
+func tableMeta(r *report.Report) [][2]string {
+	meta := [][2]string{{"repo", r.Repo}}
+	if len(r.Repos) > 0 {
+		meta = [][2]string{{"repos", strings.Join(r.Repos, listSeparator)}}
+	}
+	if r.AtCommit != "" {
+		meta = append(meta, [2]string{"at_commit", r.AtCommit})
+	}
+	if pr := r.PullRequest; pr != nil {
+		meta = append(meta,
+			[2]string{"pull_request", strconv.Itoa(pr.Number)},
+			[2]string{"pull_request_author", pr.Author},
+			[2]string{"foreign_commits", strconv.Itoa(pr.ForeignCommits)},
+		)
+	}
+	if r.AsOf != nil {
+		meta = append(meta, [2]string{"as_of", r.AsOf.UTC().Format(time.RFC3339)})
+	}
+	meta = append(meta,
+		[2]string{"generated_on", r.GeneratedOn.UTC().Format(time.RFC3339)},
+		[2]string{"total_commits", strconv.FormatInt(r.TotalCommits, 10)},
+		[2]string{"total_contributors", strconv.FormatInt(r.TotalContributors, 10)},
+	)
+	if r.Meta != nil {
+		meta = append(meta, [2]string{"model_version", r.Meta.ModelVersion})
+	}
+	return meta
+}
+
+// authorRow returns the author's cell values keyed by column.
+func authorRow(a *report.Author) map[string]string {
+	row := map[string]string{
+		"username":     a.Username,
+		"reputation":   cell(a.Reputation),
+		"tier":         a.Tier,
+		"completeness": cell(a.Completeness),
+		"confidence":   cell(a.Confidence),
+	}
+
+	if a.Context != nil {
+		addFields(row, reflect.ValueOf(*a.Context))
+	}
+	if a.Stats != nil {
+		addFields(row, reflect.ValueOf(*a.Stats))
+	}
+
+	rules := make([]string, 0, len(a.Policy))
+	for _, m := range a.Policy {
+		rules = append(rules, m.Rule)
+	}
+	row["policy"] = strings.Join(rules, listSeparator)
+
+	types := make([]string, 0, len(a.Overrides))
+	for _, o := range a.Overrides {
+		types = append(types, o.Type)
+	}
+	row["overrides"] = strings.Join(types, listSeparator)
+
+	repos := make([]string, 0, len(a.Repos))
+	for _, rc := range a.Repos {
+		repos = append(repos, fmt.Sprintf("%s=%d", rc.Repo, rc.Commits))
+	}
+	row["repos"] = strings.Join(repos, listSeparator)
+
+	return row
+}
+
+// addFields adds the struct's fields to row keyed by JSON name.
+func addFields(row map[string]string, v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		name := jsonName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		row[name] = cell(v.Field(i).Interface())
+	}
+}
+
+// jsonNames returns the JSON names of the struct's fields in order.
+func jsonNames(t reflect.Type) []string {
+	names := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		if name := jsonName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// jsonName returns the field's JSON name, or "" if it is not serialized.
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// cell formats a value for a delimited cell.
+func cell(v any) string {
+	switch val := v.(type) {
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case []string:
+		return strings.Join(val, listSeparator)
+	case map[string]string:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+"="+val[k])
+		}
+		return strings.Join(parts, listSeparator)
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumns(t *testing.T) {
+	cols := Columns()
+	assert.Equal(t, []string{"username", "reputation", "tier", "completeness", "confidence", "created"}, cols[:6])
+	assert.Contains(t, cols, "age_days")
+	assert.Contains(t, cols, "signal_status")
+	assert.Equal(t, []string{"policy", "overrides", "repos"}, cols[len(cols)-3:])
+
+	seen := make(map[string]bool)
+	for _, c := range cols {
+		assert.False(t, seen[c], "duplicate column %s", c)
+		seen[c] = true
+	}
+
+	require.NoError(t, CheckColumns([]string{"username", "age_days"}))
+	assert.ErrorContains(t, CheckColumns([]string{"username", "nope"}), "unknown column: nope")
+}
+
+func TestCSV(t *testing.T) {
+	r := templateReport()
+	r.AssignTiers(nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, CSV(&buf, r, nil))
+
+	cr := csv.NewReader(strings.NewReader(buf.String()))
+	cr.Comment = '#'
+	recs, err := cr.ReadAll()
+	require.NoError(t, err)
+	require.Len(t, recs, 6)
+	assert.Equal(t, Columns(), recs[0])
+
+	idx := make(map[string]int)
+	for i, c := range recs[0] {
+		idx[c] = i
+	}
+	trusted := recs[1]
+	assert.Equal(t, "trusted", trusted[idx["username"]])
+	assert.Equal(t, "0.9", trusted[idx["reputation"]])
+	assert.Equal(t, "high", trusted[idx["tier"]])
+	assert.Equal(t, "900", trusted[idx["age_days"]])
+	assert.Equal(t, "go.mod;Makefile", trusted[idx["sensitive_files"]])
+	assert.Equal(t, "burst=missing", trusted[idx["signal_status"]])
+
+	// Stats were not collected for this author.
+	assert.Empty(t, recs[4][idx["age_days"]])
+	assert.Equal(t, "touches-ci;maintainer-floor", recs[5][idx["policy"]])
+	assert.Equal(t, "deny", recs[5][idx["overrides"]])
+
+	assert.True(t, strings.HasPrefix(buf.String(), "# repo: github.com/o/r\n# at_commit: abc123\n"))
+	assert.Contains(t, buf.String(), "# total_commits: 12\n")
+}
+
+func TestTSVColumns(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, TSV(&buf, templateReport(), []string{"username", "commits"}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var rows []string
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "#") {
+			rows = append(rows, l)
+		}
+	}
+	assert.Equal(t, []string{"username\tcommits", "trusted\t10", "young\t2", "gone\t", "unexplained\t", "flagged\t"}, rows)
+
+	assert.Error(t, TSV(&buf, templateReport(), []string{"bogus"}))
+}
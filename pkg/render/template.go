@@ -0,0 +1,207 @@
+package render
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/report"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+const (
+	markdownTemplate = "templates/markdown.tmpl"
+	htmlTemplate     = "templates/html.tmpl"
+)
+
+// Tier badges, from the highest tier to the lowest.
+const (
+	badgeTop    = "🟢"
+	badgeMiddle = "🟡"
+	badgeBottom = "🔴"
+)
+
+// Level returns the position of the named tier: "high" for the highest
+// tier, "low" for the lowest, and "medium" for any in between. Empty tiers
+// use [report.DefaultTiers].
+func Level(tiers []report.Tier, name string) string {
+	if len(tiers) == 0 {
+		tiers = report.DefaultTiers
+	}
+
+	switch name {
+	case tiers[0].Name:
+		return "high"
+	case tiers[len(tiers)-1].Name:
+		return "low"
+	default:
+		return "medium"
+	}
+}
+
+// Badge returns the emoji for the tier the reputation falls in: green for
+// the highest tier, red for the lowest, and yellow for any in between.
+func Badge(tiers []report.Tier, reputation float64) string {
+	switch Level(tiers, report.TierFor(tiers, reputation)) {
+	case "high":
+		return badgeTop
+	case "low":
+		return badgeBottom
+	default:
+		return badgeMiddle
+	}
+}
+
+// Stat is a single named author statistic formatted for display.
+type Stat struct {
+	Name  string
+	Value string
+}
+
+// Markdown writes the report using the given text/template source, or the
+// built-in Markdown template when tmpl is empty.
+func Markdown(w io.Writer, r *report.Report, tmpl string) error {
+	if tmpl == "" {
+		b, err := templates.ReadFile(markdownTemplate)
+		if err != nil {
+			return fmt.Errorf("error reading built-in template: %w", err)
+		}
+		tmpl = string(b)
+	}
+
+	t, err := texttemplate.New("markdown").Funcs(texttemplate.FuncMap(funcs(r))).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("error parsing template: %w", err)
+	}
+
+	if err := t.Execute(w, r); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+
+	return nil
+}
+
+// HTML writes the report using the given html/template source, or the
+// built-in HTML template when tmpl is empty. Values are escaped for their
+// HTML context.
+func HTML(w io.Writer, r *report.Report, tmpl string) error {
+	if tmpl == "" {
+		b, err := templates.ReadFile(htmlTemplate)
+		if err != nil {
+			return fmt.Errorf("error reading built-in template: %w", err)
+		}
+		tmpl = string(b)
+	}
+
+	t, err := htmltemplate.New("html").Funcs(htmltemplate.FuncMap(funcs(r))).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("error parsing template: %w", err)
+	}
+
+	if err := t.Execute(w, r); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+
+	return nil
+}
+
+// funcs returns the functions available to report templates. Tiers come
+// from the report, or [report.DefaultTiers] when it has none.
+func funcs(r *report.Report) map[string]any {
+	var tiers []report.Tier
+	if r != nil {
+		tiers = r.Tiers
+	}
+
+	return map[string]any{
+		"tier":    func(v float64) string { return report.TierFor(tiers, v) },
+		"level":   func(v float64) string { return Level(tiers, report.TierFor(tiers, v)) },
+		"badge":   func(v float64) string { return Badge(tiers, v) },
+		"score":   func(v float64) string { return fmt.Sprintf("%.2f", v) },
+		"percent": func(v float64) string { return fmt.Sprintf("%.1f%%", v*100) },
+		"date":    func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") },
+		"profile": profileURL,
+		"stats":   statList,
+		"flags":   flags,
+	}
+}
+
+// profileURL returns the author's profile URL on the repo's host.
+func profileURL(repo, username string) string {
+	host, _, _ := strings.Cut(repo, "/")
+	return fmt.Sprintf("https://%s/%s", host, username)
+}
+
+// statList returns the non-zero stats sorted by name.
+func statList(s *report.Stats) []Stat {
+	if s == nil {
+		return nil
+	}
+
+	var list []Stat
+	for name, v := range s.Values() {
+		rv := reflect.ValueOf(v)
+		if rv.IsZero() || (rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice) && rv.Len() == 0 {
+			continue
+		}
+		list = append(list, Stat{Name: name, Value: formatValue(v)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// formatValue renders a stat value on a single line.
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case []string:
+		return strings.Join(val, ", ")
+	case map[string]string:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+": "+val[k])
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// flags lists the notable conditions for an author: suspension,
+// reactivation, policy rules, and trust overrides.
+func flags(a *report.Author) []string {
+	if a == nil {
+		return nil
+	}
+
+	var list []string
+	if a.Stats != nil {
+		if a.Stats.Suspended {
+			list = append(list, "suspended")
+		}
+		if a.Stats.Reactivated {
+			list = append(list, "reactivated")
+		}
+	}
+	for _, m := range a.Policy {
+		list = append(list, "policy:"+m.Rule)
+	}
+	for _, o := range a.Overrides {
+		list = append(list, o.Type)
+	}
+
+	return list
+}
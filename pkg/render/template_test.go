@@ -0,0 +1,138 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func templateReport() *report.Report {
+	r := testReport()
+	r.TotalCommits = 12
+	r.TotalContributors = 5
+	r.Contributors[0].Stats = &report.Stats{
+		Commits:        10,
+		AgeDays:        900,
+		SensitiveFiles: []string{"go.mod", "Makefile"},
+		SignalStatus:   map[string]string{"burst": report.SignalMissing},
+	}
+	r.Contributors[1].Stats = &report.Stats{Commits: 2, Reactivated: true}
+	r.Summary = report.Summarize(r.Contributors, report.DefaultRiskThreshold)
+	return r
+}
+
+func TestBadge(t *testing.T) {
+	assert.Equal(t, "🟢", Badge(nil, 0.7))
+	assert.Equal(t, "🟡", Badge(nil, 0.5))
+	assert.Equal(t, "🔴", Badge(nil, 0.1))
+
+	tiers := []report.Tier{{Name: "a", Min: 0.9}, {Name: "b", Min: 0.6}, {Name: "c", Min: 0.3}, {Name: "d", Min: 0}}
+	assert.Equal(t, "🟢", Badge(tiers, 0.95))
+	assert.Equal(t, "🟡", Badge(tiers, 0.65))
+	assert.Equal(t, "🟡", Badge(tiers, 0.35))
+	assert.Equal(t, "🔴", Badge(tiers, 0.1))
+	assert.Equal(t, "medium", Level(tiers, "c"))
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, templateReport(), ""))
+	out := buf.String()
+
+	assert.Contains(t, out, "## Contributor reputation for `github.com/o/r`")
+	assert.Contains(t, out, "Commit `abc123`")
+	assert.Contains(t, out, "| Contributors | 5 |")
+	assert.Contains(t, out, "| Bus factor (50% / 80%) | 1 / 1 |")
+	assert.Contains(t, out, "| 🟢 | [@trusted](https://github.com/trusted) | 0.90 | 10 |  |")
+	assert.Contains(t, out, "| 🟡 | [@unexplained](https://github.com/unexplained) | 0.40 | - |  |")
+	assert.Contains(t, out, "`policy:touches-ci`, `policy:maintainer-floor`, `deny`")
+	assert.Contains(t, out, "<summary>🟢 @trusted (0.90)</summary>")
+	assert.Contains(t, out, "| `sensitive_files` | go.mod, Makefile |")
+	assert.Contains(t, out, "| `signal_status` | burst: missing |")
+	assert.NotContains(t, out, "`followers`")
+	assert.NotContains(t, out, "@gone (")
+}
+
+func TestMarkdownCombined(t *testing.T) {
+	r := templateReport()
+	r.Repo = ""
+	r.AtCommit = ""
+	r.Repos = []string{"github.com/o/c", "gitlab.com/g/a", "gitlab.com/g/b"}
+	r.Contributors[0].Host = "gitlab.com"
+	r.Contributors[0].Repos = []report.RepoCommits{{Repo: "gitlab.com/g/a", Commits: 10, Reputation: 0.85}}
+
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, r, ""))
+	out := buf.String()
+
+	assert.Contains(t, out, "## Contributor reputation for 3 repositories")
+	assert.Contains(t, out, "Repositories: `github.com/o/c`, `gitlab.com/g/a`, `gitlab.com/g/b`")
+	assert.Contains(t, out, "[@trusted](https://gitlab.com/trusted)", "profile links use the author's host")
+	assert.Contains(t, out, "| `gitlab.com/g/a` | 10 commits, 0.85 in repo |")
+}
+
+func TestMarkdownPullRequest(t *testing.T) {
+	r := templateReport()
+	r.AtCommit = ""
+	r.PullRequest = &report.PullRequest{
+		Number: 42,
+		Author: "trusted",
+		Commits: []report.PullRequestCommit{
+			{SHA: "c1", Author: "trusted"},
+			{SHA: "c2", Author: "unexplained", Foreign: true},
+			{SHA: "c3", AuthorName: "Jane Doe", Foreign: true},
+		},
+		ForeignCommits: 2,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, r, ""))
+	out := buf.String()
+
+	assert.Contains(t, out, "## Contributor reputation for `github.com/o/r#42`")
+	assert.Contains(t, out, "Pull request #42 opened by [@trusted](https://github.com/trusted) · 3 commits")
+	assert.Contains(t, out, "> **2 commits not authored by @trusted:**")
+	assert.Contains(t, out, "> - `c2` by @unexplained")
+	assert.Contains(t, out, "> - `c3` by Jane Doe (no account)")
+	assert.NotContains(t, out, "`c1`")
+
+	buf.Reset()
+	require.NoError(t, HTML(&buf, r, ""))
+	assert.Contains(t, buf.String(), "<li><code>c3</code> by Jane Doe (no account)</li>")
+}
+
+func TestHTML(t *testing.T) {
+	r := templateReport()
+	r.Contributors[3].Username = "<script>"
+
+	var buf bytes.Buffer
+	require.NoError(t, HTML(&buf, r, ""))
+	out := buf.String()
+
+	assert.Contains(t, out, "<!DOCTYPE html>")
+	assert.Contains(t, out, `<span class="badge tier-high" title="high">0.90</span>`)
+	assert.Contains(t, out, "<details>")
+	assert.Contains(t, out, "<tr><th>sensitive_files</th><td>go.mod, Makefile</td></tr>")
+	assert.Contains(t, out, "@&lt;script&gt;")
+	assert.NotContains(t, out, "@<script>")
+}
+
+func TestCustomTemplate(t *testing.T) {
+	tmpl := `{{ range .Contributors }}{{ .Username }}={{ tier .Reputation }};{{ end }}`
+
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, testReport(), tmpl))
+	assert.Equal(t, "trusted=high;young=low;gone=low;unexplained=medium;flagged=high;", buf.String())
+
+	r := testReport()
+	r.Tiers = []report.Tier{{Name: "ok", Min: 0.5}, {Name: "review", Min: 0}}
+	buf.Reset()
+	require.NoError(t, Markdown(&buf, r, tmpl))
+	assert.Equal(t, "trusted=ok;young=review;gone=review;unexplained=review;flagged=ok;", buf.String())
+
+	assert.Error(t, Markdown(&buf, testReport(), "{{ .Missing"))
+	assert.Error(t, HTML(&buf, testReport(), "{{ .NoSuchField }}"))
+}
@@ -2,8 +2,39 @@ package report
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mchmarny/reputer/pkg/policy"
+	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/mchmarny/reputer/pkg/trust"
+)
+
+// Signal collection statuses recorded in [Stats.SignalStatus].
+const (
+	// SignalCollected means the signal's inputs were fetched successfully.
+	SignalCollected = "collected"
+	// SignalMissing means the source had no data for the author (e.g. not found).
+	SignalMissing = "missing"
+	// SignalErrored means fetching the signal's inputs failed.
+	SignalErrored = "errored"
+	// SignalNotApplicable means the query has no inputs for the signal,
+	// e.g. repo-relative signals in a user lookup without a repo.
+	SignalNotApplicable = "not_applicable"
 )
 
+// SignalSensitivePaths is the [Stats.SignalStatus] key of the sensitive-path
+// profile. It is not a scoring signal, so it does not affect completeness
+// or the score.
+const SignalSensitivePaths = "sensitive_paths"
+
+// SignalReactivation is the [Stats.SignalStatus] key of the prior-activity
+// lookup behind dormant-account reactivation. It is not a scoring signal;
+// when it is not collected, reactivation is not evaluated.
+const SignalReactivation = "reactivation"
+
 // MakeAuthor creates a new Author instance.
 func MakeAuthor(username string) *Author {
 	return &Author{
@@ -15,10 +46,37 @@ func MakeAuthor(username string) *Author {
 
 // Author represents a commit author.
 type Author struct {
-	Username   string         `json:"username" yaml:"username"`
-	Reputation float64        `json:"reputation" yaml:"reputation"`
-	Context    *AuthorContext `json:"context,omitempty" yaml:"context,omitempty"`
-	Stats      *Stats         `json:"stats,omitempty" yaml:"stats,omitempty"`
+	Username     string           `json:"username" yaml:"username"`
+	Host         string           `json:"host,omitempty" yaml:"host,omitempty"`
+	Reputation   float64          `json:"reputation" yaml:"reputation" jsonschema:"minimum=0,maximum=1"`
+	Tier         string           `json:"tier,omitempty" yaml:"tier,omitempty"`
+	Completeness float64          `json:"completeness,omitempty" yaml:"completeness,omitempty" jsonschema:"minimum=0,maximum=1"`
+	Confidence   float64          `json:"confidence,omitempty" yaml:"confidence,omitempty" jsonschema:"minimum=0,maximum=1"`
+	Context      *AuthorContext   `json:"context,omitempty" yaml:"context,omitempty"`
+	Stats        *Stats           `json:"stats,omitempty" yaml:"stats,omitempty"`
+	Breakdown    *Breakdown       `json:"breakdown,omitempty" yaml:"breakdown,omitempty"`
+	Comparison   []ModelScore     `json:"comparison,omitempty" yaml:"comparison,omitempty"`
+	Policy       []policy.Match   `json:"policy,omitempty" yaml:"policy,omitempty"`
+	Overrides    []trust.Override `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Repos        []RepoCommits    `json:"repos,omitempty" yaml:"repos,omitempty"`
+
+	// Commits lists the SHAs of the author's commits in range, newest first.
+	// Used by renderers that link to commits; not serialized.
+	Commits []string `json:"-" yaml:"-"`
+}
+
+// ModelScore is an author's reputation under a comparison model.
+type ModelScore struct {
+	ModelName    string  `json:"model_name" yaml:"modelName"`
+	ModelVersion string  `json:"model_version" yaml:"modelVersion"`
+	Reputation   float64 `json:"reputation" yaml:"reputation" jsonschema:"minimum=0,maximum=1"`
+}
+
+// RepoCommits is an author's contribution to one repo of a combined report.
+type RepoCommits struct {
+	Repo       string  `json:"repo" yaml:"repo"`
+	Commits    int64   `json:"commits" yaml:"commits"`
+	Reputation float64 `json:"reputation" yaml:"reputation" jsonschema:"minimum=0,maximum=1"`
 }
 
 func (a *Author) String() string {
@@ -60,4 +118,141 @@ type Stats struct {
 	RecentPRRepoCount int64  `json:"recent_pr_repo_count,omitempty" yaml:"recentPRRepoCount,omitempty"`
 	ForkedRepos       int64  `json:"forked_repos,omitempty" yaml:"forkedRepos,omitempty"`
 	TrustedOrgMember  bool   `json:"trusted_org_member,omitempty" yaml:"trustedOrgMember,omitempty"`
+	TrustedDomain     string `json:"trusted_domain,omitempty" yaml:"trustedDomain,omitempty"`
+
+	// Reactivation fields
+	Reactivated     bool   `json:"reactivated,omitempty" yaml:"reactivated,omitempty"`
+	DormantDays     int64  `json:"dormant_days,omitempty" yaml:"dormantDays,omitempty"`
+	PrevActivity    string `json:"prev_activity,omitempty" yaml:"prevActivity,omitempty"`
+	FirstRepoCommit string `json:"first_repo_commit,omitempty" yaml:"firstRepoCommit,omitempty"`
+
+	// Sensitive-path fields. ProfiledCommits is how many of the author's
+	// commits were profiled, which is fewer than Commits for prolific
+	// authors.
+	SensitiveCommits int64    `json:"sensitive_commits,omitempty" yaml:"sensitiveCommits,omitempty"`
+	SensitiveFiles   []string `json:"sensitive_files,omitempty" yaml:"sensitiveFiles,omitempty"`
+	ProfiledCommits  int64    `json:"profiled_commits,omitempty" yaml:"profiledCommits,omitempty"`
+
+	// SignalStatus records how each scoring signal's inputs, and the
+	// sensitive-path profile, were collected, keyed by signal name. Signals
+	// without an entry are collected.
+	SignalStatus map[string]string `json:"signal_status,omitempty" yaml:"signalStatus,omitempty"`
+}
+
+// Signals maps the author stats and repo-wide totals to scoring model inputs.
+func (s *Stats) Signals(totalCommits int64, totalContributors int) score.Signals {
+	if s == nil {
+		return score.Signals{TotalCommits: totalCommits, TotalContributors: totalContributors}
+	}
+
+	return score.Signals{
+		Suspended:         s.Suspended,
+		Commits:           s.Commits,
+		UnverifiedCommits: s.UnverifiedCommits,
+		TotalCommits:      totalCommits,
+		TotalContributors: totalContributors,
+		AgeDays:           s.AgeDays,
+		OrgMember:         s.OrgMember,
+		LastCommitDays:    s.LastCommitDays,
+		Followers:         s.Followers,
+		Following:         s.Following,
+		PublicRepos:       s.PublicRepos,
+		AuthorAssociation: s.AuthorAssociation,
+		HasBio:            s.HasBio,
+		HasCompany:        s.HasCompany,
+		HasLocation:       s.HasLocation,
+		HasWebsite:        s.HasWebsite,
+		PRsMerged:         s.PRsMerged,
+		PRsClosed:         s.PRsClosed,
+		RecentPRRepoCount: s.RecentPRRepoCount,
+		ForkedRepos:       s.ForkedRepos,
+		TrustedOrgMember:  s.TrustedOrgMember || s.TrustedDomain != "",
+		Reactivated:       s.Reactivated,
+		Unavailable:       s.Unavailable(),
+	}
+}
+
+// Unavailable returns the sorted names of scoring signals that were not
+// collected.
+func (s *Stats) Unavailable() []string {
+	if s == nil {
+		return nil
+	}
+
+	names := score.SignalNames()
+	var list []string
+	for name, status := range s.SignalStatus {
+		if status != SignalCollected && slices.Contains(names, name) {
+			list = append(list, name)
+		}
+	}
+	sort.Strings(list)
+
+	return list
+}
+
+// Completeness returns the share of scoring signals that were collected.
+func (s *Stats) Completeness() float64 {
+	names := score.SignalNames()
+	missing := len(s.Unavailable())
+	return ToFixed(float64(len(names)-missing)/float64(len(names)), 4)
+}
+
+// Values returns the stats keyed by their JSON field names, including
+// zero values.
+func (s *Stats) Values() map[string]any {
+	vals := make(map[string]any)
+	if s == nil {
+		s = &Stats{}
+	}
+
+	v := reflect.ValueOf(*s)
+	t := v.Type()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		vals[name] = v.Field(i).Interface()
+	}
+
+	return vals
+}
+
+// Vars returns the policy evaluation environment for the author. Policies
+// evaluated with it must be compiled with stats bound to [Stats].
+func (a *Author) Vars(repo string, totalCommits int64, totalContributors int) map[string]any {
+	vars := map[string]any{
+		"repo":               repo,
+		"total_commits":      totalCommits,
+		"total_contributors": int64(totalContributors),
+		"username":           "",
+		"reputation":         0.0,
+		"completeness":       0.0,
+		"confidence":         0.0,
+		"stats":              &Stats{},
+	}
+
+	ctx := map[string]string{"created": "", "name": "", "email": "", "company": ""}
+	vars["context"] = ctx
+	if a == nil {
+		return vars
+	}
+
+	vars["username"] = a.Username
+	vars["reputation"] = a.Reputation
+	vars["completeness"] = a.Completeness
+	vars["confidence"] = a.Confidence
+	if a.Stats != nil {
+		vars["stats"] = a.Stats
+	}
+
+	if a.Context != nil {
+		ctx["created"] = a.Context.Created
+		ctx["name"] = a.Context.Name
+		ctx["email"] = a.Context.Email
+		ctx["company"] = a.Context.Company
+	}
+
+	return vars
 }
@@ -1,8 +1,11 @@
 package report
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/mchmarny/reputer/pkg/policy"
+	"github.com/mchmarny/reputer/pkg/score"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -38,3 +41,110 @@ func TestMakeAuthorHasV3StatsFields(t *testing.T) {
 	assert.Zero(t, a.Stats.RecentPRRepoCount)
 	assert.Zero(t, a.Stats.ForkedRepos)
 }
+
+func TestStatsSignals(t *testing.T) {
+	s := &Stats{
+		Commits:           10,
+		UnverifiedCommits: 2,
+		AgeDays:           365,
+		AuthorAssociation: "MEMBER",
+		PRsMerged:         3,
+		Reactivated:       true,
+	}
+	sig := s.Signals(100, 5)
+	assert.Equal(t, int64(10), sig.Commits)
+	assert.Equal(t, int64(2), sig.UnverifiedCommits)
+	assert.Equal(t, int64(100), sig.TotalCommits)
+	assert.Equal(t, 5, sig.TotalContributors)
+	assert.Equal(t, int64(365), sig.AgeDays)
+	assert.Equal(t, "MEMBER", sig.AuthorAssociation)
+	assert.Equal(t, int64(3), sig.PRsMerged)
+	assert.True(t, sig.Reactivated)
+
+	sig = (&Stats{TrustedDomain: "example.com"}).Signals(100, 5)
+	assert.True(t, sig.TrustedOrgMember)
+
+	var n *Stats
+	sig = n.Signals(100, 5)
+	assert.Equal(t, int64(100), sig.TotalCommits)
+	assert.Zero(t, sig.Commits)
+}
+
+func TestStatsUnavailable(t *testing.T) {
+	s := &Stats{SignalStatus: map[string]string{
+		score.SignalPRAcceptance: SignalErrored,
+		score.SignalBurst:        SignalCollected,
+		score.SignalAssociation:  SignalMissing,
+		SignalSensitivePaths:     SignalErrored,
+	}}
+	assert.Equal(t, []string{score.SignalAssociation, score.SignalPRAcceptance}, s.Unavailable(),
+		"the sensitive-path profile is not a scoring signal")
+	assert.Equal(t, s.Unavailable(), s.Signals(10, 1).Unavailable)
+	assert.InDelta(t, 9.0/11.0, s.Completeness(), 0.0001)
+
+	var n *Stats
+	assert.Empty(t, n.Unavailable())
+	assert.InDelta(t, 1.0, n.Completeness(), 0.0001)
+	assert.InDelta(t, 1.0, (&Stats{}).Completeness(), 0.0001)
+}
+
+func TestStatsValues(t *testing.T) {
+	s := &Stats{Commits: 3, AuthorAssociation: "OWNER", SensitiveFiles: []string{"go.mod"}}
+	v := s.Values()
+	assert.Equal(t, int64(3), v["commits"])
+	assert.Equal(t, "OWNER", v["author_association"])
+	assert.Equal(t, []string{"go.mod"}, v["sensitive_files"])
+	// Zero values are present even though they are omitted from JSON output.
+	assert.Contains(t, v, "suspended")
+	assert.Contains(t, v, "prs_merged")
+
+	var n *Stats
+	assert.Contains(t, n.Values(), "age_days")
+}
+
+func TestAuthorVars(t *testing.T) {
+	a := MakeAuthor("octocat")
+	a.Reputation = 0.5
+	a.Context.Company = "GitHub"
+	v := a.Vars("github.com/o/r", 100, 5)
+	assert.Equal(t, "octocat", v["username"])
+	assert.InDelta(t, 0.5, v["reputation"], 0.0001)
+	assert.Equal(t, "github.com/o/r", v["repo"])
+	assert.Equal(t, int64(100), v["total_commits"])
+	assert.Equal(t, int64(5), v["total_contributors"])
+	assert.Equal(t, "GitHub", v["context"].(map[string]string)["company"])
+	assert.Same(t, a.Stats, v["stats"])
+
+	var n *Author
+	nv := n.Vars("r", 1, 1)
+	assert.Equal(t, "", nv["username"], "every declared variable is set")
+	assert.NotNil(t, nv["stats"])
+}
+
+func TestAuthorVarsPolicy(t *testing.T) {
+	p := &policy.Policy{Rules: []*policy.Rule{{
+		Name:   "unverified-workflows",
+		When:   "stats.unverified_commits == stats.commits && stats.sensitive_files.exists(f, f.startsWith('.github/')) && stats.signal_status['burst'] == 'errored' && context.company == 'GitHub'",
+		Action: policy.ActionCap,
+		Value:  0.1,
+	}}}
+	require.NoError(t, p.Compile(reflect.TypeFor[Stats]()))
+
+	a := MakeAuthor("octocat")
+	a.Context.Company = "GitHub"
+	a.Stats.Commits, a.Stats.UnverifiedCommits = 3, 3
+	a.Stats.SensitiveFiles = []string{".github/workflows/release.yaml"}
+	a.Stats.SignalStatus = map[string]string{score.SignalBurst: SignalErrored}
+
+	rep, matches, err := p.Evaluate(a.Vars("github.com/o/r", 10, 2), 0.8)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1, rep, 0)
+	assert.Len(t, matches, 1)
+
+	_, matches, err = p.Evaluate((*Author)(nil).Vars("", 0, 0), 0.8)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	bad := &policy.Policy{Rules: []*policy.Rule{{Name: "typo", When: "stats.age_dyas < 14", Action: policy.ActionFlag}}}
+	require.ErrorContains(t, bad.Compile(reflect.TypeFor[Stats]()), "undefined field")
+}
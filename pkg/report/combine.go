@@ -0,0 +1,260 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/policy"
+	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/mchmarny/reputer/pkg/trust"
+)
+
+// CombineOptions configures how per-repo reports are combined.
+type CombineOptions struct {
+	// Model scores the combined authors (optional, defaults to the built-in model).
+	Model *score.Model
+	// Policy is evaluated against each combined author (optional).
+	Policy *policy.Policy
+	// Trust is applied to each combined author after the policy (optional).
+	Trust *trust.Config
+	// RiskThreshold is the summary's low-reputation threshold
+	// (optional, defaults to DefaultRiskThreshold).
+	RiskThreshold *float64
+}
+
+// associationRank orders GitHub author associations from least to most
+// trusted, so the combined author keeps their strongest association.
+var associationRank = map[string]int{
+	"NONE":                   1,
+	"FIRST_TIMER":            2,
+	"FIRST_TIME_CONTRIBUTOR": 2,
+	"CONTRIBUTOR":            3,
+	"COLLABORATOR":           4,
+	"MEMBER":                 5,
+	"OWNER":                  5,
+}
+
+// statusRank orders signal collection statuses from best to worst.
+var statusRank = map[string]int{
+	SignalCollected:     0,
+	SignalMissing:       1,
+	SignalNotApplicable: 1,
+	SignalErrored:       2,
+}
+
+// Combine merges reports of several repos into one org-wide report with
+// each author, identified by host and login, once. Per-repo commits are summed, account-level stats are
+// taken from the most recently generated report, and every author is
+// rescored against the combined commit and contributor totals. Authors
+// must carry their stats. Authors denied in any input report stay denied.
+func Combine(reports []*Report, opt CombineOptions) (*Report, error) {
+	if len(reports) == 0 {
+		return nil, errors.New("no reports to combine")
+	}
+
+	// Account-level stats from later reports replace earlier ones.
+	sorted := make([]*Report, 0, len(reports))
+	seen := make(map[string]bool)
+	for _, r := range reports {
+		if r == nil {
+			continue
+		}
+		if r.Repo == "" {
+			return nil, errors.New("combined reports must each cover a single repo")
+		}
+		if seen[r.Repo] {
+			return nil, fmt.Errorf("duplicate report for %s", r.Repo)
+		}
+		seen[r.Repo] = true
+		sorted = append(sorted, r)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GeneratedOn.Before(sorted[j].GeneratedOn)
+	})
+
+	out := &Report{
+		SchemaVersion: SchemaVersion,
+		GeneratedOn:   time.Now().UTC(),
+	}
+
+	byUser := make(map[string]*Author)
+	denied := make(map[*Author]bool)
+	for _, r := range sorted {
+		out.Repos = append(out.Repos, r.Repo)
+		out.TotalCommits += r.TotalCommits
+
+		for _, a := range r.Contributors {
+			if a == nil {
+				continue
+			}
+			if a.Stats == nil {
+				return nil, fmt.Errorf("report for %s has no stats for %s; generate it with stats", r.Repo, a.Username)
+			}
+
+			// Logins are only unique per host, so the same login on
+			// another host is another account.
+			host := r.Host()
+			key := host + "/" + strings.ToLower(a.Username)
+			c, ok := byUser[key]
+			if !ok {
+				c = &Author{Username: a.Username, Host: host, Stats: &Stats{}}
+				byUser[key] = c
+			}
+			mergeAuthor(c, a)
+			c.Repos = append(c.Repos, RepoCommits{Repo: r.Repo, Commits: a.Stats.Commits, Reputation: a.Reputation})
+
+			for _, o := range a.Overrides {
+				if o.Type == trust.OverrideDeny && !denied[c] {
+					c.Overrides = append(c.Overrides, o)
+					denied[c] = true
+				}
+			}
+		}
+	}
+	sort.Strings(out.Repos)
+
+	m := opt.Model
+	if m == nil {
+		m = score.DefaultModel()
+	}
+	out.Meta = MakeMeta(m)
+
+	threshold := DefaultRiskThreshold
+	if opt.RiskThreshold != nil {
+		threshold = *opt.RiskThreshold
+	}
+
+	total := out.TotalCommits
+	contributors := len(byUser)
+	out.TotalContributors = int64(contributors)
+
+	for _, c := range byUser {
+		sort.Slice(c.Repos, func(i, j int) bool {
+			if c.Repos[i].Commits != c.Repos[j].Commits {
+				return c.Repos[i].Commits > c.Repos[j].Commits
+			}
+			return c.Repos[i].Repo < c.Repos[j].Repo
+		})
+
+		res := m.Compute(c.Stats.Signals(total, contributors))
+		c.Reputation = res.Score
+		c.Confidence = res.Confidence
+		c.Breakdown = &res
+		c.Completeness = c.Stats.Completeness()
+
+		if opt.Policy != nil {
+			rep, matches, err := opt.Policy.Evaluate(c.Vars("", total, contributors), c.Reputation)
+			if err != nil {
+				return nil, fmt.Errorf("error evaluating policy for %s: %w", c.Username, err)
+			}
+			c.Reputation = rep
+			c.Policy = matches
+		}
+
+		if rep, o := opt.Trust.Apply(c.Username, c.Reputation); o != nil {
+			c.Reputation = rep
+			c.Overrides = append(c.Overrides, *o)
+		}
+
+		out.Contributors = append(out.Contributors, c)
+	}
+
+	// A denial in any repo is an explicit decision about the account, so it
+	// outranks the combined score and allowlist entries.
+	for c := range denied {
+		c.Reputation = 0
+	}
+
+	out.Summary = Summarize(out.Contributors, threshold)
+	out.SortAuthors()
+
+	return out, nil
+}
+
+// mergeAuthor folds one repo's author into the combined author. Repo-level
+// counts are summed; account-level stats and context are replaced, since
+// reports are merged oldest first.
+func mergeAuthor(c, a *Author) {
+	if a.Context != nil {
+		ctx := *a.Context
+		c.Context = &ctx
+	}
+
+	prev := *c.Stats
+	s := *a.Stats
+
+	s.Commits += prev.Commits
+	s.UnverifiedCommits += prev.UnverifiedCommits
+	s.CommitsVerified = s.UnverifiedCommits == 0
+	s.SensitiveCommits += prev.SensitiveCommits
+	s.ProfiledCommits += prev.ProfiledCommits
+	s.SensitiveFiles = mergeSorted(prev.SensitiveFiles, s.SensitiveFiles)
+
+	if prev.Commits > 0 && prev.LastCommitDays < s.LastCommitDays {
+		s.LastCommitDays = prev.LastCommitDays
+	}
+	if associationRank[prev.AuthorAssociation] > associationRank[s.AuthorAssociation] {
+		s.AuthorAssociation = prev.AuthorAssociation
+	}
+	s.OrgMember = s.OrgMember || prev.OrgMember
+	s.TrustedOrgMember = s.TrustedOrgMember || prev.TrustedOrgMember
+	if s.TrustedDomain == "" {
+		s.TrustedDomain = prev.TrustedDomain
+	}
+
+	// A reactivation flagged in any repo is kept.
+	if prev.Reactivated && !s.Reactivated {
+		s.Reactivated = true
+		s.DormantDays = prev.DormantDays
+		s.PrevActivity = prev.PrevActivity
+		s.FirstRepoCommit = prev.FirstRepoCommit
+	}
+
+	s.SignalStatus = mergeStatus(prev.SignalStatus, s.SignalStatus)
+
+	c.Stats = &s
+}
+
+// mergeStatus keeps the worst collection status of each signal.
+func mergeStatus(a, b map[string]string) map[string]string {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, len(a)+len(b))
+	for _, src := range []map[string]string{a, b} {
+		for k, v := range src {
+			if cur, ok := m[k]; !ok || statusRank[v] > statusRank[cur] {
+				m[k] = v
+			}
+		}
+	}
+
+	return m
+}
+
+// mergeSorted returns the sorted union of two string lists.
+func mergeSorted(a, b []string) []string {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+
+	set := make(map[string]struct{}, len(a)+len(b))
+	for _, v := range a {
+		set[v] = struct{}{}
+	}
+	for _, v := range b {
+		set[v] = struct{}{}
+	}
+
+	list := make([]string, 0, len(set))
+	for v := range set {
+		list = append(list, v)
+	}
+	sort.Strings(list)
+
+	return list
+}
@@ -0,0 +1,170 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/policy"
+	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/mchmarny/reputer/pkg/trust"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func combineAuthor(name string, rep float64, commits, unverified int64, assoc string) *Author {
+	a := summaryAuthor(name, rep, commits, unverified, assoc)
+	a.Stats.AgeDays = 1000
+	a.Stats.Followers = 10
+	return a
+}
+
+func combineReports() []*Report {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	alice := combineAuthor("alice", 0.8, 30, 0, "MEMBER")
+	alice.Stats.LastCommitDays = 20
+	alice.Stats.SensitiveFiles = []string{"Makefile"}
+
+	eve := combineAuthor("eve", 0.2, 2, 2, "NONE")
+	eve.Overrides = []trust.Override{{Type: trust.OverrideDeny, Subject: "eve", Reason: "compromised"}}
+
+	a := &Report{
+		Repo:         "github.com/o/a",
+		GeneratedOn:  day,
+		TotalCommits: 32,
+		Contributors: []*Author{alice, eve},
+	}
+
+	// Newer report: Alice's account-level stats are more recent here.
+	alice2 := combineAuthor("Alice", 0.6, 10, 2, "CONTRIBUTOR")
+	alice2.Stats.Followers = 50
+	alice2.Stats.LastCommitDays = 5
+	alice2.Stats.SensitiveFiles = []string{".github/workflows/ci.yaml"}
+	alice2.Stats.SignalStatus = map[string]string{score.SignalPRAcceptance: SignalErrored}
+
+	bob := combineAuthor("bob", 0.5, 8, 0, "CONTRIBUTOR")
+	eve2 := combineAuthor("eve", 0.9, 20, 0, "COLLABORATOR")
+
+	b := &Report{
+		Repo:         "github.com/o/b",
+		GeneratedOn:  day.Add(time.Hour),
+		TotalCommits: 38,
+		Contributors: []*Author{alice2, bob, eve2},
+	}
+
+	return []*Report{b, a}
+}
+
+func TestCombine(t *testing.T) {
+	r, err := Combine(combineReports(), CombineOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, SchemaVersion, r.SchemaVersion)
+	assert.Empty(t, r.Repo)
+	assert.Equal(t, []string{"github.com/o/a", "github.com/o/b"}, r.Repos)
+	assert.Equal(t, "github.com", r.Host())
+	assert.Equal(t, int64(70), r.TotalCommits)
+	assert.Equal(t, int64(3), r.TotalContributors)
+	require.NotNil(t, r.Meta)
+	require.NotNil(t, r.Summary)
+	require.Len(t, r.Contributors, 3)
+
+	byName := make(map[string]*Author)
+	for _, a := range r.Contributors {
+		byName[a.Username] = a
+	}
+
+	alice := byName["alice"]
+	require.NotNil(t, alice, "first spelling of the login is kept")
+	s := alice.Stats
+	assert.Equal(t, int64(40), s.Commits)
+	assert.Equal(t, int64(2), s.UnverifiedCommits)
+	assert.False(t, s.CommitsVerified)
+	assert.Equal(t, int64(50), s.Followers, "account stats come from the newest report")
+	assert.Equal(t, int64(5), s.LastCommitDays)
+	assert.Equal(t, "MEMBER", s.AuthorAssociation, "strongest association is kept")
+	assert.Equal(t, []string{".github/workflows/ci.yaml", "Makefile"}, s.SensitiveFiles)
+	assert.Equal(t, SignalErrored, s.SignalStatus[score.SignalPRAcceptance])
+	assert.Equal(t, []RepoCommits{
+		{Repo: "github.com/o/a", Commits: 30, Reputation: 0.8},
+		{Repo: "github.com/o/b", Commits: 10, Reputation: 0.6},
+	}, alice.Repos)
+
+	want := score.Compute(s.Signals(70, 3))
+	assert.InDelta(t, want.Score, alice.Reputation, 0.0001, "scored against combined totals")
+	require.NotNil(t, alice.Breakdown)
+	assert.Positive(t, alice.Completeness)
+
+	eve := byName["eve"]
+	assert.Zero(t, eve.Reputation, "denied in one repo stays denied")
+	require.Len(t, eve.Overrides, 1)
+	assert.Equal(t, trust.OverrideDeny, eve.Overrides[0].Type)
+}
+
+func TestCombineAcrossHosts(t *testing.T) {
+	reports := combineReports()
+	reports = append(reports, &Report{
+		Repo:         "gitlab.com/g/p",
+		TotalCommits: 4,
+		Contributors: []*Author{combineAuthor("alice", 0.4, 4, 0, "NONE")},
+	})
+
+	r, err := Combine(reports, CombineOptions{})
+	require.NoError(t, err)
+	require.Len(t, r.Contributors, 4, "the same login on another host is another author")
+
+	assert.Equal(t, "alice", r.Contributors[0].Username)
+	assert.Equal(t, "github.com", r.Contributors[0].Host)
+	assert.Len(t, r.Contributors[0].Repos, 2)
+	assert.Equal(t, "alice", r.Contributors[1].Username)
+	assert.Equal(t, "gitlab.com", r.Contributors[1].Host)
+	assert.Equal(t, []RepoCommits{{Repo: "gitlab.com/g/p", Commits: 4, Reputation: 0.4}}, r.Contributors[1].Repos)
+}
+
+func TestCombineTrustAndPolicy(t *testing.T) {
+	floor, threshold := 0.95, 0.3
+	p := &policy.Policy{Rules: []*policy.Rule{{Name: "busy", When: "stats.commits >= 40", Action: policy.ActionFlag}}}
+	require.NoError(t, p.Compile(reflect.TypeFor[Stats]()))
+
+	r, err := Combine(combineReports(), CombineOptions{
+		Policy:        p,
+		Trust:         &trust.Config{Allow: []*trust.Allow{{User: "bob", Floor: &floor}}},
+		RiskThreshold: &threshold,
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.3, r.Summary.RiskThreshold, 0)
+
+	for _, a := range r.Contributors {
+		switch a.Username {
+		case "alice":
+			require.Len(t, a.Policy, 1)
+			assert.Equal(t, "busy", a.Policy[0].Rule)
+		case "bob":
+			assert.InDelta(t, 0.95, a.Reputation, 0.0001)
+			require.Len(t, a.Overrides, 1)
+		}
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	_, err := Combine(nil, CombineOptions{})
+	require.ErrorContains(t, err, "no reports")
+
+	reports := combineReports()
+	_, err = Combine(append(reports, &Report{Repo: "github.com/o/a"}), CombineOptions{})
+	require.ErrorContains(t, err, "duplicate report for github.com/o/a")
+
+	reports = combineReports()
+	reports[0].Contributors[1].Stats = nil
+	_, err = Combine(reports, CombineOptions{})
+	require.ErrorContains(t, err, "has no stats for bob")
+
+	_, err = Combine([]*Report{{Repos: []string{"x"}}}, CombineOptions{})
+	require.ErrorContains(t, err, "single repo")
+}
+
+func TestReportHost(t *testing.T) {
+	assert.Equal(t, "gitlab.com", (&Report{Repo: "gitlab.com/g/p"}).Host())
+	assert.Empty(t, (&Report{}).Host())
+}
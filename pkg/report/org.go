@@ -0,0 +1,77 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Repo visibilities.
+const (
+	VisibilityAll      = "all"
+	VisibilityPublic   = "public"
+	VisibilityPrivate  = "private"
+	VisibilityInternal = "internal"
+)
+
+// RepoFilter selects the repos of an organization to scan.
+type RepoFilter struct {
+	// Archived includes archived repos.
+	Archived bool
+	// Forks includes forked repos.
+	Forks bool
+	// Visibility limits repos to public, private, or internal ones
+	// (optional, default: all).
+	Visibility string
+	// Topics limits repos to those with every listed topic (optional).
+	Topics []string
+}
+
+// Validate checks the filter's visibility.
+func (f RepoFilter) Validate() error {
+	switch f.Visibility {
+	case "", VisibilityAll, VisibilityPublic, VisibilityPrivate, VisibilityInternal:
+		return nil
+	default:
+		return fmt.Errorf("invalid visibility: %s (must be all, public, private, or internal)", f.Visibility)
+	}
+}
+
+// Match reports whether a repo with the given properties passes the
+// filter. Topics are compared case-insensitively.
+func (f RepoFilter) Match(archived, fork bool, visibility string, topics []string) bool {
+	if archived && !f.Archived {
+		return false
+	}
+	if fork && !f.Forks {
+		return false
+	}
+	if f.Visibility != "" && f.Visibility != VisibilityAll && !strings.EqualFold(f.Visibility, visibility) {
+		return false
+	}
+	for _, t := range f.Topics {
+		if !slices.ContainsFunc(topics, func(v string) bool { return strings.EqualFold(v, t) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseOrg parses an organization given as host/org, its URL, or a bare
+// org name on DefaultHost, and returns its host and name. On hosts with
+// nested groups, the org may be a subgroup, e.g. gitlab.com/group/subgroup.
+func ParseOrg(ref string) (string, string, error) {
+	v := strings.TrimPrefix(ref, "https://")
+	v = strings.TrimPrefix(v, "http://")
+
+	host, org, ok := strings.Cut(v, "/")
+	if !ok {
+		host, org = DefaultHost, v
+	}
+	org = strings.TrimSuffix(org, "/")
+	if host == "" || slices.Contains(strings.Split(org, "/"), "") || (strings.Contains(org, "/") && !nestedHosts[host]) {
+		return "", "", fmt.Errorf("invalid org: %s (must be host/org)", ref)
+	}
+
+	return host, org, nil
+}
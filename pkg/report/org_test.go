@@ -0,0 +1,56 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoFilterMatch(t *testing.T) {
+	var f RepoFilter
+	assert.True(t, f.Match(false, false, "public", nil))
+	assert.False(t, f.Match(true, false, "public", nil), "archived repos are skipped by default")
+	assert.False(t, f.Match(false, true, "public", nil), "forks are skipped by default")
+
+	f = RepoFilter{Archived: true, Forks: true}
+	assert.True(t, f.Match(true, true, "private", nil))
+
+	f = RepoFilter{Visibility: VisibilityPrivate}
+	assert.True(t, f.Match(false, false, "Private", nil))
+	assert.False(t, f.Match(false, false, "public", nil))
+
+	f = RepoFilter{Visibility: VisibilityAll, Topics: []string{"go", "Security"}}
+	assert.True(t, f.Match(false, false, "internal", []string{"security", "go", "cli"}))
+	assert.False(t, f.Match(false, false, "internal", []string{"go"}))
+}
+
+func TestRepoFilterValidate(t *testing.T) {
+	require.NoError(t, RepoFilter{}.Validate())
+	require.NoError(t, RepoFilter{Visibility: VisibilityInternal}.Validate())
+	require.ErrorContains(t, RepoFilter{Visibility: "secret"}.Validate(), "invalid visibility")
+}
+
+func TestParseOrg(t *testing.T) {
+	tests := []struct {
+		ref  string
+		host string
+		org  string
+	}{
+		{"github.com/o", "github.com", "o"},
+		{"https://gitlab.com/g/", "gitlab.com", "g"},
+		{"o", DefaultHost, "o"},
+		{"gitlab.com/g/sub", "gitlab.com", "g/sub"},
+	}
+	for _, tt := range tests {
+		host, org, err := ParseOrg(tt.ref)
+		require.NoError(t, err, tt.ref)
+		assert.Equal(t, tt.host, host, tt.ref)
+		assert.Equal(t, tt.org, org, tt.ref)
+	}
+
+	for _, ref := range []string{"", "github.com/", "github.com/o/r", "gitlab.com/g//sub"} {
+		_, _, err := ParseOrg(ref)
+		require.ErrorContains(t, err, "invalid org", ref)
+	}
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultSensitivePaths lists path globs for CI workflows, build scripts,
+// release tooling, dependency manifests, and vendored binaries.
+// Patterns without a slash match the file name at any depth.
+var DefaultSensitivePaths = []string{
+	".github/workflows/**",
+	".github/actions/**",
+	".gitlab-ci.yml",
+	"Makefile",
+	"*.mk",
+	"*.sh",
+	"Dockerfile",
+	"tools/**",
+	"scripts/**",
+	".goreleaser.yaml",
+	".goreleaser.yml",
+	"go.mod",
+	"go.sum",
+	"package.json",
+	"package-lock.json",
+	"yarn.lock",
+	"requirements*.txt",
+	"vendor/**",
+	"*.exe",
+	"*.dll",
+	"*.so",
+	"*.jar",
+}
+
+// MatchPath reports whether the slash-separated file name matches the glob pattern.
+// In addition to [path.Match] syntax, a "**" segment matches zero or more
+// path segments, and a pattern without a slash matches the base name at any depth.
+func MatchPath(pattern, name string) bool {
+	if pattern == "" || name == "" {
+		return false
+	}
+
+	pattern = strings.TrimPrefix(pattern, "/")
+	name = strings.TrimPrefix(name, "/")
+
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// MatchAnyPath reports whether the file name matches any of the patterns.
+func MatchAnyPath(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if MatchPath(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches pattern segments against name segments, expanding "**".
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"empty pattern", "", "go.mod", false},
+		{"empty name", "go.mod", "", false},
+		{"exact root", "go.mod", "go.mod", true},
+		{"base name nested", "go.mod", "sub/dir/go.mod", true},
+		{"base name glob", "*.sh", "tools/build.sh", true},
+		{"base name no match", "*.sh", "tools/build.py", false},
+		{"double star dir", ".github/workflows/**", ".github/workflows/test.yaml", true},
+		{"double star deep", "vendor/**", "vendor/github.com/x/y.go", true},
+		{"double star other dir", ".github/workflows/**", ".github/actions/a.yaml", false},
+		{"anchored path", "tools/bump", "tools/bump", true},
+		{"anchored path nested", "tools/bump", "x/tools/bump", false},
+		{"leading slash", "/go.sum", "go.sum", true},
+		{"middle double star", "a/**/c.txt", "a/b/b/c.txt", true},
+		{"middle double star zero", "a/**/c.txt", "a/c.txt", true},
+		{"bad pattern", "[", "[", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchPath(tt.pattern, tt.file))
+		})
+	}
+}
+
+func TestMatchAnyPath(t *testing.T) {
+	assert.True(t, MatchAnyPath(DefaultSensitivePaths, ".github/workflows/on-push.yaml"))
+	assert.True(t, MatchAnyPath(DefaultSensitivePaths, "Makefile"))
+	assert.True(t, MatchAnyPath(DefaultSensitivePaths, "web/package.json"))
+	assert.False(t, MatchAnyPath(DefaultSensitivePaths, "pkg/score/score.go"))
+	assert.False(t, MatchAnyPath(nil, "Makefile"))
+}
@@ -0,0 +1,74 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PullRequest describes the pull request a report's contributors were
+// taken from: its opener and the authors and co-authors of its commits.
+type PullRequest struct {
+	// Number is the pull request number in the report's repo.
+	Number int `json:"number" yaml:"number"`
+	// Author is the login of the account that opened the pull request.
+	Author string `json:"author" yaml:"author"`
+	// HeadSHA is the head commit of the pull request when it was scored.
+	HeadSHA string `json:"head_sha,omitempty" yaml:"headSha,omitempty"`
+	// Commits lists the pull request's commits, oldest first.
+	Commits []PullRequestCommit `json:"commits,omitempty" yaml:"commits,omitempty"`
+	// ForeignCommits is the number of commits not authored by the opener.
+	ForeignCommits int `json:"foreign_commits" yaml:"foreignCommits"`
+	// Unresolved lists co-author logins from Co-authored-by trailers that
+	// match no account. They are listed in Commits but not scored.
+	Unresolved []string `json:"unresolved,omitempty" yaml:"unresolved,omitempty"`
+}
+
+// PullRequestCommit is a single commit of a pull request.
+type PullRequestCommit struct {
+	SHA string `json:"sha" yaml:"sha"`
+	// Author is the login of the commit author, empty when the commit is
+	// not linked to an account.
+	Author string `json:"author,omitempty" yaml:"author,omitempty"`
+	// AuthorName is the git author name of a commit not linked to an account.
+	AuthorName string `json:"author_name,omitempty" yaml:"authorName,omitempty"`
+	// CoAuthors lists the logins of the commit's Co-authored-by trailers, or
+	// the trailer names of co-authors not linked to an account.
+	CoAuthors []string `json:"co_authors,omitempty" yaml:"coAuthors,omitempty"`
+	// Foreign is set when the commit was not authored by the opener.
+	Foreign bool `json:"foreign,omitempty" yaml:"foreign,omitempty"`
+}
+
+// ParsePullRequest parses a pull request reference given as
+// owner/repo#number, host/owner/repo#number, or the pull request URL. Hosts
+// default to DefaultHost.
+func ParsePullRequest(ref string) (string, int, error) {
+	v := strings.TrimPrefix(ref, "https://")
+	v = strings.TrimPrefix(v, "http://")
+
+	repo, num, ok := strings.Cut(v, "#")
+	if !ok {
+		// URL form: host/owner/repo/pull/number
+		parts := strings.Split(strings.TrimSuffix(v, "/"), "/")
+		if len(parts) < repoNameParts+2 || parts[len(parts)-2] != "pull" {
+			return "", 0, fmt.Errorf("invalid pull request: %s (must be owner/repo#number)", ref)
+		}
+		repo = strings.Join(parts[:len(parts)-2], "/")
+		num = parts[len(parts)-1]
+	}
+
+	if strings.Count(repo, "/") == repoNameParts-2 {
+		repo = DefaultHost + "/" + repo
+	}
+	if parts := strings.Split(repo, "/"); len(parts) != repoNameParts || slices.Contains(parts, "") {
+		return "", 0, fmt.Errorf("invalid pull request: %s (must be owner/repo#number)", ref)
+	}
+
+	n, err := strconv.Atoi(num)
+	if err != nil || n <= 0 {
+		return "", 0, fmt.Errorf("invalid pull request number: %s", ref)
+	}
+
+	return repo, n, nil
+}
@@ -0,0 +1,42 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePullRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  string
+		repo string
+		num  int
+		err  string
+	}{
+		{name: "short", ref: "o/r#12", repo: "github.com/o/r", num: 12},
+		{name: "host", ref: "gitlab.com/g/p#3", repo: "gitlab.com/g/p", num: 3},
+		{name: "url", ref: "https://github.com/o/r/pull/7", repo: "github.com/o/r", num: 7},
+		{name: "url trailing slash", ref: "https://github.com/o/r/pull/7/", repo: "github.com/o/r", num: 7},
+		{name: "no number", ref: "o/r", err: "invalid pull request"},
+		{name: "bad number", ref: "o/r#x", err: "invalid pull request number"},
+		{name: "zero", ref: "o/r#0", err: "invalid pull request number"},
+		{name: "empty name", ref: "o/#1", err: "invalid pull request"},
+		{name: "too deep", ref: "a/b/c/d#1", err: "invalid pull request"},
+		{name: "issue url", ref: "https://github.com/o/r/issues/7", err: "invalid pull request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, num, err := ParsePullRequest(tt.ref)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.repo, repo)
+			assert.Equal(t, tt.num, num)
+		})
+	}
+}
@@ -4,14 +4,62 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/cache"
+	"github.com/mchmarny/reputer/pkg/policy"
+	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/mchmarny/reputer/pkg/trust"
 )
 
 const (
 	repoNameParts = 3
+
+	// DefaultDormancyDays is the minimum gap between an author's previous
+	// public activity and their first commit to the repo for the account
+	// to be considered reactivated.
+	DefaultDormancyDays = 365
+
+	// DefaultReactivationWindowDays is how long after the first repo commit
+	// a reactivated account remains flagged.
+	DefaultReactivationWindowDays = 90
+
+	// DefaultHost is the git provider of user lookups that name neither a
+	// host nor a repo.
+	DefaultHost = "github.com"
 )
 
+// nestedHosts are the git providers whose repos may sit in nested groups,
+// e.g. gitlab.com/group/subgroup/project.
+var nestedHosts = map[string]bool{
+	"gitlab.com": true,
+}
+
 // MakeQuery returns a new query for the given repo and commit.
 func MakeQuery(repo, commit string, stats bool) (*Query, error) {
+	q, err := DefaultQuery(stats).ForRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	q.Commit = commit
+
+	return q, nil
+}
+
+// DefaultQuery returns a query with default settings and no repo. Use
+// [Query.ForRepo] to target a repo.
+func DefaultQuery(stats bool) *Query {
+	return &Query{
+		Stats:                  stats,
+		DormancyDays:           DefaultDormancyDays,
+		ReactivationWindowDays: DefaultReactivationWindowDays,
+		RiskThreshold:          DefaultRiskThreshold,
+	}
+}
+
+// ForRepo returns a copy of the query for the given repo at the head of its
+// default branch.
+func (q *Query) ForRepo(repo string) (*Query, error) {
 	if repo == "" {
 		return nil, errors.New("repo must be specified")
 	}
@@ -20,31 +68,40 @@ func MakeQuery(repo, commit string, stats bool) (*Query, error) {
 	repo = strings.TrimPrefix(repo, "http://")
 
 	parts := strings.Split(repo, "/")
-	if len(parts) != repoNameParts {
+	if len(parts) != repoNameParts && !(nestedHosts[parts[0]] && len(parts) > repoNameParts) {
 		return nil, fmt.Errorf("invalid format: %s", repo)
 	}
 
-	q := &Query{
-		Repo:   repo,
-		Commit: commit,
-		Stats:  stats,
-		Kind:   parts[0],
-		Owner:  parts[1],
-		Name:   parts[2],
-	}
+	c := *q
+	c.Repo = repo
+	c.Commit = ""
+	c.Kind = parts[0]
+	c.Owner = strings.Join(parts[1:len(parts)-1], "/")
+	c.Name = parts[len(parts)-1]
 
-	return q, nil
+	return &c, nil
 }
 
 // Query is a query for a repo and commit.
 type Query struct {
-	// Repo is the repo to query (required).
+	// Repo is the repo to query (required unless User is set).
 	Repo string
+	// User limits the query to a single account instead of every commit
+	// author (optional). Without Repo, only account-level signals are
+	// collected.
+	User string
+	// PullRequest limits the query to the opener and the commit authors and
+	// co-authors of this pull request in Repo (optional).
+	PullRequest int
 	// Commit is the commit to query (optional).
 	Commit string
 	// Stats includes stats in the output (optional).
 	Stats bool
 
+	// Explain includes the per-signal score breakdown in the output (optional).
+	// Implied by Stats.
+	Explain bool
+
 	// Kind is the kind of repo (e.g. github.com, gitlab.com, etc.).
 	// Will be parsed from Repo.
 	Kind string
@@ -59,6 +116,95 @@ type Query struct {
 
 	// TrustedOrgs lists organizations whose members receive a scoring boost.
 	TrustedOrgs []string
+
+	// DormancyDays is the inactivity gap (in days) before the first repo
+	// commit that marks an account as reactivated.
+	DormancyDays int64
+
+	// ReactivationWindowDays is the number of days after the first repo
+	// commit during which a reactivated account stays flagged.
+	ReactivationWindowDays int64
+
+	// SensitivePaths lists path globs whose changes are profiled per author.
+	// File lists are only fetched when at least one pattern is set.
+	SensitivePaths []string
+
+	// Model is the scoring model (optional, defaults to the built-in model).
+	Model *score.Model
+
+	// CompareModels are additional models each author is scored with
+	// for side-by-side comparison (optional).
+	CompareModels []*score.Model
+
+	// Policy holds rules evaluated after scoring (optional).
+	Policy *policy.Policy
+
+	// RiskThreshold is the reputation below which an author's commits count
+	// as low-reputation in the repository summary.
+	RiskThreshold float64
+
+	// AsOf is the moment authors are scored at (optional). Age, recency, and
+	// where the provider supports it PR and repo counts are evaluated as of
+	// this time. Providers default it to the commit date when Commit is set.
+	AsOf time.Time
+
+	// Trust holds explicit allowlist, denylist, trusted org, and trusted
+	// domain decisions (optional).
+	Trust *trust.Config
+
+	// RepoTrust also loads the trust file at [trust.RepoPath] from the
+	// repo's default branch and merges it into Trust.
+	RepoTrust bool
+
+	// Accounts caches account-level lookups across the queries of one run,
+	// so an author seen in several repos is fetched once (optional, default:
+	// not cached).
+	Accounts *cache.Cache
+}
+
+// ForUser returns a copy of the query that scores only the given account.
+// The user is a login, optionally prefixed with the provider host (e.g.
+// github.com/octocat); without a host, the repo's host or [DefaultHost] is
+// used.
+func (q *Query) ForUser(user string) (*Query, error) {
+	host, login, ok := strings.Cut(user, "/")
+	if !ok {
+		host, login = q.Kind, user
+		if host == "" {
+			host = DefaultHost
+		}
+	}
+
+	if login == "" || strings.Contains(login, "/") {
+		return nil, fmt.Errorf("invalid user: %s", user)
+	}
+	if q.Kind != "" && host != q.Kind {
+		return nil, fmt.Errorf("user host %s does not match repo host %s", host, q.Kind)
+	}
+
+	c := *q
+	c.User = login
+	c.Kind = host
+
+	return &c, nil
+}
+
+// ParseAsOf parses an as-of time given as an RFC 3339 timestamp or a
+// YYYY-MM-DD date (midnight UTC). Times in the future are rejected.
+func ParseAsOf(v string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, v)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid as-of time %q (must be RFC 3339 or YYYY-MM-DD)", v)
+	}
+
+	if t.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("as-of time %s is in the future", v)
+	}
+
+	return t.UTC(), nil
 }
 
 // String returns a string representation of the query.
@@ -75,6 +221,14 @@ func (q *Query) Validate() error {
 		return errors.New("query must be specified")
 	}
 
+	// User lookups may omit the repo.
+	if q.User != "" && q.Repo == "" {
+		if q.Kind == "" {
+			return errors.New("kind must be specified")
+		}
+		return nil
+	}
+
 	if q.Repo == "" {
 		return errors.New("repo must be specified")
 	}
@@ -2,6 +2,7 @@ package report
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,6 +29,8 @@ func TestMakeQuery(t *testing.T) {
 				assert.Equal(t, "repo", q.Name)
 				assert.Equal(t, "abc123", q.Commit)
 				assert.True(t, q.Stats)
+				assert.Equal(t, int64(DefaultDormancyDays), q.DormancyDays)
+				assert.Equal(t, int64(DefaultReactivationWindowDays), q.ReactivationWindowDays)
 			},
 		},
 		{
@@ -155,3 +158,71 @@ func TestQueryString(t *testing.T) {
 	q := &Query{Repo: "github.com/o/n", Kind: "github.com", Owner: "o", Name: "n"}
 	assert.Contains(t, q.String(), "github.com/o/n")
 }
+
+func TestParseAsOf(t *testing.T) {
+	got, err := ParseAsOf("2024-03-01")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), got)
+
+	got, err = ParseAsOf("2024-03-01T10:30:00+02:00")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC), got)
+	assert.Equal(t, time.UTC, got.Location())
+
+	_, err = ParseAsOf("03/01/2024")
+	require.Error(t, err)
+
+	_, err = ParseAsOf(time.Now().Add(48 * time.Hour).Format(time.DateOnly))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "future")
+}
+
+func TestQueryForRepo(t *testing.T) {
+	q, err := MakeQuery("github.com/o/a", "abc", true)
+	require.NoError(t, err)
+	q.TrustedOrgs = []string{"acme"}
+
+	c, err := q.ForRepo("https://gitlab.com/g/b")
+	require.NoError(t, err)
+	assert.Equal(t, "gitlab.com/g/b", c.Repo)
+	assert.Equal(t, "gitlab.com", c.Kind)
+	assert.Equal(t, "g", c.Owner)
+	assert.Equal(t, "b", c.Name)
+
+	c, err = q.ForRepo("gitlab.com/g/sub/b")
+	require.NoError(t, err)
+	assert.Equal(t, "g/sub", c.Owner, "GitLab repos may be in nested groups")
+	assert.Equal(t, "b", c.Name)
+	assert.Empty(t, c.Commit)
+	assert.True(t, c.Stats)
+	assert.Equal(t, []string{"acme"}, c.TrustedOrgs)
+	assert.Equal(t, "github.com/o/a", q.Repo, "original query is unchanged")
+
+	_, err = q.ForRepo("")
+	require.Error(t, err)
+	_, err = DefaultQuery(false).ForRepo("github.com/o")
+	require.ErrorContains(t, err, "invalid format")
+}
+
+func TestQueryForUser(t *testing.T) {
+	c, err := DefaultQuery(false).ForUser("octocat")
+	require.NoError(t, err)
+	assert.Equal(t, "octocat", c.User)
+	assert.Equal(t, DefaultHost, c.Kind)
+	require.NoError(t, c.Validate())
+
+	q, err := MakeQuery("github.com/o/a", "", false)
+	require.NoError(t, err)
+	c, err = q.ForUser("github.com/octocat")
+	require.NoError(t, err)
+	assert.Equal(t, "octocat", c.User)
+	assert.Equal(t, "github.com/o/a", c.Repo)
+	assert.Empty(t, q.User, "original query is unchanged")
+
+	_, err = q.ForUser("gitlab.com/octocat")
+	require.ErrorContains(t, err, "does not match")
+	_, err = q.ForUser("github.com/")
+	require.ErrorContains(t, err, "invalid user")
+	_, err = q.ForUser("a/b/c")
+	require.ErrorContains(t, err, "invalid user")
+}
@@ -1,42 +1,145 @@
 package report
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/mchmarny/reputer/pkg/score"
+	"gopkg.in/yaml.v3"
 )
 
 // ModelVersion is the current scoring model version.
 var ModelVersion = score.ModelVersion
 
+// SchemaVersion is the version of the report format. Bump the major version
+// for changes that break existing consumers (removed or renamed fields,
+// changed types or meaning) and the minor version for added fields.
+const SchemaVersion = "1.4.0"
+
 // CategoryWeight describes a scoring category and its weight.
 type CategoryWeight = score.CategoryWeight
 
+// Breakdown explains how an author's reputation was computed.
+type Breakdown = score.Result
+
 // Meta holds scoring model metadata.
 type Meta struct {
+	ModelName    string           `json:"model_name,omitempty" yaml:"modelName,omitempty"`
 	ModelVersion string           `json:"model_version" yaml:"modelVersion"`
-	Categories   []CategoryWeight `json:"categories" yaml:"categories"`
+	ModelHash    string           `json:"model_hash,omitempty" yaml:"modelHash,omitempty"`
+	Categories   []CategoryWeight `json:"categories" yaml:"categories" jsonschema:"nullable"`
+	Comparison   []*Meta          `json:"comparison,omitempty" yaml:"comparison,omitempty"`
+}
+
+// MakeMeta returns the metadata describing the given scoring model.
+func MakeMeta(m *score.Model) *Meta {
+	if m == nil {
+		m = score.DefaultModel()
+	}
+	return &Meta{
+		ModelName:    m.Name,
+		ModelVersion: m.Version,
+		ModelHash:    m.Hash(),
+		Categories:   m.CategoryWeights(),
+	}
 }
 
 // Report is the top-level output for a reputation query.
 type Report struct {
-	Repo              string    `json:"repo,omitempty" yaml:"repo,omitempty"`
-	AtCommit          string    `json:"at_commit,omitempty" yaml:"atCommit,omitempty"`
-	GeneratedOn       time.Time `json:"generated_on,omitempty" yaml:"generatedOn,omitempty"`
-	TotalCommits      int64     `json:"total_commits,omitempty" yaml:"totalCommits,omitempty"`
-	TotalContributors int64     `json:"total_contributors,omitempty" yaml:"totalContributors,omitempty"`
-	Meta              *Meta     `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Contributors      []*Author `json:"contributors,omitempty" yaml:"contributors,omitempty"`
+	SchemaVersion     string       `json:"schema_version" yaml:"schemaVersion"`
+	Repo              string       `json:"repo,omitempty" yaml:"repo,omitempty"`
+	Repos             []string     `json:"repos,omitempty" yaml:"repos,omitempty"`
+	AtCommit          string       `json:"at_commit,omitempty" yaml:"atCommit,omitempty"`
+	PullRequest       *PullRequest `json:"pull_request,omitempty" yaml:"pullRequest,omitempty"`
+	AsOf              *time.Time   `json:"as_of,omitempty" yaml:"asOf,omitempty"`
+	GeneratedOn       time.Time    `json:"generated_on,omitempty" yaml:"generatedOn,omitempty"`
+	TotalCommits      int64        `json:"total_commits,omitempty" yaml:"totalCommits,omitempty"`
+	TotalContributors int64        `json:"total_contributors,omitempty" yaml:"totalContributors,omitempty"`
+	Meta              *Meta        `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Summary           *Summary     `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Tiers             []Tier       `json:"tiers,omitempty" yaml:"tiers,omitempty"`
+	Contributors      []*Author    `json:"contributors,omitempty" yaml:"contributors,omitempty"`
 }
 
-// SortAuthors sorts the authors by username.
+// Host returns the git provider host of the report's repo, or for a
+// combined report of its first repo. Authors of a combined report carry
+// their own host.
+func (r *Report) Host() string {
+	repo := r.Repo
+	if repo == "" && len(r.Repos) > 0 {
+		repo = r.Repos[0]
+	}
+	host, _, _ := strings.Cut(repo, "/")
+	return host
+}
+
+// SortAuthors sorts the authors by username, then host.
 func (r *Report) SortAuthors() {
 	if r.Contributors == nil {
 		return
 	}
 
 	sort.Slice(r.Contributors, func(i, j int) bool {
-		return r.Contributors[i].Username < r.Contributors[j].Username
+		a, b := r.Contributors[i], r.Contributors[j]
+		if a.Username != b.Username {
+			return a.Username < b.Username
+		}
+		return a.Host < b.Host
 	})
 }
+
+// StripDetails removes author stats and context, and unless explain is
+// set the score breakdown, from every contributor.
+func (r *Report) StripDetails(explain bool) {
+	for _, a := range r.Contributors {
+		if a == nil {
+			continue
+		}
+		a.Stats = nil
+		a.Context = nil
+		if !explain {
+			a.Breakdown = nil
+		}
+	}
+}
+
+// Load reads a report previously written as JSON or YAML.
+func Load(path string) (*Report, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // G304: path is a user-supplied report file
+	if err != nil {
+		return nil, fmt.Errorf("error reading report %s: %w", path, err)
+	}
+
+	r, err := Parse(b, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing report %s: %w", path, err)
+	}
+
+	return r, nil
+}
+
+// Parse decodes a report. The ext selects the decoder: ".yaml" or ".yml"
+// for YAML, anything else for JSON. Unknown fields are ignored so reports
+// written by other versions still load.
+func Parse(b []byte, ext string) (*Report, error) {
+	var r Report
+
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		if err := yaml.NewDecoder(bytes.NewReader(b)).Decode(&r); err != nil {
+			return nil, fmt.Errorf("error decoding YAML report: %w", err)
+		}
+	default:
+		if err := json.NewDecoder(bytes.NewReader(b)).Decode(&r); err != nil {
+			return nil, fmt.Errorf("error decoding JSON report: %w", err)
+		}
+	}
+
+	return &r, nil
+}
@@ -1,9 +1,16 @@
 package report
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/mchmarny/reputer/pkg/score"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestSortAuthors(t *testing.T) {
@@ -26,3 +33,64 @@ func TestSortAuthorsNilContributors(_ *testing.T) {
 	r := &Report{Contributors: nil}
 	r.SortAuthors() // should not panic
 }
+
+func TestMakeMeta(t *testing.T) {
+	m := MakeMeta(nil)
+	assert.Equal(t, "reputer-v3", m.ModelName)
+	assert.Equal(t, ModelVersion, m.ModelVersion)
+	assert.Equal(t, score.DefaultModel().Hash(), m.ModelHash)
+	assert.Equal(t, score.Categories(), m.Categories)
+}
+
+func TestLoadRoundTrip(t *testing.T) {
+	r := &Report{
+		Repo:         "github.com/o/r",
+		AtCommit:     "abc",
+		GeneratedOn:  time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		Meta:         MakeMeta(nil),
+		Contributors: []*Author{{Username: "alice", Reputation: 0.8, Stats: &Stats{Commits: 3}}},
+	}
+
+	dir := t.TempDir()
+	for _, ext := range []string{".json", ".yaml"} {
+		t.Run(ext, func(t *testing.T) {
+			var b []byte
+			var err error
+			if ext == ".json" {
+				b, err = json.Marshal(r)
+			} else {
+				b, err = yaml.Marshal(r)
+			}
+			require.NoError(t, err)
+
+			p := filepath.Join(dir, "report"+ext)
+			require.NoError(t, os.WriteFile(p, b, 0o600))
+
+			got, err := Load(p)
+			require.NoError(t, err)
+			assert.Equal(t, r, got)
+		})
+	}
+
+	_, err := Load(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte("{"), ".json")
+	assert.Error(t, err)
+}
+
+func TestStripDetails(t *testing.T) {
+	b := &Breakdown{Score: 0.5}
+	r := &Report{Contributors: []*Author{
+		{Username: "a", Stats: &Stats{}, Context: &AuthorContext{}, Breakdown: b},
+		nil,
+	}}
+
+	r.StripDetails(true)
+	assert.Nil(t, r.Contributors[0].Stats)
+	assert.Nil(t, r.Contributors[0].Context)
+	assert.Same(t, b, r.Contributors[0].Breakdown)
+
+	r.StripDetails(false)
+	assert.Nil(t, r.Contributors[0].Breakdown)
+}
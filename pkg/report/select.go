@@ -0,0 +1,189 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Sort keys accepted by [Selection].
+const (
+	SortUsername   = "username"
+	SortReputation = "reputation"
+	SortCommits    = "commits"
+	SortLastCommit = "last_commit"
+	SortAge        = "age"
+)
+
+// sortKeys lists the valid sort keys; all but username need author stats.
+var sortKeys = []string{SortUsername, SortReputation, SortCommits, SortLastCommit, SortAge}
+
+// Selection narrows and orders a report's contributors. Filters are applied
+// first, then the sort, then Top.
+type Selection struct {
+	// Sort is the sort key, optionally suffixed with ":asc" or ":desc"
+	// (default ascending). Empty keeps the username order.
+	Sort string
+	// Top keeps only the first N contributors after sorting (0 keeps all).
+	Top int
+	// MinScore and MaxScore keep contributors whose reputation is within
+	// the inclusive bounds (nil means unbounded).
+	MinScore *float64
+	MaxScore *float64
+	// Authors keeps contributors whose login matches any of the
+	// case-insensitive glob patterns (e.g. "dependabot*").
+	Authors []string
+}
+
+// ParseSort splits a sort value such as "reputation:desc" into its key and
+// direction.
+func ParseSort(v string) (string, bool, error) {
+	key, dir, _ := strings.Cut(v, ":")
+
+	var desc bool
+	switch dir {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return "", false, fmt.Errorf("invalid sort direction %q (must be asc or desc)", dir)
+	}
+
+	for _, k := range sortKeys {
+		if key == k {
+			return key, desc, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("invalid sort key %q (must be one of %s)", key, strings.Join(sortKeys, ", "))
+}
+
+// Validate checks the sort value, bounds, and patterns.
+func (s *Selection) Validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Sort != "" {
+		if _, _, err := ParseSort(s.Sort); err != nil {
+			return err
+		}
+	}
+
+	if s.Top < 0 {
+		return errors.New("top must be non-negative")
+	}
+
+	for _, b := range []*float64{s.MinScore, s.MaxScore} {
+		if b != nil && (*b < 0 || *b > 1) {
+			return errors.New("score bounds must be in [0, 1]")
+		}
+	}
+	if s.MinScore != nil && s.MaxScore != nil && *s.MinScore > *s.MaxScore {
+		return errors.New("min score must not exceed max score")
+	}
+
+	for _, p := range s.Authors {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid author pattern %q: %w", p, err)
+		}
+	}
+
+	return nil
+}
+
+// NeedsStats reports whether the sort key reads author stats.
+func (s *Selection) NeedsStats() bool {
+	if s == nil || s.Sort == "" {
+		return false
+	}
+	key, _, _ := ParseSort(s.Sort)
+	return key != SortUsername && key != SortReputation
+}
+
+// Select filters, sorts, and truncates the contributors. Repo-wide totals
+// and the summary are left unchanged.
+func (r *Report) Select(s *Selection) error {
+	if s == nil {
+		return nil
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	list := make([]*Author, 0, len(r.Contributors))
+	for _, a := range r.Contributors {
+		if a != nil && s.keep(a) {
+			list = append(list, a)
+		}
+	}
+
+	if s.Sort != "" {
+		key, desc, _ := ParseSort(s.Sort)
+		sort.SliceStable(list, func(i, j int) bool {
+			a, b := list[i], list[j]
+			if key == SortUsername {
+				return (a.Username < b.Username) != desc
+			}
+			if va, vb := sortValue(a, key), sortValue(b, key); va != vb {
+				return (va < vb) != desc
+			}
+			return a.Username < b.Username
+		})
+	}
+
+	if s.Top > 0 && len(list) > s.Top {
+		list = list[:s.Top]
+	}
+
+	r.Contributors = list
+
+	return nil
+}
+
+// keep reports whether the author passes the score and login filters.
+func (s *Selection) keep(a *Author) bool {
+	if s.MinScore != nil && a.Reputation < *s.MinScore {
+		return false
+	}
+	if s.MaxScore != nil && a.Reputation > *s.MaxScore {
+		return false
+	}
+
+	if len(s.Authors) == 0 {
+		return true
+	}
+	login := strings.ToLower(a.Username)
+	for _, p := range s.Authors {
+		if ok, _ := path.Match(strings.ToLower(p), login); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortValue returns the author's numeric value for the key. Last commit
+// sorts by date, so more days since the last commit is a smaller value.
+// Authors without stats sort as zero.
+func sortValue(a *Author, key string) float64 {
+	if key == SortReputation {
+		return a.Reputation
+	}
+	if a.Stats == nil {
+		return 0
+	}
+
+	switch key {
+	case SortCommits:
+		return float64(a.Stats.Commits)
+	case SortLastCommit:
+		return -float64(a.Stats.LastCommitDays)
+	case SortAge:
+		return float64(a.Stats.AgeDays)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,90 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func selectReport() *Report {
+	return &Report{
+		TotalContributors: 4,
+		Contributors: []*Author{
+			{Username: "alice", Reputation: 0.9, Stats: &Stats{Commits: 50, AgeDays: 4000, LastCommitDays: 2}},
+			{Username: "bob", Reputation: 0.3, Stats: &Stats{Commits: 2, AgeDays: 30, LastCommitDays: 200}},
+			{Username: "dependabot[bot]", Reputation: 0.5, Stats: &Stats{Commits: 20, AgeDays: 2000, LastCommitDays: 1}},
+			{Username: "Carol", Reputation: 0.3, Stats: &Stats{Commits: 7, AgeDays: 900, LastCommitDays: 40}},
+		},
+	}
+}
+
+func usernames(r *Report) []string {
+	var list []string
+	for _, a := range r.Contributors {
+		list = append(list, a.Username)
+	}
+	return list
+}
+
+func TestSelectSort(t *testing.T) {
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{sort: "reputation", want: []string{"Carol", "bob", "dependabot[bot]", "alice"}},
+		{sort: "reputation:desc", want: []string{"alice", "dependabot[bot]", "Carol", "bob"}},
+		{sort: "commits:desc", want: []string{"alice", "dependabot[bot]", "Carol", "bob"}},
+		{sort: "last_commit", want: []string{"bob", "Carol", "alice", "dependabot[bot]"}},
+		{sort: "age", want: []string{"bob", "Carol", "dependabot[bot]", "alice"}},
+		{sort: "username:desc", want: []string{"dependabot[bot]", "bob", "alice", "Carol"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.sort, func(t *testing.T) {
+			r := selectReport()
+			require.NoError(t, r.Select(&Selection{Sort: tc.sort}))
+			assert.Equal(t, tc.want, usernames(r))
+		})
+	}
+}
+
+func TestSelectFilters(t *testing.T) {
+	lo, hi := 0.3, 0.5
+
+	r := selectReport()
+	require.NoError(t, r.Select(&Selection{Sort: "reputation", Top: 2}))
+	assert.Equal(t, []string{"Carol", "bob"}, usernames(r))
+	assert.Equal(t, int64(4), r.TotalContributors)
+
+	r = selectReport()
+	require.NoError(t, r.Select(&Selection{MinScore: &lo, MaxScore: &hi}))
+	assert.Equal(t, []string{"bob", "dependabot[bot]", "Carol"}, usernames(r))
+
+	r = selectReport()
+	require.NoError(t, r.Select(&Selection{Authors: []string{"*[[]bot]", "c*"}}))
+	assert.Equal(t, []string{"dependabot[bot]", "Carol"}, usernames(r))
+
+	r = selectReport()
+	require.NoError(t, r.Select(nil))
+	assert.Len(t, r.Contributors, 4)
+}
+
+func TestSelectionValidate(t *testing.T) {
+	lo, hi, bad := 0.6, 0.4, 2.0
+
+	for name, s := range map[string]*Selection{
+		"sort key":  {Sort: "stars"},
+		"direction": {Sort: "age:up"},
+		"top":       {Top: -1},
+		"bounds":    {MinScore: &bad},
+		"min > max": {MinScore: &lo, MaxScore: &hi},
+		"bad glob":  {Authors: []string{"["}},
+	} {
+		assert.Error(t, s.Validate(), name)
+	}
+
+	assert.True(t, (&Selection{Sort: "age:desc"}).NeedsStats())
+	assert.False(t, (&Selection{Sort: "reputation"}).NeedsStats())
+	assert.False(t, (*Selection)(nil).NeedsStats())
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"sort"
+)
+
+// DefaultRiskThreshold is the reputation below which an author's commits
+// count as low-reputation in the repository summary.
+const DefaultRiskThreshold = 0.5
+
+// summaryPrecision is the number of decimals kept for summary ratios.
+const summaryPrecision = 4
+
+// Summary describes the repository as a whole.
+type Summary struct {
+	// AvgReputation is the author reputation averaged over commits.
+	AvgReputation float64 `json:"avg_reputation" yaml:"avgReputation" jsonschema:"minimum=0,maximum=1"`
+	// RiskThreshold is the reputation below which authors count as low-reputation.
+	RiskThreshold float64 `json:"risk_threshold" yaml:"riskThreshold" jsonschema:"minimum=0,maximum=1"`
+	// LowReputationAuthors is the number of authors below RiskThreshold.
+	LowReputationAuthors int64 `json:"low_reputation_authors" yaml:"lowReputationAuthors"`
+	// LowReputationCommitShare is the share of commits by authors below RiskThreshold.
+	LowReputationCommitShare float64 `json:"low_reputation_commit_share" yaml:"lowReputationCommitShare" jsonschema:"minimum=0,maximum=1"`
+	// UnverifiedCommitShare is the share of commits without a verified signature.
+	UnverifiedCommitShare float64 `json:"unverified_commit_share" yaml:"unverifiedCommitShare" jsonschema:"minimum=0,maximum=1"`
+	// BusFactor50 is the minimum number of authors covering 50% of commits.
+	BusFactor50 int64 `json:"bus_factor_50" yaml:"busFactor50"`
+	// BusFactor80 is the minimum number of authors covering 80% of commits.
+	BusFactor80 int64 `json:"bus_factor_80" yaml:"busFactor80"`
+	// FirstTimeContributors is the number of authors whose first contribution
+	// to the repo is in range.
+	FirstTimeContributors int64 `json:"first_time_contributors" yaml:"firstTimeContributors"`
+}
+
+// Summarize computes the repository summary from scored authors. Authors
+// must still carry their stats; authors without stats are skipped.
+func Summarize(authors []*Author, threshold float64) *Summary {
+	s := &Summary{RiskThreshold: threshold}
+
+	var total, unverified, low int64
+	var weighted float64
+	commits := make([]int64, 0, len(authors))
+
+	for _, a := range authors {
+		if a == nil || a.Stats == nil {
+			continue
+		}
+
+		c := a.Stats.Commits
+		total += c
+		unverified += a.Stats.UnverifiedCommits
+		weighted += a.Reputation * float64(c)
+		commits = append(commits, c)
+
+		if a.Reputation < threshold {
+			s.LowReputationAuthors++
+			low += c
+		}
+
+		if isFirstTime(a.Stats.AuthorAssociation) {
+			s.FirstTimeContributors++
+		}
+	}
+
+	if total == 0 {
+		return s
+	}
+
+	s.AvgReputation = ToFixed(weighted/float64(total), 2)
+	s.LowReputationCommitShare = ToFixed(float64(low)/float64(total), summaryPrecision)
+	s.UnverifiedCommitShare = ToFixed(float64(unverified)/float64(total), summaryPrecision)
+	s.BusFactor50 = busFactor(commits, total, 0.5)
+	s.BusFactor80 = busFactor(commits, total, 0.8)
+
+	return s
+}
+
+// busFactor returns the minimum number of authors whose commits together
+// make up at least share of total.
+func busFactor(commits []int64, total int64, share float64) int64 {
+	sorted := append([]int64(nil), commits...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+
+	target := share * float64(total)
+	var covered, n int64
+	for _, c := range sorted {
+		if float64(covered) >= target {
+			break
+		}
+		covered += c
+		n++
+	}
+
+	return n
+}
+
+// isFirstTime reports whether a GitHub author association marks a first-time contributor.
+func isFirstTime(assoc string) bool {
+	return assoc == "FIRST_TIME_CONTRIBUTOR" || assoc == "FIRST_TIMER"
+}
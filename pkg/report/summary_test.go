@@ -0,0 +1,68 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func summaryAuthor(name string, rep float64, commits, unverified int64, assoc string) *Author {
+	a := MakeAuthor(name)
+	a.Reputation = rep
+	a.Stats.Commits = commits
+	a.Stats.UnverifiedCommits = unverified
+	a.Stats.AuthorAssociation = assoc
+	return a
+}
+
+func TestSummarize(t *testing.T) {
+	authors := []*Author{
+		summaryAuthor("a", 0.9, 60, 0, "OWNER"),
+		summaryAuthor("b", 0.4, 25, 5, "CONTRIBUTOR"),
+		summaryAuthor("c", 0.2, 10, 10, "FIRST_TIME_CONTRIBUTOR"),
+		summaryAuthor("d", 0.7, 5, 0, "FIRST_TIMER"),
+		{Username: "no-stats", Reputation: 0.1},
+		nil,
+	}
+
+	s := Summarize(authors, DefaultRiskThreshold)
+	assert.InDelta(t, 0.7, s.AvgReputation, 0.0001) // (54 + 10 + 2 + 3.5) / 100, rounded
+	assert.InDelta(t, 0.5, s.RiskThreshold, 0.0001)
+	assert.Equal(t, int64(2), s.LowReputationAuthors)
+	assert.InDelta(t, 0.35, s.LowReputationCommitShare, 0.0001)
+	assert.InDelta(t, 0.15, s.UnverifiedCommitShare, 0.0001)
+	assert.Equal(t, int64(1), s.BusFactor50)
+	assert.Equal(t, int64(2), s.BusFactor80)
+	assert.Equal(t, int64(2), s.FirstTimeContributors)
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	s := Summarize(nil, 0.3)
+	assert.InDelta(t, 0.3, s.RiskThreshold, 0.0001)
+	assert.Zero(t, s.AvgReputation)
+	assert.Zero(t, s.BusFactor50)
+}
+
+func TestBusFactor(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []int64
+		share   float64
+		want    int64
+	}{
+		{"single author", []int64{10}, 0.8, 1},
+		{"even split half", []int64{10, 10, 10, 10}, 0.5, 2},
+		{"even split most", []int64{10, 10, 10, 10}, 0.8, 4},
+		{"unsorted input", []int64{1, 1, 8}, 0.5, 1},
+		{"no commits", nil, 0.5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var total int64
+			for _, c := range tt.commits {
+				total += c
+			}
+			assert.Equal(t, tt.want, busFactor(tt.commits, total, tt.share))
+		})
+	}
+}
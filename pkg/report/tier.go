@@ -0,0 +1,97 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tier is a named reputation band. An author belongs to the highest tier
+// whose Min their reputation reaches.
+type Tier struct {
+	Name string  `json:"name" yaml:"name"`
+	Min  float64 `json:"min" yaml:"min" jsonschema:"minimum=0,maximum=1"`
+}
+
+// DefaultTiers are the reputation bands used when none are configured,
+// ordered from highest to lowest.
+var DefaultTiers = []Tier{
+	{Name: "high", Min: 0.7},
+	{Name: "medium", Min: 0.4},
+	{Name: "low", Min: 0},
+}
+
+// ParseTiers parses a comma-separated list of name=min tiers such as
+// "high=0.7,medium=0.4,low=0". Names must be unique, minimums must be
+// distinct and in [0, 1], and one tier must start at 0 so every
+// reputation has a tier. The result is ordered from highest to lowest.
+func ParseTiers(v string) ([]Tier, error) {
+	var tiers []Tier
+	names := make(map[string]bool)
+	mins := make(map[float64]bool)
+
+	for item := range strings.SplitSeq(v, ",") {
+		name, val, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid tier %q (must be name=min)", item)
+		}
+
+		m, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || m < 0 || m > 1 {
+			return nil, fmt.Errorf("invalid tier %q minimum (must be in [0, 1])", item)
+		}
+
+		if names[name] {
+			return nil, fmt.Errorf("duplicate tier name: %s", name)
+		}
+		if mins[m] {
+			return nil, fmt.Errorf("duplicate tier minimum: %v", m)
+		}
+		names[name] = true
+		mins[m] = true
+
+		tiers = append(tiers, Tier{Name: name, Min: m})
+	}
+
+	if !mins[0] {
+		return nil, errors.New("one tier must have a minimum of 0")
+	}
+
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Min > tiers[j].Min })
+
+	return tiers, nil
+}
+
+// TierFor returns the name of the first tier, from highest to lowest, whose
+// minimum the reputation reaches. Empty tiers use [DefaultTiers].
+func TierFor(tiers []Tier, reputation float64) string {
+	if len(tiers) == 0 {
+		tiers = DefaultTiers
+	}
+
+	for _, t := range tiers {
+		if reputation >= t.Min {
+			return t.Name
+		}
+	}
+
+	return tiers[len(tiers)-1].Name
+}
+
+// AssignTiers records the tiers on the report and sets each contributor's
+// tier. Empty tiers use [DefaultTiers].
+func (r *Report) AssignTiers(tiers []Tier) {
+	if len(tiers) == 0 {
+		tiers = DefaultTiers
+	}
+
+	r.Tiers = tiers
+	for _, a := range r.Contributors {
+		if a != nil {
+			a.Tier = TierFor(tiers, a.Reputation)
+		}
+	}
+}
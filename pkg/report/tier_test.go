@@ -0,0 +1,46 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTierFor(t *testing.T) {
+	tests := []struct {
+		reputation float64
+		want       string
+	}{
+		{1, "high"},
+		{0.7, "high"},
+		{0.69, "medium"},
+		{0.4, "medium"},
+		{0.39, "low"},
+		{0, "low"},
+		{-1, "low"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, TierFor(nil, tt.reputation), "reputation %v", tt.reputation)
+	}
+}
+
+func TestParseTiers(t *testing.T) {
+	tiers, err := ParseTiers("block=0, review = 0.5,trusted=0.85")
+	require.NoError(t, err)
+	assert.Equal(t, []Tier{{Name: "trusted", Min: 0.85}, {Name: "review", Min: 0.5}, {Name: "block", Min: 0}}, tiers)
+	assert.Equal(t, "review", TierFor(tiers, 0.6))
+
+	for _, bad := range []string{"", "high", "high=x", "high=2", "a=0,a=0.5", "a=0,b=0", "a=0.5,b=0.7"} {
+		_, err := ParseTiers(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestAssignTiers(t *testing.T) {
+	r := &Report{Contributors: []*Author{{Username: "a", Reputation: 0.9}, nil, {Username: "b", Reputation: 0.1}}}
+	r.AssignTiers(nil)
+	assert.Equal(t, DefaultTiers, r.Tiers)
+	assert.Equal(t, "high", r.Contributors[0].Tier)
+	assert.Equal(t, "low", r.Contributors[2].Tier)
+}
@@ -0,0 +1,313 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mchmarny/reputer/pkg/provider"
+	"github.com/mchmarny/reputer/pkg/report"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultConcurrency is the number of repos a batch scans at once.
+const DefaultConcurrency = 4
+
+// formatExt maps output formats to the file extension of batch reports.
+var formatExt = map[string]string{
+	"":         ".json",
+	"json":     ".json",
+	"yaml":     ".yaml",
+	"sarif":    ".sarif",
+	"markdown": ".md",
+	"html":     ".html",
+	"csv":      ".csv",
+	"tsv":      ".tsv",
+}
+
+// runBatch scans every repo of the run's repos file or org into its own
+// report, written to the output dir or streamed as NDJSON, one report per
+// line. For an org, a summary combining the repo reports follows them.
+// A failed repo is logged and skipped; the batch returns an error listing
+// the failed repos after all others are written, or when none failed but
+// some, or the org summary, fall below the fail-below gate, an error
+// wrapping [ErrBelowThreshold].
+func runBatch(ctx context.Context, rr *reportRun) (retErr error) {
+	repos, err := rr.batchRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	var stream io.Writer
+	if rr.opt.OutputDir != "" {
+		if err := os.MkdirAll(rr.opt.OutputDir, 0o755); err != nil {
+			return fmt.Errorf("error creating output dir %s: %w", rr.opt.OutputDir, err)
+		}
+	} else {
+		stream = os.Stdout
+		if rr.opt.File != "" {
+			f, err := os.Create(rr.opt.File)
+			if err != nil {
+				return fmt.Errorf("error creating file %s: %w", rr.opt.File, err)
+			}
+			defer func() {
+				if cerr := f.Close(); cerr != nil && retErr == nil {
+					retErr = fmt.Errorf("error closing file %s: %w", rr.opt.File, cerr)
+				}
+			}()
+			stream = f
+		}
+	}
+
+	limit := rr.opt.Concurrency
+	if limit == 0 {
+		limit = DefaultConcurrency
+	}
+
+	var (
+		mu      sync.Mutex
+		failed  []string
+		below   []string
+		reports []*report.Report
+	)
+
+	// Repo failures are collected rather than returned so one repo does
+	// not cancel the others.
+	var g errgroup.Group
+	g.SetLimit(limit)
+
+	for _, repo := range repos {
+		g.Go(func() error {
+			snap, gateErr, err := rr.batchRepo(ctx, repo, stream, &mu)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				slog.Error("repo failed", "repo", repo, "error", err)
+				failed = append(failed, repo)
+			case gateErr != nil:
+				slog.Warn("repo below threshold", "repo", repo, "error", gateErr)
+				below = append(below, repo)
+			}
+			if snap != nil {
+				reports = append(reports, snap)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait() // repo errors are collected above
+
+	// The summary covers the repos that were scanned, even when some failed.
+	var summaryGate, summaryErr error
+	if rr.opt.Org != "" && len(reports) > 0 {
+		summaryGate, summaryErr = rr.orgSummary(reports, stream)
+	}
+
+	slog.Info("batch complete",
+		"repos", len(repos),
+		"failed", len(failed),
+		"below_threshold", len(below))
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("%d of %d repos failed: %s", len(failed), len(repos), strings.Join(failed, ", "))
+	}
+	if summaryErr != nil {
+		return summaryErr
+	}
+	if len(below) > 0 {
+		sort.Strings(below)
+		return fmt.Errorf("%w: %d of %d repos: %s", ErrBelowThreshold, len(below), len(repos), strings.Join(below, ", "))
+	}
+	if summaryGate != nil {
+		return fmt.Errorf("org summary of %s: %w", rr.opt.Org, summaryGate)
+	}
+
+	return nil
+}
+
+// batchRepos returns the repos of the run's org that pass its filter, or
+// those listed in its repos file.
+func (rr *reportRun) batchRepos(ctx context.Context) ([]string, error) {
+	if rr.opt.Org != "" {
+		repos, err := provider.ListRepos(ctx, rr.opt.Org, rr.opt.RepoFilter)
+		if err != nil {
+			return nil, err
+		}
+		if len(repos) == 0 {
+			return nil, fmt.Errorf("no repos of %s pass the filter", rr.opt.Org)
+		}
+		return repos, nil
+	}
+
+	repos, err := readRepoList(rr.opt.ReposFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repos in %s", rr.opt.ReposFile)
+	}
+
+	return repos, nil
+}
+
+// batchRepo scans a single repo of a batch and writes its report. For an
+// org, it also returns a snapshot of the report taken before selection, for
+// the org summary. It returns the repo's gate result and any error.
+func (rr *reportRun) batchRepo(ctx context.Context, repo string, stream io.Writer, mu *sync.Mutex) (snap *report.Report, gateErr, err error) {
+	q, err := rr.q.ForRepo(repo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating query for %s: %w", repo, err)
+	}
+
+	r, err := provider.GetAuthors(ctx, *q)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing authors for %s: %w", repo, err)
+	}
+
+	if rr.opt.Org != "" {
+		snap = snapshot(r)
+	}
+
+	if gateErr, err = rr.finish(r); err != nil {
+		return nil, nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err := rr.writeBatch(q.Repo, r, stream); err != nil {
+		return nil, nil, err
+	}
+
+	return snap, gateErr, nil
+}
+
+// orgSummary combines the org's repo reports into one and writes it like a
+// repo report, named after the org. It returns the summary's gate result
+// and any error.
+func (rr *reportRun) orgSummary(reports []*report.Report, stream io.Writer) (gateErr, err error) {
+	r, err := report.Combine(reports, report.CombineOptions{
+		Model:         rr.q.Model,
+		Policy:        rr.q.Policy,
+		Trust:         rr.q.Trust,
+		RiskThreshold: &rr.q.RiskThreshold,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error combining reports of %s: %w", rr.opt.Org, err)
+	}
+
+	if gateErr, err = rr.finish(r); err != nil {
+		return nil, err
+	}
+
+	if err := rr.writeBatch(rr.opt.Org, r, stream); err != nil {
+		return nil, err
+	}
+
+	return gateErr, nil
+}
+
+// writeBatch writes the report of a batch entry: to its own file in the
+// output dir, named after the entry, or as one line of stream. Callers
+// writing concurrently to stream must serialize the calls.
+func (rr *reportRun) writeBatch(name string, r *report.Report, stream io.Writer) error {
+	if stream != nil {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("error encoding report for %s: %w", name, err)
+		}
+		if _, err := stream.Write(append(b, '\n')); err != nil {
+			return fmt.Errorf("error writing report for %s: %w", name, err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := rr.write(&buf, r); err != nil {
+		return err
+	}
+
+	path := filepath.Join(rr.opt.OutputDir, batchFileName(name, rr.opt.Format))
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("error writing report for %s: %w", name, err)
+	}
+	slog.Debug("wrote report", "name", name, "file", path)
+
+	return nil
+}
+
+// snapshot copies the report and its authors, so finishing the report
+// does not change the copy.
+func snapshot(r *report.Report) *report.Report {
+	c := *r
+	c.Contributors = make([]*report.Author, 0, len(r.Contributors))
+	for _, a := range r.Contributors {
+		if a == nil {
+			continue
+		}
+		ac := *a
+		c.Contributors = append(c.Contributors, &ac)
+	}
+	return &c
+}
+
+// batchFileName returns the report file name of a repo or org, e.g.
+// github.com_owner_name.json.
+func batchFileName(repo, format string) string {
+	return strings.ReplaceAll(repo, "/", "_") + formatExt[format]
+}
+
+// readRepoList reads repo URIs, one per line, from path or with "-" from
+// stdin. Blank lines and lines starting with # are skipped, as are
+// duplicates.
+func readRepoList(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		b, err := os.ReadFile(path) //nolint:gosec // G304: path is a user-supplied repos file
+		if err != nil {
+			return nil, fmt.Errorf("error reading repos file %s: %w", path, err)
+		}
+		r = bytes.NewReader(b)
+	}
+
+	list, err := parseRepoList(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading repos file %s: %w", path, err)
+	}
+
+	return list, nil
+}
+
+// parseRepoList parses a repo list. See [readRepoList].
+func parseRepoList(r io.Reader) ([]string, error) {
+	var list []string
+	seen := make(map[string]bool)
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if seen[line] {
+			continue
+		}
+		seen[line] = true
+		list = append(list, line)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
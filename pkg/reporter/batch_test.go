@@ -0,0 +1,89 @@
+package reporter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepoList(t *testing.T) {
+	list, err := parseRepoList(strings.NewReader("# org repos\ngithub.com/o/a\n\n  github.com/o/b  \ngithub.com/o/a\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github.com/o/a", "github.com/o/b"}, list)
+
+	list, err = parseRepoList(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestBatchFileName(t *testing.T) {
+	assert.Equal(t, "github.com_o_a.json", batchFileName("github.com/o/a", ""))
+	assert.Equal(t, "github.com_o_a.md", batchFileName("github.com/o/a", "markdown"))
+}
+
+func TestRunBatchCollectsFailures(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "repos.txt")
+	require.NoError(t, os.WriteFile(file, []byte("github.com/o/a\ninvalid\ngithub.com/o/b\n"), 0o600))
+
+	err := ListCommitAuthors(context.Background(), &ListCommitAuthorsOptions{
+		ReposFile: file,
+		OutputDir: filepath.Join(dir, "out"),
+	})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrBelowThreshold)
+	assert.Equal(t, "3 of 3 repos failed: github.com/o/a, github.com/o/b, invalid", err.Error())
+
+	err = ListCommitAuthors(context.Background(), &ListCommitAuthorsOptions{
+		ReposFile: filepath.Join(dir, "missing.txt"),
+	})
+	require.ErrorContains(t, err, "error reading repos file")
+}
+
+func TestSnapshot(t *testing.T) {
+	r := &report.Report{
+		Repo:         "github.com/o/a",
+		Contributors: []*report.Author{{Username: "alice", Stats: &report.Stats{Commits: 3}}, nil},
+	}
+
+	c := snapshot(r)
+	r.StripDetails(false)
+	r.Contributors = r.Contributors[:0]
+
+	require.Len(t, c.Contributors, 1)
+	assert.Equal(t, "alice", c.Contributors[0].Username)
+	require.NotNil(t, c.Contributors[0].Stats)
+	assert.Equal(t, int64(3), c.Contributors[0].Stats.Commits)
+}
+
+func TestOrgSummary(t *testing.T) {
+	dir := t.TempDir()
+	opt := &ListCommitAuthorsOptions{Org: "github.com/o", OutputDir: dir}
+	require.NoError(t, opt.Validate())
+	rr, err := newReportRun(opt)
+	require.NoError(t, err)
+
+	reports := []*report.Report{
+		{Repo: "github.com/o/a", TotalCommits: 3, Contributors: []*report.Author{{Username: "alice", Stats: &report.Stats{Commits: 3}}}},
+		{Repo: "github.com/o/b", TotalCommits: 2, Contributors: []*report.Author{{Username: "Alice", Stats: &report.Stats{Commits: 2}}}},
+	}
+
+	gateErr, err := rr.orgSummary(reports, nil)
+	require.NoError(t, err)
+	require.NoError(t, gateErr)
+
+	r, err := report.Load(filepath.Join(dir, "github.com_o.json"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github.com/o/a", "github.com/o/b"}, r.Repos)
+	assert.Equal(t, int64(5), r.TotalCommits)
+	require.Len(t, r.Contributors, 1)
+	assert.Nil(t, r.Contributors[0].Stats, "stats are stripped unless requested")
+}
@@ -0,0 +1,238 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mchmarny/reputer/pkg/cache"
+	"github.com/mchmarny/reputer/pkg/deps"
+	"github.com/mchmarny/reputer/pkg/provider"
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/mchmarny/reputer/pkg/schema"
+	"golang.org/x/sync/errgroup"
+)
+
+// fixtureMappings are the file names of the dependency-to-repo mapping in
+// a fixtures directory, in order of preference.
+var fixtureMappings = []string{"repos.yaml", "repos.yml", "repos.json"}
+
+// ScanDependencies parses the manifests, resolves each dependency to its
+// source repo, scores the contributors of each repo once, and writes the
+// per-dependency risk. Dependencies that cannot be resolved or scanned are
+// reported without a risk. When a fail-below score is set and any scored
+// dependency falls below it, the report is still written and an error
+// wrapping [ErrBelowThreshold] is returned.
+func ScanDependencies(ctx context.Context, opt *ScanDependenciesOptions) (retErr error) {
+	if opt == nil {
+		return errors.New("options must be specified")
+	}
+
+	if err := opt.Validate(); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+
+	lists := make([][]deps.Dependency, 0, len(opt.Manifests))
+	for _, path := range opt.Manifests {
+		list, err := deps.ParseManifest(path)
+		if err != nil {
+			return err
+		}
+		lists = append(lists, list)
+	}
+	list := deps.Merge(lists, opt.DirectOnly)
+
+	threshold := report.DefaultRiskThreshold
+	if opt.RiskThreshold != nil {
+		threshold = *opt.RiskThreshold
+	}
+
+	var tiers []report.Tier
+	if opt.Tiers != "" {
+		var err error
+		if tiers, err = report.ParseTiers(opt.Tiers); err != nil {
+			return fmt.Errorf("error parsing tiers for %s: %w", opt, err)
+		}
+	}
+
+	resolve, fetch, err := dependencySources(opt, threshold)
+	if err != nil {
+		return err
+	}
+
+	limit := opt.Concurrency
+	if limit == 0 {
+		limit = DefaultConcurrency
+	}
+
+	// Resolve every dependency, then scan each distinct repo once.
+	results := make([]*deps.Result, len(list))
+	var g errgroup.Group
+	g.SetLimit(limit)
+	for i, d := range list {
+		g.Go(func() error {
+			r := &deps.Result{Dependency: d}
+			repo, err := resolve(ctx, d)
+			if err != nil {
+				slog.Debug("dependency not resolved", "dependency", d.Name, "error", err)
+				r.Error = err.Error()
+			}
+			r.Repo = repo
+			results[i] = r
+			return nil
+		})
+	}
+	_ = g.Wait() // resolution errors are recorded on the results
+
+	var repos []string
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if r.Error == "" && !seen[r.Repo] {
+			seen[r.Repo] = true
+			repos = append(repos, r.Repo)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		reports = make(map[string]*report.Report, len(repos))
+		failed  = make(map[string]error)
+	)
+	for _, repo := range repos {
+		g.Go(func() error {
+			rpt, err := fetch(ctx, repo)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				slog.Error("dependency repo failed", "repo", repo, "error", err)
+				failed[repo] = err
+				return nil
+			}
+			reports[repo] = rpt
+			return nil
+		})
+	}
+	_ = g.Wait() // scan errors are recorded on the results
+
+	var below []string
+	for _, r := range results {
+		if r.Error != "" {
+			continue
+		}
+		if err := failed[r.Repo]; err != nil {
+			r.Error = err.Error()
+			continue
+		}
+		r.Risk = deps.Assess(reports[r.Repo], tiers, threshold)
+		if opt.FailBelow > 0 && r.Risk.Reputation < opt.FailBelow {
+			below = append(below, r.Name)
+		}
+	}
+
+	d := deps.New(opt.Manifests, results, tiers, threshold)
+
+	slog.Info("dependency scan complete",
+		"dependencies", d.Summary.Dependencies,
+		"repos", d.Summary.Repos,
+		"unscored", d.Summary.Unscored)
+
+	f := os.Stdout
+	if opt.File != "" {
+		f, err = os.Create(opt.File)
+		if err != nil {
+			return fmt.Errorf("error creating file %s: %w", opt.File, err)
+		}
+		defer func() {
+			if cerr := f.Close(); cerr != nil && retErr == nil {
+				retErr = fmt.Errorf("error closing file %s: %w", opt.File, cerr)
+			}
+		}()
+	}
+
+	switch opt.Format {
+	case "json":
+		err = deps.JSON(f, d)
+	case "yaml":
+		err = deps.YAML(f, d)
+	case "markdown":
+		err = deps.Markdown(f, d)
+	default:
+		err = deps.Text(f, d)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing dependencies for %s: %w", opt, err)
+	}
+
+	if len(below) > 0 {
+		return fmt.Errorf("%w: %d of %d dependencies below %.2f: %s",
+			ErrBelowThreshold, len(below), len(results), opt.FailBelow, strings.Join(below, ", "))
+	}
+
+	return nil
+}
+
+// dependencySources returns how dependencies are resolved to repos and how
+// repo reports are fetched: from the fixtures directory when one is set,
+// otherwise from the package registries and git providers.
+func dependencySources(opt *ScanDependenciesOptions, threshold float64) (deps.Resolver, func(context.Context, string) (*report.Report, error), error) {
+	if opt.Fixtures != "" {
+		m := make(map[string]string)
+		for _, name := range fixtureMappings {
+			path := filepath.Join(opt.Fixtures, name)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			var err error
+			if m, err = deps.LoadMapping(path); err != nil {
+				return nil, nil, err
+			}
+			break
+		}
+
+		fetch := func(_ context.Context, repo string) (*report.Report, error) {
+			return loadFixtureReport(opt.Fixtures, repo)
+		}
+		return deps.FixtureResolver(m), fetch, nil
+	}
+
+	q := report.DefaultQuery(true)
+	q.TrustedOrgs = opt.TrustedOrgs
+	q.Accounts = cache.New(accountTTL)
+	q.RiskThreshold = threshold
+
+	fetch := func(ctx context.Context, repo string) (*report.Report, error) {
+		rq, err := q.ForRepo(repo)
+		if err != nil {
+			return nil, fmt.Errorf("error creating query for %s: %w", repo, err)
+		}
+		r, err := provider.GetAuthors(ctx, *rq)
+		if err != nil {
+			return nil, fmt.Errorf("error listing authors for %s: %w", repo, err)
+		}
+		return r, nil
+	}
+	return deps.OnlineResolver(deps.Registries{}), fetch, nil
+}
+
+// loadFixtureReport loads the saved JSON or YAML report of a repo from the
+// fixtures directory.
+func loadFixtureReport(dir, repo string) (*report.Report, error) {
+	for _, format := range []string{"json", "yaml"} {
+		path := filepath.Join(dir, batchFileName(repo, format))
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		r, err := schema.LoadReport(path)
+		if err != nil {
+			return nil, fmt.Errorf("error loading fixture report: %w", err)
+		}
+		return r, nil
+	}
+	return nil, fmt.Errorf("no fixture report for %s in %s", repo, dir)
+}
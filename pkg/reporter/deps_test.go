@@ -0,0 +1,88 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mchmarny/reputer/pkg/deps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanDependenciesFixtures(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+
+	dir := t.TempDir()
+	fixtures := filepath.Join(dir, "fixtures")
+	require.NoError(t, os.MkdirAll(fixtures, 0o755))
+
+	manifest := filepath.Join(dir, "go.mod")
+	require.NoError(t, os.WriteFile(manifest, []byte(`module example.com/app
+
+require (
+	github.com/o/a v1.0.0
+	github.com/o/a/sub v0.1.0
+	gopkg.in/yaml.v3 v3.0.1
+	golang.org/x/sync v0.19.0 // indirect
+	github.com/o/missing v1.0.0
+)
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(fixtures, "repos.yaml"), []byte("gopkg.in/yaml.v3: https://github.com/go-yaml/yaml\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(fixtures, "github.com_o_a.json"),
+		[]byte(`{"schema_version":"1.2.0","repo":"github.com/o/a","total_commits":3,"total_contributors":1,"contributors":[{"username":"alice","reputation":0.8}]}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(fixtures, "github.com_go-yaml_yaml.yaml"),
+		[]byte("schemaVersion: 1.2.0\nrepo: github.com/go-yaml/yaml\ncontributors:\n- username: bob\n  reputation: 0.3\n"), 0o600))
+
+	out := filepath.Join(dir, "deps.json")
+	err := ScanDependencies(context.Background(), &ScanDependenciesOptions{
+		Manifests:  []string{manifest},
+		Fixtures:   fixtures,
+		DirectOnly: true,
+		FailBelow:  0.5,
+		Format:     "json",
+		File:       out,
+	})
+	require.ErrorIs(t, err, ErrBelowThreshold)
+	assert.ErrorContains(t, err, "1 of 4 dependencies below 0.50: gopkg.in/yaml.v3")
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	var d deps.Report
+	require.NoError(t, json.Unmarshal(b, &d))
+
+	assert.Equal(t, &deps.Summary{
+		Dependencies:  4,
+		Repos:         2,
+		Unscored:      1,
+		LowReputation: 1,
+		Tiers:         map[string]int{"high": 2, "low": 1},
+	}, d.Summary)
+	require.Len(t, d.Dependencies, 4)
+	assert.Equal(t, "github.com/o/a", d.Dependencies[1].Repo, "modules of one repo share its report")
+	assert.Contains(t, d.Dependencies[3].Error, "no fixture report for github.com/o/missing")
+
+	zero := 0.0
+	require.NoError(t, ScanDependencies(context.Background(), &ScanDependenciesOptions{
+		Manifests:     []string{manifest},
+		Fixtures:      fixtures,
+		RiskThreshold: &zero,
+		Format:        "json",
+		File:          out,
+	}))
+	b, err = os.ReadFile(out)
+	require.NoError(t, err)
+	d = deps.Report{}
+	require.NoError(t, json.Unmarshal(b, &d))
+	assert.Zero(t, d.RiskThreshold, "a zero threshold is not replaced by the default")
+	assert.Zero(t, d.Summary.LowReputation)
+}
+
+func TestScanDependenciesInvalid(t *testing.T) {
+	require.Error(t, ScanDependencies(context.Background(), nil))
+
+	err := ScanDependencies(context.Background(), &ScanDependenciesOptions{Manifests: []string{"Cargo.toml"}})
+	require.ErrorContains(t, err, "error reading manifest")
+}
@@ -1,5 +1,6 @@
 // Package reporter orchestrates reputation report generation
 // by validating options, building queries, and delegating to
-// the appropriate provider backend. Output is encoded as JSON
-// or YAML based on the configured format.
+// the appropriate provider backend. Output is encoded as JSON,
+// YAML, SARIF, CSV, or TSV, or rendered from a Markdown or HTML
+// template, based on the configured format.
 package reporter
@@ -3,16 +3,115 @@ package reporter
 import (
 	"errors"
 	"fmt"
+	"strconv"
+
+	"github.com/mchmarny/reputer/pkg/render"
+	"github.com/mchmarny/reputer/pkg/report"
 )
 
 // ListCommitAuthorsOptions configures a reputation report query.
 type ListCommitAuthorsOptions struct {
-	Repo        string
+	Repo string
+	// Repos are scanned along with Repo, and Reports are saved reports
+	// (with stats) included as is. With more than one repo, or any saved
+	// report, the contributors are combined into one org-wide report.
+	Repos   []string
+	Reports []string
+	// User scores a single account instead of every commit author; Repo is
+	// then optional and adds repo-relative signals.
+	User string
+	// PullRequest scores the opener and the commit authors and co-authors
+	// of this pull request in Repo instead of every commit author.
+	PullRequest int
+	// ReposFile lists repos, one per line ("-" reads stdin), each scanned
+	// into its own report: written to OutputDir, or without it streamed as
+	// NDJSON. Concurrency bounds how many repos are scanned at once.
+	ReposFile   string
+	OutputDir   string
+	Concurrency int
+	// Org, as host/org, scans every repo of the organization or group that
+	// passes RepoFilter like a repos file, and adds an org summary
+	// combining them.
+	Org        string
+	RepoFilter report.RepoFilter
+
 	Commit      string
 	Stats       bool
+	Explain     bool
 	File        string
 	Format      string
+	Template    string
+	Columns     []string
 	TrustedOrgs []string
+
+	// DormancyDays and ReactivationWindowDays override the query defaults
+	// when set. A DormancyDays of 0 disables reactivation detection.
+	DormancyDays           *int64
+	ReactivationWindowDays *int64
+
+	Sensitive      bool
+	SensitivePaths []string
+
+	Model         string
+	ModelVersion  string
+	CompareModels []string
+
+	Policy string
+
+	// RiskThreshold overrides report.DefaultRiskThreshold when set.
+	RiskThreshold *float64
+
+	AsOf string
+
+	Trust     string
+	RepoTrust bool
+
+	SigningKey string
+
+	Tiers          string
+	FailBelow      float64
+	FailIfAnyBelow bool
+
+	Sort     string
+	Top      int
+	MinScore *float64
+	MaxScore *float64
+	Authors  []string
+}
+
+// selection returns the contributor selection, or nil when none is set.
+func (l *ListCommitAuthorsOptions) selection() *report.Selection {
+	if l.Sort == "" && l.Top == 0 && l.MinScore == nil && l.MaxScore == nil && len(l.Authors) == 0 {
+		return nil
+	}
+	return &report.Selection{
+		Sort:     l.Sort,
+		Top:      l.Top,
+		MinScore: l.MinScore,
+		MaxScore: l.MaxScore,
+		Authors:  l.Authors,
+	}
+}
+
+// repos returns the repos to scan, Repo first.
+func (l *ListCommitAuthorsOptions) repos() []string {
+	var list []string
+	if l.Repo != "" {
+		list = append(list, l.Repo)
+	}
+	return append(list, l.Repos...)
+}
+
+// combined reports whether the output is a combined report: of several
+// repos, or including saved reports.
+func (l *ListCommitAuthorsOptions) combined() bool {
+	return len(l.repos()) > 1 || len(l.Reports) > 0
+}
+
+// batch reports whether each repo is scanned into its own report: of a
+// repos file or an org.
+func (l *ListCommitAuthorsOptions) batch() bool {
+	return l.ReposFile != "" || l.Org != ""
 }
 
 // Validate checks that required fields are populated.
@@ -21,14 +120,143 @@ func (l *ListCommitAuthorsOptions) Validate() error {
 		return errors.New("options must be populated")
 	}
 
-	if l.Repo == "" {
+	if l.ReposFile != "" && l.Org != "" {
+		return errors.New("repos file and org are mutually exclusive")
+	}
+	if l.Org != "" {
+		if _, _, err := report.ParseOrg(l.Org); err != nil {
+			return err
+		}
+		if err := l.RepoFilter.Validate(); err != nil {
+			return err
+		}
+	}
+	if l.batch() {
+		if len(l.repos()) > 0 || len(l.Reports) > 0 {
+			return errors.New("repos file or org cannot be combined with repo or aggregate")
+		}
+		if l.User != "" || l.PullRequest != 0 {
+			return errors.New("repos file or org is not supported for user or pull request lookups")
+		}
+		if l.Commit != "" {
+			return errors.New("commit is not supported for batch reports")
+		}
+		if l.OutputDir == "" && l.Format != "" && l.Format != "json" {
+			return fmt.Errorf("batch output without an output dir is NDJSON and does not support format %s", l.Format)
+		}
+	} else if l.OutputDir != "" {
+		return errors.New("output dir requires a repos file or org")
+	}
+	if l.Concurrency < 0 {
+		return fmt.Errorf("invalid concurrency: %d", l.Concurrency)
+	}
+
+	if l.User != "" {
+		if len(l.Repos) > 0 || len(l.Reports) > 0 {
+			return errors.New("user lookup supports at most one repo")
+		}
+		if l.Commit != "" && l.Repo == "" {
+			return errors.New("commit requires a repo")
+		}
+	} else if len(l.repos()) == 0 && len(l.Reports) == 0 && !l.batch() {
 		return errors.New("repo must be specified")
 	}
 
+	if l.PullRequest < 0 {
+		return fmt.Errorf("invalid pull request number: %d", l.PullRequest)
+	}
+	if l.PullRequest > 0 {
+		if l.Repo == "" || len(l.Repos) > 0 || len(l.Reports) > 0 {
+			return errors.New("pull request requires exactly one repo")
+		}
+		if l.User != "" {
+			return errors.New("pull request and user lookup are mutually exclusive")
+		}
+		if l.Commit != "" {
+			return errors.New("commit is not supported for pull requests")
+		}
+	}
+
+	if l.combined() {
+		if l.Commit != "" {
+			return errors.New("commit is not supported for combined reports")
+		}
+		if l.Format == "attestation" {
+			return errors.New("attestation format is not supported for combined reports")
+		}
+		if len(l.CompareModels) > 0 {
+			return errors.New("model comparison is not supported for combined reports")
+		}
+	}
+
 	switch l.Format {
-	case "", "json", "yaml":
+	case "", "json", "yaml", "sarif", "markdown", "html", "csv", "tsv", "attestation":
 	default:
-		return fmt.Errorf("unsupported format: %s (must be json or yaml)", l.Format)
+		return fmt.Errorf("unsupported format: %s (must be json, yaml, sarif, markdown, html, csv, tsv, or attestation)", l.Format)
+	}
+
+	if l.Format == "attestation" {
+		if l.SigningKey == "" {
+			return errors.New("attestation format requires a signing key")
+		}
+		if l.Commit == "" {
+			return errors.New("attestation format requires a commit")
+		}
+	} else if l.SigningKey != "" {
+		return errors.New("signing key requires attestation format")
+	}
+
+	if l.Template != "" && l.Format != "markdown" && l.Format != "html" {
+		return errors.New("template requires markdown or html format")
+	}
+
+	if len(l.Columns) > 0 {
+		if l.Format != "csv" && l.Format != "tsv" {
+			return errors.New("columns require csv or tsv format")
+		}
+		if err := render.CheckColumns(l.Columns); err != nil {
+			return err
+		}
+	}
+
+	if l.DormancyDays != nil && *l.DormancyDays < 0 {
+		return errors.New("dormancy days must be non-negative")
+	}
+
+	if l.ReactivationWindowDays != nil && *l.ReactivationWindowDays < 0 {
+		return errors.New("reactivation window must be non-negative")
+	}
+
+	if l.RiskThreshold != nil && (*l.RiskThreshold < 0 || *l.RiskThreshold > 1) {
+		return errors.New("risk threshold must be in [0, 1]")
+	}
+
+	if l.Tiers != "" {
+		if _, err := report.ParseTiers(l.Tiers); err != nil {
+			return err
+		}
+	}
+
+	if err := l.selection().Validate(); err != nil {
+		return err
+	}
+
+	if l.FailBelow < 0 || l.FailBelow > 1 {
+		return errors.New("fail-below score must be in [0, 1]")
+	}
+
+	if l.FailIfAnyBelow && l.FailBelow == 0 {
+		return errors.New("fail-if-any-below requires a fail-below score")
+	}
+
+	if l.AsOf != "" {
+		if _, err := report.ParseAsOf(l.AsOf); err != nil {
+			return err
+		}
+	}
+
+	if l.Model != "" && l.ModelVersion != "" {
+		return errors.New("model and model version are mutually exclusive")
 	}
 
 	if l.Format == "" {
@@ -39,6 +267,181 @@ func (l *ListCommitAuthorsOptions) Validate() error {
 }
 
 func (l *ListCommitAuthorsOptions) String() string {
-	return fmt.Sprintf("repo: %s, commit: %s, stats: %t, file: %s, format: %s, trusted_orgs: %v",
-		l.Repo, l.Commit, l.Stats, l.File, l.Format, l.TrustedOrgs)
+	return fmt.Sprintf("repo: %s, repos: %v, reports: %v, user: %s, pull_request: %d, repos_file: %s, output_dir: %s, concurrency: %d, org: %s, repo_filter: %+v, commit: %s, stats: %t, explain: %t, file: %s, format: %s, template: %s, columns: %v, trusted_orgs: %v, dormancy_days: %s, reactivation_window: %s, sensitive: %t, sensitive_paths: %v, model: %s, model_version: %s, compare_models: %v, policy: %s, risk_threshold: %s, as_of: %s, trust: %s, repo_trust: %t, signing_key: %s, tiers: %s, fail_below: %.2f, fail_if_any_below: %t, sort: %s, top: %d, min_score: %s, max_score: %s, authors: %v",
+		l.Repo, l.Repos, l.Reports, l.User, l.PullRequest, l.ReposFile, l.OutputDir, l.Concurrency, l.Org, l.RepoFilter, l.Commit, l.Stats, l.Explain, l.File, l.Format, l.Template, l.Columns, l.TrustedOrgs, formatDays(l.DormancyDays), formatDays(l.ReactivationWindowDays),
+		l.Sensitive, l.SensitivePaths, l.Model, l.ModelVersion, l.CompareModels, l.Policy, formatThreshold(l.RiskThreshold), l.AsOf, l.Trust, l.RepoTrust, l.SigningKey, l.Tiers, l.FailBelow, l.FailIfAnyBelow,
+		l.Sort, l.Top, formatBound(l.MinScore), formatBound(l.MaxScore), l.Authors)
+}
+
+// formatBound formats an optional score bound.
+func formatBound(b *float64) string {
+	if b == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%.2f", *b)
+}
+
+// formatThreshold formats an optional risk threshold.
+func formatThreshold(t *float64) string {
+	if t == nil {
+		return "default"
+	}
+	return fmt.Sprintf("%.2f", *t)
+}
+
+// formatDays formats an optional day count.
+func formatDays(d *int64) string {
+	if d == nil {
+		return "default"
+	}
+	return strconv.FormatInt(*d, 10)
+}
+
+// VerifyAttestationOptions configures attestation verification.
+type VerifyAttestationOptions struct {
+	// Envelope is the path of the DSSE envelope to verify.
+	Envelope string
+	// Key is the path of the PEM public key the envelope must be signed with.
+	Key string
+	// File receives the verified report as JSON (optional, stdout if not specified).
+	File string
+}
+
+// Validate checks that required fields are populated.
+func (v *VerifyAttestationOptions) Validate() error {
+	if v == nil {
+		return errors.New("options must be populated")
+	}
+
+	if v.Envelope == "" {
+		return errors.New("envelope must be specified")
+	}
+
+	if v.Key == "" {
+		return errors.New("key must be specified")
+	}
+
+	return nil
+}
+
+func (v *VerifyAttestationOptions) String() string {
+	return fmt.Sprintf("envelope: %s, key: %s, file: %s", v.Envelope, v.Key, v.File)
+}
+
+// DiffReportsOptions configures a comparison of two reports.
+type DiffReportsOptions struct {
+	// Old and New are the paths of the JSON or YAML reports to compare.
+	Old string
+	New string
+	// Threshold is the minimum absolute reputation change reported.
+	Threshold float64
+	// Format is text, json, or markdown (default: text).
+	Format string
+	// File receives the diff (optional, stdout if not specified).
+	File string
+}
+
+// Validate checks that required fields are populated.
+func (d *DiffReportsOptions) Validate() error {
+	if d == nil {
+		return errors.New("options must be populated")
+	}
+
+	if d.Old == "" || d.New == "" {
+		return errors.New("old and new reports must be specified")
+	}
+
+	if d.Threshold < 0 || d.Threshold > 1 {
+		return errors.New("threshold must be in [0, 1]")
+	}
+
+	switch d.Format {
+	case "", "text", "json", "markdown":
+	default:
+		return fmt.Errorf("unsupported format: %s (must be text, json, or markdown)", d.Format)
+	}
+
+	if d.Format == "" {
+		d.Format = "text"
+	}
+
+	return nil
+}
+
+func (d *DiffReportsOptions) String() string {
+	return fmt.Sprintf("old: %s, new: %s, threshold: %.2f, format: %s, file: %s", d.Old, d.New, d.Threshold, d.Format, d.File)
+}
+
+// ScanDependenciesOptions configures a dependency scan.
+type ScanDependenciesOptions struct {
+	// Manifests are the go.mod, package.json, or requirements*.txt files
+	// whose dependencies are scanned.
+	Manifests []string
+	// DirectOnly skips Go modules marked indirect.
+	DirectOnly bool
+	// Fixtures is a directory that replaces the network: repos.yaml (or
+	// repos.json) maps dependency names to repos, and each repo's report is
+	// read from a file named as in batch mode, e.g. github.com_owner_name.json.
+	Fixtures string
+	// Concurrency bounds how many dependencies are resolved and repos
+	// scanned at once.
+	Concurrency int
+	TrustedOrgs []string
+	// RiskThreshold is the repo reputation below which a dependency counts
+	// as low-reputation (optional, default: report.DefaultRiskThreshold).
+	RiskThreshold *float64
+	Tiers         string
+	// FailBelow fails the scan when any scored dependency's repo reputation
+	// is below this score.
+	FailBelow float64
+	// Format is text, json, yaml, or markdown (default: text).
+	Format string
+	// File receives the report (optional, stdout if not specified).
+	File string
+}
+
+// Validate checks that required fields are populated.
+func (s *ScanDependenciesOptions) Validate() error {
+	if s == nil {
+		return errors.New("options must be populated")
+	}
+
+	if len(s.Manifests) == 0 {
+		return errors.New("at least one manifest must be specified")
+	}
+
+	if s.Concurrency < 0 {
+		return fmt.Errorf("invalid concurrency: %d", s.Concurrency)
+	}
+
+	if s.RiskThreshold != nil && (*s.RiskThreshold < 0 || *s.RiskThreshold > 1) {
+		return errors.New("risk threshold must be in [0, 1]")
+	}
+
+	if s.Tiers != "" {
+		if _, err := report.ParseTiers(s.Tiers); err != nil {
+			return err
+		}
+	}
+
+	if s.FailBelow < 0 || s.FailBelow > 1 {
+		return errors.New("fail-below score must be in [0, 1]")
+	}
+
+	switch s.Format {
+	case "", "text", "json", "yaml", "markdown":
+	default:
+		return fmt.Errorf("unsupported format: %s (must be text, json, yaml, or markdown)", s.Format)
+	}
+
+	if s.Format == "" {
+		s.Format = "text"
+	}
+
+	return nil
+}
+
+func (s *ScanDependenciesOptions) String() string {
+	return fmt.Sprintf("manifests: %v, direct_only: %t, fixtures: %s, concurrency: %d, trusted_orgs: %v, risk_threshold: %s, tiers: %s, fail_below: %.2f, format: %s, file: %s",
+		s.Manifests, s.DirectOnly, s.Fixtures, s.Concurrency, s.TrustedOrgs, formatThreshold(s.RiskThreshold), s.Tiers, s.FailBelow, s.Format, s.File)
 }
@@ -3,6 +3,7 @@ package reporter
 import (
 	"testing"
 
+	"github.com/mchmarny/reputer/pkg/report"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,6 +28,56 @@ func TestValidateValid(t *testing.T) {
 	assert.Equal(t, "json", o.Format)
 }
 
+func TestValidateNegativeReactivation(t *testing.T) {
+	neg, zero := int64(-1), int64(0)
+
+	o := &ListCommitAuthorsOptions{Repo: "github.com/o/r", DormancyDays: &neg}
+	err := o.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dormancy days")
+
+	o = &ListCommitAuthorsOptions{Repo: "github.com/o/r", ReactivationWindowDays: &neg}
+	err = o.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reactivation window")
+
+	o = &ListCommitAuthorsOptions{Repo: "github.com/o/r", DormancyDays: &zero, ReactivationWindowDays: &zero}
+	require.NoError(t, o.Validate(), "zero disables detection")
+	assert.Contains(t, o.String(), "dormancy_days: 0, reactivation_window: 0")
+}
+
+func TestValidateRiskThreshold(t *testing.T) {
+	for _, v := range []float64{-0.1, 1.1} {
+		o := &ListCommitAuthorsOptions{Repo: "github.com/o/r", RiskThreshold: &v}
+		err := o.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "risk threshold")
+	}
+
+	zero := 0.0
+	o := &ListCommitAuthorsOptions{Repo: "github.com/o/r", RiskThreshold: &zero}
+	require.NoError(t, o.Validate())
+	assert.Contains(t, o.String(), "risk_threshold: 0.00")
+	assert.Contains(t, (&ListCommitAuthorsOptions{}).String(), "risk_threshold: default")
+}
+
+func TestValidateAsOf(t *testing.T) {
+	o := &ListCommitAuthorsOptions{Repo: "github.com/o/r", AsOf: "2024-01-15"}
+	require.NoError(t, o.Validate())
+
+	o = &ListCommitAuthorsOptions{Repo: "github.com/o/r", AsOf: "yesterday"}
+	err := o.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "as-of")
+}
+
+func TestValidateModelExclusive(t *testing.T) {
+	o := &ListCommitAuthorsOptions{Repo: "github.com/o/r", Model: "m.yaml", ModelVersion: "3.2.0"}
+	err := o.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mutually exclusive")
+}
+
 func TestValidateFormat(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -37,6 +88,11 @@ func TestValidateFormat(t *testing.T) {
 		{name: "empty defaults to json", format: "", wantFmt: "json"},
 		{name: "explicit json", format: "json", wantFmt: "json"},
 		{name: "explicit yaml", format: "yaml", wantFmt: "yaml"},
+		{name: "explicit sarif", format: "sarif", wantFmt: "sarif"},
+		{name: "explicit markdown", format: "markdown", wantFmt: "markdown"},
+		{name: "explicit html", format: "html", wantFmt: "html"},
+		{name: "explicit csv", format: "csv", wantFmt: "csv"},
+		{name: "explicit tsv", format: "tsv", wantFmt: "tsv"},
 		{name: "unsupported format", format: "xml", wantErr: true},
 	}
 
@@ -55,6 +111,128 @@ func TestValidateFormat(t *testing.T) {
 	}
 }
 
+func TestValidateTemplate(t *testing.T) {
+	o := &ListCommitAuthorsOptions{Repo: "github.com/o/r", Format: "json", Template: "t.tmpl"}
+	err := o.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "template requires")
+
+	o.Format = "markdown"
+	require.NoError(t, o.Validate())
+}
+
+func TestValidateColumns(t *testing.T) {
+	o := &ListCommitAuthorsOptions{Repo: "github.com/o/r", Format: "json", Columns: []string{"username"}}
+	err := o.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "columns require")
+
+	o.Format = "csv"
+	require.NoError(t, o.Validate())
+
+	o.Columns = []string{"username", "nope"}
+	err = o.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown column")
+}
+
+func TestValidateAttestation(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     ListCommitAuthorsOptions
+		wantErr string
+	}{
+		{name: "valid", opt: ListCommitAuthorsOptions{Format: "attestation", SigningKey: "k.pem", Commit: "abc"}},
+		{name: "no key", opt: ListCommitAuthorsOptions{Format: "attestation", Commit: "abc"}, wantErr: "requires a signing key"},
+		{name: "no commit", opt: ListCommitAuthorsOptions{Format: "attestation", SigningKey: "k.pem"}, wantErr: "requires a commit"},
+		{name: "key without attestation", opt: ListCommitAuthorsOptions{Format: "json", SigningKey: "k.pem"}, wantErr: "requires attestation format"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opt.Repo = "github.com/o/r"
+			err := tc.opt.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestValidateVerifyAttestation(t *testing.T) {
+	var nilOpt *VerifyAttestationOptions
+	require.Error(t, nilOpt.Validate())
+	require.Error(t, (&VerifyAttestationOptions{Key: "k.pub"}).Validate())
+	require.Error(t, (&VerifyAttestationOptions{Envelope: "e.json"}).Validate())
+	require.NoError(t, (&VerifyAttestationOptions{Envelope: "e.json", Key: "k.pub"}).Validate())
+}
+
+func TestValidateDiffReports(t *testing.T) {
+	o := &DiffReportsOptions{Old: "a.json", New: "b.json"}
+	require.NoError(t, o.Validate())
+	assert.Equal(t, "text", o.Format)
+
+	require.Error(t, (&DiffReportsOptions{Old: "a.json"}).Validate())
+	require.Error(t, (&DiffReportsOptions{Old: "a.json", New: "b.json", Threshold: 2}).Validate())
+
+	err := (&DiffReportsOptions{Old: "a.json", New: "b.json", Format: "yaml"}).Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format")
+}
+
+func TestValidateGate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     ListCommitAuthorsOptions
+		wantErr string
+	}{
+		{name: "fail below", opt: ListCommitAuthorsOptions{FailBelow: 0.5}},
+		{name: "fail if any below", opt: ListCommitAuthorsOptions{FailBelow: 0.5, FailIfAnyBelow: true}},
+		{name: "out of range", opt: ListCommitAuthorsOptions{FailBelow: 1.5}, wantErr: "must be in [0, 1]"},
+		{name: "any without score", opt: ListCommitAuthorsOptions{FailIfAnyBelow: true}, wantErr: "requires a fail-below score"},
+		{name: "tiers", opt: ListCommitAuthorsOptions{Tiers: "ok=0.5,review=0"}},
+		{name: "bad tiers", opt: ListCommitAuthorsOptions{Tiers: "ok=0.5"}, wantErr: "minimum of 0"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opt.Repo = "github.com/o/r"
+			err := tc.opt.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestValidateSelection(t *testing.T) {
+	lo, hi := 0.6, 0.4
+
+	o := &ListCommitAuthorsOptions{Repo: "github.com/o/r"}
+	assert.Nil(t, o.selection())
+
+	o.Sort = "reputation:desc"
+	o.Top = 10
+	o.Authors = []string{"dependabot*"}
+	require.NoError(t, o.Validate())
+	require.NotNil(t, o.selection())
+	assert.Equal(t, 10, o.selection().Top)
+
+	o.Sort = "stars"
+	assert.ErrorContains(t, o.Validate(), "invalid sort key")
+
+	o.Sort = ""
+	o.MinScore, o.MaxScore = &lo, &hi
+	assert.ErrorContains(t, o.Validate(), "min score")
+	assert.Contains(t, o.String(), "min_score: 0.60")
+}
+
 func TestOptionsString(t *testing.T) {
 	o := &ListCommitAuthorsOptions{
 		Repo:   "github.com/o/r",
@@ -70,3 +248,129 @@ func TestOptionsString(t *testing.T) {
 	assert.Contains(t, s, "out.json")
 	assert.Contains(t, s, "yaml")
 }
+
+func TestValidateCombined(t *testing.T) {
+	o := &ListCommitAuthorsOptions{Reports: []string{"a.json"}}
+	require.NoError(t, o.Validate())
+	assert.True(t, o.combined())
+
+	o = &ListCommitAuthorsOptions{Repo: "github.com/o/a", Repos: []string{"github.com/o/b"}}
+	require.NoError(t, o.Validate())
+	assert.Equal(t, []string{"github.com/o/a", "github.com/o/b"}, o.repos())
+	assert.True(t, o.combined())
+	assert.Contains(t, o.String(), "repos: [github.com/o/b]")
+
+	assert.False(t, (&ListCommitAuthorsOptions{Repo: "github.com/o/a"}).combined())
+
+	o.Commit = "abc"
+	assert.ErrorContains(t, o.Validate(), "commit is not supported")
+
+	o.Commit = ""
+	o.CompareModels = []string{"3.1.0"}
+	assert.ErrorContains(t, o.Validate(), "model comparison is not supported")
+}
+
+func TestValidateUser(t *testing.T) {
+	o := &ListCommitAuthorsOptions{User: "octocat"}
+	require.NoError(t, o.Validate())
+	assert.Contains(t, o.String(), "user: octocat")
+
+	o.Repo = "github.com/o/a"
+	require.NoError(t, o.Validate())
+
+	o.Repos = []string{"github.com/o/b"}
+	assert.ErrorContains(t, o.Validate(), "at most one repo")
+
+	o = &ListCommitAuthorsOptions{User: "octocat", Commit: "abc"}
+	assert.ErrorContains(t, o.Validate(), "commit requires a repo")
+}
+
+func TestValidatePullRequest(t *testing.T) {
+	o := &ListCommitAuthorsOptions{Repo: "github.com/o/a", PullRequest: 3}
+	require.NoError(t, o.Validate())
+	assert.Contains(t, o.String(), "pull_request: 3")
+
+	o.Repos = []string{"github.com/o/b"}
+	assert.ErrorContains(t, o.Validate(), "exactly one repo")
+
+	o = &ListCommitAuthorsOptions{Repo: "github.com/o/a", PullRequest: 3, User: "u"}
+	assert.ErrorContains(t, o.Validate(), "mutually exclusive")
+
+	o = &ListCommitAuthorsOptions{Repo: "github.com/o/a", PullRequest: 3, Commit: "abc"}
+	assert.ErrorContains(t, o.Validate(), "commit is not supported")
+
+	o = &ListCommitAuthorsOptions{Repo: "github.com/o/a", PullRequest: -1}
+	assert.ErrorContains(t, o.Validate(), "invalid pull request number")
+}
+
+func TestValidateBatch(t *testing.T) {
+	o := &ListCommitAuthorsOptions{ReposFile: "repos.txt"}
+	require.NoError(t, o.Validate())
+	assert.Contains(t, o.String(), "repos_file: repos.txt")
+
+	o.Format = "csv"
+	assert.ErrorContains(t, o.Validate(), "NDJSON")
+	o.OutputDir = "out"
+	require.NoError(t, o.Validate())
+
+	o.Repo = "github.com/o/a"
+	assert.ErrorContains(t, o.Validate(), "cannot be combined")
+
+	o = &ListCommitAuthorsOptions{ReposFile: "repos.txt", User: "u"}
+	assert.ErrorContains(t, o.Validate(), "not supported for user")
+
+	o = &ListCommitAuthorsOptions{ReposFile: "repos.txt", Commit: "abc"}
+	assert.ErrorContains(t, o.Validate(), "commit is not supported")
+
+	o = &ListCommitAuthorsOptions{ReposFile: "repos.txt", Concurrency: -1}
+	assert.ErrorContains(t, o.Validate(), "invalid concurrency")
+
+	o = &ListCommitAuthorsOptions{Repo: "github.com/o/a", OutputDir: "out"}
+	assert.ErrorContains(t, o.Validate(), "requires a repos file")
+}
+
+func TestValidateOrg(t *testing.T) {
+	o := &ListCommitAuthorsOptions{Org: "github.com/o", OutputDir: "out", Format: "markdown"}
+	require.NoError(t, o.Validate())
+	assert.Contains(t, o.String(), "org: github.com/o")
+
+	o = &ListCommitAuthorsOptions{Org: "github.com/o/a"}
+	assert.ErrorContains(t, o.Validate(), "invalid org")
+
+	o = &ListCommitAuthorsOptions{Org: "github.com/o", RepoFilter: report.RepoFilter{Visibility: "secret"}}
+	assert.ErrorContains(t, o.Validate(), "invalid visibility")
+
+	o = &ListCommitAuthorsOptions{Org: "github.com/o", ReposFile: "repos.txt"}
+	assert.ErrorContains(t, o.Validate(), "mutually exclusive")
+
+	o = &ListCommitAuthorsOptions{Org: "github.com/o", Repo: "github.com/o/a"}
+	assert.ErrorContains(t, o.Validate(), "cannot be combined")
+
+	o = &ListCommitAuthorsOptions{Org: "github.com/o", Format: "yaml"}
+	assert.ErrorContains(t, o.Validate(), "NDJSON")
+}
+
+func TestValidateScanDependencies(t *testing.T) {
+	var o *ScanDependenciesOptions
+	require.ErrorContains(t, o.Validate(), "options must be populated")
+
+	o = &ScanDependenciesOptions{}
+	require.ErrorContains(t, o.Validate(), "at least one manifest")
+
+	o = &ScanDependenciesOptions{Manifests: []string{"go.mod"}}
+	require.NoError(t, o.Validate())
+	assert.Equal(t, "text", o.Format)
+	assert.Contains(t, o.String(), "manifests: [go.mod]")
+
+	o.Format = "sarif"
+	require.ErrorContains(t, o.Validate(), "unsupported format")
+
+	o = &ScanDependenciesOptions{Manifests: []string{"go.mod"}, Tiers: "high=0.7"}
+	require.ErrorContains(t, o.Validate(), "minimum of 0")
+
+	o = &ScanDependenciesOptions{Manifests: []string{"go.mod"}, FailBelow: 2}
+	require.ErrorContains(t, o.Validate(), "fail-below")
+
+	o = &ScanDependenciesOptions{Manifests: []string{"go.mod"}, Concurrency: -1}
+	require.ErrorContains(t, o.Validate(), "invalid concurrency")
+}
@@ -2,17 +2,43 @@ package reporter
 
 import (
 	"context"
+	"crypto"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"reflect"
+	"strings"
+	"time"
 
+	"github.com/mchmarny/reputer/pkg/attest"
+	"github.com/mchmarny/reputer/pkg/cache"
+	"github.com/mchmarny/reputer/pkg/diff"
+	"github.com/mchmarny/reputer/pkg/policy"
 	"github.com/mchmarny/reputer/pkg/provider"
+	"github.com/mchmarny/reputer/pkg/render"
 	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/mchmarny/reputer/pkg/schema"
+	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/mchmarny/reputer/pkg/trust"
 	"gopkg.in/yaml.v3"
 )
 
-// ListCommitAuthors returns a list of authors for the given repo and commit.
+// ErrBelowThreshold is returned, after the report is written, when the
+// report fails the configured fail-below gate.
+var ErrBelowThreshold = errors.New("reputation below threshold")
+
+// accountTTL bounds how long a run reuses an account lookup. Runs rarely
+// last that long, so each account is fetched once per run.
+const accountTTL = time.Hour
+
+// ListCommitAuthors returns a list of authors for the given repo and commit,
+// with a user set, the report of that single user, or with a pull request
+// set, the report of its opener and commit authors.
+// When a fail-below score is set and the report falls below it, the report
+// is still written and an error wrapping [ErrBelowThreshold] is returned.
 func ListCommitAuthors(ctx context.Context, opt *ListCommitAuthorsOptions) (retErr error) {
 	if opt == nil {
 		return errors.New("options must be specified")
@@ -22,16 +48,23 @@ func ListCommitAuthors(ctx context.Context, opt *ListCommitAuthorsOptions) (retE
 		return fmt.Errorf("invalid options: %w", err)
 	}
 
-	q, err := report.MakeQuery(opt.Repo, opt.Commit, opt.Stats)
+	rr, err := newReportRun(opt)
 	if err != nil {
-		return fmt.Errorf("error creating query for %s: %w", opt, err)
+		return err
 	}
 
-	q.TrustedOrgs = opt.TrustedOrgs
+	if opt.batch() {
+		return runBatch(ctx, rr)
+	}
+
+	r, err := rr.fetch(ctx)
+	if err != nil {
+		return err
+	}
 
-	r, err := provider.GetAuthors(ctx, *q)
+	gateErr, err := rr.finish(r)
 	if err != nil {
-		return fmt.Errorf("error listing authors for %s: %w", opt, err)
+		return err
 	}
 
 	f := os.Stdout
@@ -47,15 +80,461 @@ func ListCommitAuthors(ctx context.Context, opt *ListCommitAuthorsOptions) (retE
 		}()
 	}
 
+	if err := rr.write(f, r); err != nil {
+		return err
+	}
+
+	return gateErr
+}
+
+// reportRun holds the settings shared by the reports of one invocation.
+type reportRun struct {
+	opt *ListCommitAuthorsOptions
+	q   *report.Query
+	sel *report.Selection
+	// stats is set when the output shows author stats; withStats when they
+	// are collected, possibly only for selection or combining.
+	stats     bool
+	withStats bool
+	tiers     []report.Tier
+	signer    crypto.Signer
+	tmpl      string
+}
+
+// newReportRun resolves the options into the query and output settings.
+func newReportRun(opt *ListCommitAuthorsOptions) (*reportRun, error) {
+	var err error
+
+	// Markdown and HTML show collapsible per-author stats; CSV and TSV
+	// flatten them into columns.
+	var stats bool
 	switch opt.Format {
+	case "markdown", "html", "csv", "tsv":
+		stats = true
+	default:
+		stats = opt.Stats
+	}
+
+	// Sorting by commits, last commit, or age, and combining repos, read
+	// author stats, which are stripped again after selection when they were
+	// not requested.
+	sel := opt.selection()
+	combined := opt.combined() || opt.Org != ""
+	withStats := stats || sel.NeedsStats() || combined
+
+	q := report.DefaultQuery(withStats)
+	if repos := opt.repos(); len(repos) > 0 {
+		if q, err = report.MakeQuery(repos[0], opt.Commit, withStats); err != nil {
+			return nil, fmt.Errorf("error creating query for %s: %w", opt, err)
+		}
+	}
+	if opt.User != "" {
+		if q, err = q.ForUser(opt.User); err != nil {
+			return nil, fmt.Errorf("error creating query for %s: %w", opt, err)
+		}
+	}
+	q.PullRequest = opt.PullRequest
+	q.Accounts = cache.New(accountTTL)
+
+	// SARIF results name the weakest signal, which needs the breakdown.
+	q.Explain = opt.Explain || opt.Format == "sarif"
+	q.TrustedOrgs = opt.TrustedOrgs
+
+	if opt.DormancyDays != nil {
+		q.DormancyDays = *opt.DormancyDays
+	}
+
+	if opt.ReactivationWindowDays != nil {
+		q.ReactivationWindowDays = *opt.ReactivationWindowDays
+	}
+
+	if opt.RiskThreshold != nil {
+		q.RiskThreshold = *opt.RiskThreshold
+	}
+
+	if opt.AsOf != "" {
+		if q.AsOf, err = report.ParseAsOf(opt.AsOf); err != nil {
+			return nil, fmt.Errorf("error parsing as-of time for %s: %w", opt, err)
+		}
+	}
+
+	switch {
+	case len(opt.SensitivePaths) > 0:
+		q.SensitivePaths = opt.SensitivePaths
+	case opt.Sensitive:
+		q.SensitivePaths = report.DefaultSensitivePaths
+	}
+
+	switch {
+	case opt.Model != "":
+		m, err := score.LoadModel(opt.Model)
+		if err != nil {
+			return nil, fmt.Errorf("error loading model for %s: %w", opt, err)
+		}
+		q.Model = m
+	case opt.ModelVersion != "":
+		m, err := score.GetModel(opt.ModelVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error loading model for %s: %w", opt, err)
+		}
+		q.Model = m
+	}
+
+	for _, ref := range opt.CompareModels {
+		m, err := score.ResolveModel(ref)
+		if err != nil {
+			return nil, fmt.Errorf("error loading comparison model for %s: %w", opt, err)
+		}
+		q.CompareModels = append(q.CompareModels, m)
+	}
+
+	if opt.Trust != "" {
+		c, err := trust.Load(opt.Trust)
+		if err != nil {
+			return nil, fmt.Errorf("error loading trust file for %s: %w", opt, err)
+		}
+		q.Trust = c
+	}
+	q.RepoTrust = opt.RepoTrust
+
+	if opt.Policy != "" {
+		p, err := policy.Load(opt.Policy, reflect.TypeFor[report.Stats]())
+		if err != nil {
+			return nil, fmt.Errorf("error loading policy for %s: %w", opt, err)
+		}
+		q.Policy = p
+	}
+
+	var tiers []report.Tier
+	if opt.Tiers != "" {
+		if tiers, err = report.ParseTiers(opt.Tiers); err != nil {
+			return nil, fmt.Errorf("error parsing tiers for %s: %w", opt, err)
+		}
+	}
+
+	var signer crypto.Signer
+	if opt.SigningKey != "" {
+		if signer, err = attest.LoadPrivateKey(opt.SigningKey); err != nil {
+			return nil, fmt.Errorf("error loading signing key for %s: %w", opt, err)
+		}
+	}
+
+	var tmpl string
+	if opt.Template != "" {
+		b, err := os.ReadFile(opt.Template)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template %s: %w", opt.Template, err)
+		}
+		tmpl = string(b)
+	}
+
+	return &reportRun{
+		opt:       opt,
+		q:         q,
+		sel:       sel,
+		stats:     stats,
+		withStats: withStats,
+		tiers:     tiers,
+		signer:    signer,
+		tmpl:      tmpl,
+	}, nil
+}
+
+// fetch returns the report of the run's query.
+func (rr *reportRun) fetch(ctx context.Context) (*report.Report, error) {
+	var (
+		r   *report.Report
+		err error
+	)
+	switch {
+	case rr.opt.combined():
+		r, err = combineReports(ctx, rr.q, rr.opt.repos(), rr.opt.Reports)
+	case rr.q.User != "":
+		r, err = provider.GetUser(ctx, *rr.q)
+	case rr.q.PullRequest > 0:
+		r, err = provider.GetPullRequest(ctx, *rr.q)
+	default:
+		r, err = provider.GetAuthors(ctx, *rr.q)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing authors for %s: %w", rr.opt, err)
+	}
+
+	return r, nil
+}
+
+// finish assigns tiers, checks the fail-below gate, and applies the
+// selection. It returns the gate result, wrapping [ErrBelowThreshold], and
+// an error when the report cannot be finished.
+func (rr *reportRun) finish(r *report.Report) (gateErr, err error) {
+	r.AssignTiers(rr.tiers)
+
+	// The gate applies to every contributor, not just the selected ones.
+	if rr.opt.FailBelow > 0 {
+		gateErr = checkThreshold(r, rr.opt.FailBelow, rr.opt.FailIfAnyBelow)
+	}
+
+	if err := r.Select(rr.sel); err != nil {
+		return nil, fmt.Errorf("error selecting authors for %s: %w", rr.opt, err)
+	}
+	if rr.withStats && !rr.stats {
+		r.StripDetails(rr.q.Explain)
+	}
+
+	return gateErr, nil
+}
+
+// write writes the report in the run's output format.
+func (rr *reportRun) write(w io.Writer, r *report.Report) error {
+	switch rr.opt.Format {
+	case "attestation":
+		if err := attest.Write(w, r, rr.signer); err != nil {
+			return fmt.Errorf("error attesting authors for %s: %w", rr.opt, err)
+		}
+	case "sarif":
+		if err := render.SARIF(w, r, rr.q.RiskThreshold); err != nil {
+			return fmt.Errorf("error encoding authors for %s: %w", rr.opt, err)
+		}
+	case "markdown":
+		if err := render.Markdown(w, r, rr.tmpl); err != nil {
+			return fmt.Errorf("error rendering authors for %s: %w", rr.opt, err)
+		}
+	case "html":
+		if err := render.HTML(w, r, rr.tmpl); err != nil {
+			return fmt.Errorf("error rendering authors for %s: %w", rr.opt, err)
+		}
+	case "csv":
+		if err := render.CSV(w, r, rr.opt.Columns); err != nil {
+			return fmt.Errorf("error rendering authors for %s: %w", rr.opt, err)
+		}
+	case "tsv":
+		if err := render.TSV(w, r, rr.opt.Columns); err != nil {
+			return fmt.Errorf("error rendering authors for %s: %w", rr.opt, err)
+		}
 	case "yaml":
-		if err := yaml.NewEncoder(f).Encode(r); err != nil {
-			return fmt.Errorf("error encoding authors for %s: %w", opt, err)
+		if err := yaml.NewEncoder(w).Encode(r); err != nil {
+			return fmt.Errorf("error encoding authors for %s: %w", rr.opt, err)
+		}
+	default:
+		if err := json.NewEncoder(w).Encode(r); err != nil {
+			return fmt.Errorf("error encoding authors for %s: %w", rr.opt, err)
+		}
+	}
+
+	return nil
+}
+
+// combineReports scans each repo with the query's settings, loads the saved
+// reports, and combines them into one report.
+func combineReports(ctx context.Context, q *report.Query, repos, files []string) (*report.Report, error) {
+	reports := make([]*report.Report, 0, len(repos)+len(files))
+
+	for _, path := range files {
+		r, err := schema.LoadReport(path)
+		if err != nil {
+			return nil, fmt.Errorf("error loading report: %w", err)
+		}
+		reports = append(reports, r)
+	}
+
+	for _, repo := range repos {
+		rq, err := q.ForRepo(repo)
+		if err != nil {
+			return nil, fmt.Errorf("error creating query for %s: %w", repo, err)
+		}
+		r, err := provider.GetAuthors(ctx, *rq)
+		if err != nil {
+			return nil, fmt.Errorf("error listing authors for %s: %w", repo, err)
+		}
+		reports = append(reports, r)
+	}
+
+	r, err := report.Combine(reports, report.CombineOptions{
+		Model:         q.Model,
+		Policy:        q.Policy,
+		Trust:         q.Trust,
+		RiskThreshold: &q.RiskThreshold,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error combining reports: %w", err)
+	}
+
+	return r, nil
+}
+
+// checkThreshold returns an error wrapping [ErrBelowThreshold] when the
+// repo's commit-weighted average reputation, or with anyBelow any single
+// contributor's reputation, is below score. Reports without contributors
+// pass.
+func checkThreshold(r *report.Report, score float64, anyBelow bool) error {
+	if len(r.Contributors) == 0 {
+		return nil
+	}
+
+	if anyBelow {
+		var below []string
+		for _, a := range r.Contributors {
+			if a != nil && a.Reputation < score {
+				below = append(below, a.Username)
+			}
 		}
+		if len(below) > 0 {
+			return fmt.Errorf("%w: %d contributors below %.2f: %s",
+				ErrBelowThreshold, len(below), score, strings.Join(below, ", "))
+		}
+		return nil
+	}
+
+	avg := averageReputation(r)
+	if avg < score {
+		return fmt.Errorf("%w: average reputation %.2f below %.2f", ErrBelowThreshold, avg, score)
+	}
+
+	return nil
+}
+
+// averageReputation returns the commit-weighted average from the summary,
+// or the plain average over contributors when there is no summary.
+func averageReputation(r *report.Report) float64 {
+	if r.Summary != nil {
+		return r.Summary.AvgReputation
+	}
+
+	var sum float64
+	var n int
+	for _, a := range r.Contributors {
+		if a != nil {
+			sum += a.Reputation
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+
+	return sum / float64(n)
+}
+
+// VerifyAttestation checks the envelope's signature and predicate schema
+// and writes the verified report as JSON.
+func VerifyAttestation(opt *VerifyAttestationOptions) (retErr error) {
+	if opt == nil {
+		return errors.New("options must be specified")
+	}
+
+	if err := opt.Validate(); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+
+	pub, err := attest.LoadPublicKey(opt.Key)
+	if err != nil {
+		return fmt.Errorf("error loading key for %s: %w", opt, err)
+	}
+
+	env, err := attest.LoadEnvelope(opt.Envelope)
+	if err != nil {
+		return fmt.Errorf("error loading envelope for %s: %w", opt, err)
+	}
+
+	st, err := attest.Verify(env, pub)
+	if err != nil {
+		return fmt.Errorf("error verifying %s: %w", opt.Envelope, err)
+	}
+
+	slog.Info("attestation verified",
+		"repo", st.Predicate.Repo,
+		"commit", st.Predicate.AtCommit,
+		"generated_on", st.Predicate.GeneratedOn)
+
+	f := os.Stdout
+	if opt.File != "" {
+		f, err = os.Create(opt.File)
+		if err != nil {
+			return fmt.Errorf("error creating file %s: %w", opt.File, err)
+		}
+		defer func() {
+			if cerr := f.Close(); cerr != nil && retErr == nil {
+				retErr = fmt.Errorf("error closing file %s: %w", opt.File, cerr)
+			}
+		}()
+	}
+
+	if err := json.NewEncoder(f).Encode(st.Predicate); err != nil {
+		return fmt.Errorf("error encoding report for %s: %w", opt, err)
+	}
+
+	return nil
+}
+
+// DiffReports compares two reports and writes the differences.
+func DiffReports(opt *DiffReportsOptions) (retErr error) {
+	if opt == nil {
+		return errors.New("options must be specified")
+	}
+
+	if err := opt.Validate(); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+
+	prev, err := schema.LoadReport(opt.Old)
+	if err != nil {
+		return fmt.Errorf("error loading old report for %s: %w", opt, err)
+	}
+
+	curr, err := schema.LoadReport(opt.New)
+	if err != nil {
+		return fmt.Errorf("error loading new report for %s: %w", opt, err)
+	}
+
+	d := diff.Compare(prev, curr, opt.Threshold)
+
+	f := os.Stdout
+	if opt.File != "" {
+		f, err = os.Create(opt.File)
+		if err != nil {
+			return fmt.Errorf("error creating file %s: %w", opt.File, err)
+		}
+		defer func() {
+			if cerr := f.Close(); cerr != nil && retErr == nil {
+				retErr = fmt.Errorf("error closing file %s: %w", opt.File, cerr)
+			}
+		}()
+	}
+
+	switch opt.Format {
+	case "json":
+		err = diff.JSON(f, d)
+	case "markdown":
+		err = diff.Markdown(f, d)
 	default:
-		if err := json.NewEncoder(f).Encode(r); err != nil {
-			return fmt.Errorf("error encoding authors for %s: %w", opt, err)
+		err = diff.Text(f, d)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing diff for %s: %w", opt, err)
+	}
+
+	return nil
+}
+
+// WriteSchema writes the JSON Schema of the report format to file, or to
+// stdout when file is empty.
+func WriteSchema(file string) (retErr error) {
+	f := os.Stdout
+	if file != "" {
+		var err error
+		f, err = os.Create(file)
+		if err != nil {
+			return fmt.Errorf("error creating file %s: %w", file, err)
 		}
+		defer func() {
+			if cerr := f.Close(); cerr != nil && retErr == nil {
+				retErr = fmt.Errorf("error closing file %s: %w", file, cerr)
+			}
+		}()
+	}
+
+	if err := schema.WriteReport(f); err != nil {
+		return fmt.Errorf("error writing schema: %w", err)
 	}
 
 	return nil
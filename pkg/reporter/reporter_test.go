@@ -0,0 +1,38 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckThreshold(t *testing.T) {
+	r := &report.Report{
+		Summary: &report.Summary{AvgReputation: 0.6},
+		Contributors: []*report.Author{
+			{Username: "alice", Reputation: 0.9},
+			{Username: "bob", Reputation: 0.3},
+			{Username: "carol", Reputation: 0.45},
+		},
+	}
+
+	require.NoError(t, checkThreshold(r, 0.5, false))
+
+	err := checkThreshold(r, 0.7, false)
+	require.ErrorIs(t, err, ErrBelowThreshold)
+	assert.Contains(t, err.Error(), "average reputation 0.60 below 0.70")
+
+	err = checkThreshold(r, 0.5, true)
+	require.ErrorIs(t, err, ErrBelowThreshold)
+	assert.Contains(t, err.Error(), "2 contributors below 0.50: bob, carol")
+
+	require.NoError(t, checkThreshold(r, 0.3, true))
+	require.NoError(t, checkThreshold(&report.Report{}, 0.9, false))
+
+	// Without a summary, contributors are averaged evenly.
+	r.Summary = nil
+	require.NoError(t, checkThreshold(r, 0.54, false))
+	require.ErrorIs(t, checkThreshold(r, 0.56, false), ErrBelowThreshold)
+}
@@ -0,0 +1,12 @@
+// Package schema generates and validates the JSON Schema of reputer
+// reports.
+//
+// The schema is generated from the Go types of [report.Report] with
+// github.com/invopop/jsonschema using their JSON field names: fields
+// without omitempty are required, and numeric bounds come from
+// "jsonschema" struct tags. Documents are validated as read, before they
+// are decoded into Go types, with github.com/santhosh-tekuri/jsonschema.
+// Reports carry a schema_version; documents are only validated against a
+// schema with the same major version. Breaking changes to the report types
+// must bump [report.SchemaVersion].
+package schema
@@ -0,0 +1,143 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnversioned is returned for reports written before schema versioning.
+var ErrUnversioned = errors.New("report has no schema_version")
+
+// compiled holds the report schemas compiled for validation.
+var compiled = sync.OnceValues(func() (map[string]*jsonschema.Schema, error) {
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+
+	schemas := map[string]any{ReportID: Report(), yamlReportID: yamlReport()}
+	out := make(map[string]*jsonschema.Schema, len(schemas))
+	for id, s := range schemas {
+		b, err := json.Marshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding schema %s: %w", id, err)
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding schema %s: %w", id, err)
+		}
+		if err := c.AddResource(id, doc); err != nil {
+			return nil, fmt.Errorf("error adding schema %s: %w", id, err)
+		}
+		if out[id], err = c.Compile(id); err != nil {
+			return nil, fmt.Errorf("error compiling schema %s: %w", id, err)
+		}
+	}
+
+	return out, nil
+})
+
+// ValidateReport checks a JSON report against the schema. It returns
+// [ErrUnversioned] for reports without a schema_version and an error for
+// reports with a different major version.
+func ValidateReport(b []byte) error {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("error decoding report: %w", err)
+	}
+
+	return validate(doc, ReportID, "schema_version")
+}
+
+// validateYAMLReport checks a YAML report, as decoded, against the schema
+// of its yaml field names. See [ValidateReport].
+func validateYAMLReport(b []byte) error {
+	var raw any
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return fmt.Errorf("error decoding report: %w", err)
+	}
+
+	// YAML timestamps decode as time.Time; JSON encoding turns them back
+	// into the RFC 3339 strings the schema expects.
+	j, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("error decoding report: %w", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(j))
+	if err != nil {
+		return fmt.Errorf("error decoding report: %w", err)
+	}
+
+	return validate(doc, yamlReportID, "schemaVersion")
+}
+
+// validate checks the version of a decoded report and validates it against
+// the schema with the given ID.
+func validate(doc any, id, versionField string) error {
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return errors.New("report must be a JSON object")
+	}
+
+	v, _ := m[versionField].(string)
+	if v == "" {
+		return ErrUnversioned
+	}
+	if major(v) != major(report.SchemaVersion) {
+		return fmt.Errorf("unsupported schema version %s (supported: %s.x)", v, major(report.SchemaVersion))
+	}
+
+	schemas, err := compiled()
+	if err != nil {
+		return err
+	}
+	if err := schemas[id].Validate(doc); err != nil {
+		return fmt.Errorf("report does not match schema %s: %w", report.SchemaVersion, err)
+	}
+
+	return nil
+}
+
+// LoadReport reads a JSON or YAML report and validates the document as
+// read against the schema. Reports written before schema versioning are
+// loaded without validation.
+func LoadReport(path string) (*report.Report, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // G304: path is a user-supplied report file
+	if err != nil {
+		return nil, fmt.Errorf("error reading report %s: %w", path, err)
+	}
+
+	ext := filepath.Ext(path)
+	r, err := report.Parse(b, ext)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing report %s: %w", path, err)
+	}
+
+	validateDoc := ValidateReport
+	if e := strings.ToLower(ext); e == ".yaml" || e == ".yml" {
+		validateDoc = validateYAMLReport
+	}
+	if err := validateDoc(bytes.TrimSpace(b)); err != nil {
+		if !errors.Is(err, ErrUnversioned) {
+			return nil, fmt.Errorf("invalid report %s: %w", path, err)
+		}
+		slog.Warn("report predates schema versioning, skipping validation", "path", path)
+	}
+
+	return r, nil
+}
+
+// major returns the major component of a semantic version.
+func major(v string) string {
+	m, _, _ := strings.Cut(strings.TrimPrefix(v, "v"), ".")
+	return m
+}
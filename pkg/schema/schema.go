@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/invopop/jsonschema"
+	"github.com/mchmarny/reputer/pkg/report"
+)
+
+// ReportID is the published location of the report schema.
+const ReportID = "https://raw.githubusercontent.com/mchmarny/reputer/main/schema/report.schema.json"
+
+// yamlReportID identifies the schema of YAML reports, which use the yaml
+// field names of the same types. It is not published.
+const yamlReportID = "https://raw.githubusercontent.com/mchmarny/reputer/main/schema/report.yaml.schema.json"
+
+// Report returns the JSON Schema of [report.Report]. The schema_version
+// property accepts any version with the current major version.
+func Report() *jsonschema.Schema {
+	return reflect("json", ReportID, "schema_version")
+}
+
+// yamlReport returns the schema of [report.Report] under its yaml field
+// names.
+func yamlReport() *jsonschema.Schema {
+	return reflect("yaml", yamlReportID, "schemaVersion")
+}
+
+// WriteReport writes the report schema as indented JSON.
+func WriteReport(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(Report()); err != nil {
+		return fmt.Errorf("error encoding schema: %w", err)
+	}
+	return nil
+}
+
+// reflect generates the report schema using the field names of the given
+// struct tag. Unknown properties are allowed so reports from newer minor
+// versions still validate.
+func reflect(tag, id, versionField string) *jsonschema.Schema {
+	r := &jsonschema.Reflector{
+		AllowAdditionalProperties: true,
+		ExpandedStruct:            true,
+		FieldNameTag:              tag,
+	}
+
+	s := r.Reflect(&report.Report{})
+	s.ID = jsonschema.ID(id)
+	s.Title = "reputer report"
+	s.Description = "Contributor reputation report, schema version " + report.SchemaVersion + "."
+	if v, ok := s.Properties.Get(versionField); ok {
+		v.Pattern = "^" + regexp.QuoteMeta(major(report.SchemaVersion)) + `\.`
+	}
+
+	return s
+}
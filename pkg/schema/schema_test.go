@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/mchmarny/reputer/pkg/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const publishedSchema = "../../schema/report.schema.json"
+
+func testReport() *report.Report {
+	res := score.Compute(score.Signals{AgeDays: 400, Commits: 3, TotalCommits: 10, TotalContributors: 2})
+	return &report.Report{
+		SchemaVersion: report.SchemaVersion,
+		Repo:          "github.com/o/r",
+		AtCommit:      "abc123",
+		GeneratedOn:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Meta:          report.MakeMeta(nil),
+		Tiers:         report.DefaultTiers,
+		Contributors: []*report.Author{
+			{Username: "alice", Reputation: res.Score, Breakdown: &res, Commits: []string{"a1"}},
+			{Username: "bob", Reputation: 0.2},
+		},
+	}
+}
+
+func encode(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return b
+}
+
+func TestReportSchemaPublished(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteReport(&buf))
+
+	b, err := os.ReadFile(publishedSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(b), buf.String(),
+		"report types changed: run 'make schema' and bump report.SchemaVersion if the change is breaking")
+}
+
+func TestValidateReport(t *testing.T) {
+	require.NoError(t, ValidateReport(encode(t, testReport())))
+
+	r := testReport()
+	r.SchemaVersion = ""
+	require.ErrorIs(t, ValidateReport(encode(t, r)), ErrUnversioned)
+
+	r.SchemaVersion = "99.0.0"
+	require.ErrorContains(t, ValidateReport(encode(t, r)), "unsupported schema version 99.0.0")
+
+	require.ErrorContains(t, ValidateReport([]byte(`[]`)), "must be a JSON object")
+	require.Error(t, ValidateReport([]byte(`{`)))
+}
+
+func TestValidateReportViolations(t *testing.T) {
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(encode(t, testReport()), &doc))
+
+	contributors := doc["contributors"].([]any)
+	alice := contributors[0].(map[string]any)
+	alice["reputation"] = 1.5
+	delete(contributors[1].(map[string]any), "username")
+	doc["total_commits"] = 1.5
+	doc["generated_on"] = "yesterday"
+
+	err := ValidateReport(encode(t, doc))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at '/contributors/0/reputation': maximum: got 1.5, want 1")
+	assert.Contains(t, err.Error(), "at '/contributors/1': missing property 'username'")
+	assert.Contains(t, err.Error(), "at '/total_commits': got number, want integer")
+	assert.Contains(t, err.Error(), "at '/generated_on': 'yesterday' is not valid date-time")
+}
+
+func TestLoadReport(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "report.json")
+	require.NoError(t, os.WriteFile(jsonPath, encode(t, testReport()), 0o600))
+	r, err := LoadReport(jsonPath)
+	require.NoError(t, err)
+	assert.Len(t, r.Contributors, 2)
+
+	y, err := yaml.Marshal(testReport())
+	require.NoError(t, err)
+	yamlPath := filepath.Join(dir, "report.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, y, 0o600))
+	r, err = LoadReport(yamlPath)
+	require.NoError(t, err)
+	assert.Equal(t, report.SchemaVersion, r.SchemaVersion)
+
+	legacy := testReport()
+	legacy.SchemaVersion = ""
+	legacyPath := filepath.Join(dir, "legacy.json")
+	require.NoError(t, os.WriteFile(legacyPath, encode(t, legacy), 0o600))
+	_, err = LoadReport(legacyPath)
+	require.NoError(t, err)
+
+	bad := testReport()
+	bad.Contributors[0].Reputation = -1
+	badPath := filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(badPath, encode(t, bad), 0o600))
+	_, err = LoadReport(badPath)
+	require.ErrorContains(t, err, "minimum: got -1, want 0")
+
+	// YAML reports are validated as written, not after decoding, so a
+	// missing username is not filled in with an empty one.
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal(y, &doc))
+	delete(doc["contributors"].([]any)[1].(map[string]any), "username")
+	y, err = yaml.Marshal(doc)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(yamlPath, y, 0o600))
+	_, err = LoadReport(yamlPath)
+	require.ErrorContains(t, err, "at '/contributors/1': missing property 'username'")
+
+	_, err = LoadReport(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
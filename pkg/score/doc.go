@@ -2,4 +2,9 @@
 // using a v3 risk-weighted categorical algorithm with five categories:
 // code provenance, identity, engagement, community, and behavioral.
 // It exposes [Compute], [Signals], category weights, and [ModelVersion].
+//
+// Weights, ceilings, and curves are defined by a [Model]. Built-in
+// models are embedded from models/v<version>.yaml and selected with
+// [GetModel]; [ModelVersion] is the default. Custom definitions are
+// loaded with [LoadModel] and validated so signal weights sum to 1.0.
 package score
@@ -0,0 +1,320 @@
+package score
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Signal names used as keys in a model definition.
+const (
+	SignalProvenance   = "provenance"
+	SignalAge          = "age"
+	SignalAssociation  = "association"
+	SignalProfile      = "profile"
+	SignalProportion   = "proportion"
+	SignalRecency      = "recency"
+	SignalPRAcceptance = "pr_acceptance"
+	SignalFollowers    = "followers"
+	SignalRepoCount    = "repo_count"
+	SignalBurst        = "burst"
+	SignalForkRatio    = "fork_ratio"
+)
+
+// Curve names used to map a raw signal value into [0.0, 1.0].
+const (
+	CurveLog    = "log"
+	CurveLinear = "linear"
+	CurveDecay  = "decay"
+)
+
+const weightSumTolerance = 0.001
+
+// defaultParams fill in params a model definition omits, so a definition
+// without a params block does not score with zero confidence thresholds and
+// a zero reactivated-account cap. They match the params of the baseline
+// 3.2.0 model: behavior added by later versions, such as the cap and
+// renormalization, is only enabled when a definition sets it.
+var defaultParams = Params{
+	MinConfidenceCommits:  30,
+	ConfCommitsPerContrib: 10,
+	MinHalfLifeMultiple:   0.25,
+	ReactivatedScoreCeil:  1,
+	Renormalize:           false,
+}
+
+// defaultModel is the parsed built-in model. It must not be modified.
+var defaultModel = mustParseModel(mustReadDefinition(ModelVersion), ".yaml")
+
+// signalNames lists every signal a model must define, in evaluation order.
+var signalNames = []string{
+	SignalProvenance,
+	SignalAge,
+	SignalAssociation,
+	SignalProfile,
+	SignalProportion,
+	SignalRecency,
+	SignalPRAcceptance,
+	SignalFollowers,
+	SignalRepoCount,
+	SignalBurst,
+	SignalForkRatio,
+}
+
+// SignalNames returns the names of every signal a model defines, in evaluation order.
+func SignalNames() []string {
+	return slices.Clone(signalNames)
+}
+
+// Model defines the weights, ceilings, and curves used to compute reputation.
+type Model struct {
+	Name       string                `json:"name" yaml:"name"`
+	Version    string                `json:"version" yaml:"version"`
+	Categories []Category            `json:"categories" yaml:"categories"`
+	Signals    map[string]SignalSpec `json:"signals" yaml:"signals"`
+	Params     Params                `json:"params" yaml:"params"`
+}
+
+// Category groups signals; its weight is the sum of its signal weights.
+type Category struct {
+	Name    string   `json:"name" yaml:"name"`
+	Signals []string `json:"signals" yaml:"signals"`
+}
+
+// SignalSpec configures how a single signal contributes to the score.
+type SignalSpec struct {
+	Weight  float64 `json:"weight" yaml:"weight"`
+	Ceiling float64 `json:"ceiling,omitempty" yaml:"ceiling,omitempty"`
+	Curve   string  `json:"curve,omitempty" yaml:"curve,omitempty"`
+}
+
+// Params holds model-wide parameters that are not tied to a single signal weight.
+type Params struct {
+	MinConfidenceCommits  int64   `json:"min_confidence_commits" yaml:"min_confidence_commits"`
+	ConfCommitsPerContrib int64   `json:"conf_commits_per_contrib" yaml:"conf_commits_per_contrib"`
+	MinHalfLifeMultiple   float64 `json:"min_half_life_multiple" yaml:"min_half_life_multiple"`
+	ReactivatedScoreCeil  float64 `json:"reactivated_score_ceil" yaml:"reactivated_score_ceil"`
+	// Renormalize leaves unavailable signals out of the score and scales the
+	// rest to the available weight. Without it, unavailable signals are
+	// scored from their zero-valued inputs.
+	Renormalize bool `json:"renormalize" yaml:"renormalize"`
+}
+
+// DefaultModel returns a copy of the built-in scoring model for [ModelVersion].
+func DefaultModel() *Model {
+	return mustParseModel(mustReadDefinition(ModelVersion), ".yaml")
+}
+
+// DefaultModelDefinition returns the raw built-in model definition (YAML).
+func DefaultModelDefinition() []byte {
+	return mustReadDefinition(ModelVersion)
+}
+
+// LoadModel reads and validates a model definition from a YAML or JSON file.
+func LoadModel(path string) (*Model, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // G304: path is a user-supplied model file
+	if err != nil {
+		return nil, fmt.Errorf("error reading model %s: %w", path, err)
+	}
+
+	m, err := ParseModel(b, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing model %s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// ParseModel decodes and validates a model definition. The ext selects the
+// decoder: ".json" for JSON, anything else for YAML. Unknown fields are
+// rejected, and params the definition omits take their default values.
+func ParseModel(b []byte, ext string) (*Model, error) {
+	m := Model{Params: defaultParams}
+
+	switch strings.ToLower(ext) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("error decoding JSON model: %w", err)
+		}
+	default:
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("error decoding YAML model: %w", err)
+		}
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+
+	return &m, nil
+}
+
+// mustParseModel parses a built-in model definition and panics on error.
+func mustParseModel(b []byte, ext string) *Model {
+	m, err := ParseModel(b, ext)
+	if err != nil {
+		panic(fmt.Sprintf("built-in model: %v", err))
+	}
+	return m
+}
+
+// Validate checks that the model is complete and that signal weights sum to 1.0.
+func (m *Model) Validate() error {
+	if m == nil {
+		return errors.New("model must be specified")
+	}
+	if m.Name == "" {
+		return errors.New("name must be specified")
+	}
+	if m.Version == "" {
+		return errors.New("version must be specified")
+	}
+
+	for name := range m.Signals {
+		if !slices.Contains(signalNames, name) {
+			return fmt.Errorf("unknown signal: %s", name)
+		}
+	}
+
+	var sum float64
+	for _, name := range signalNames {
+		spec, ok := m.Signals[name]
+		if !ok {
+			return fmt.Errorf("signal %s must be specified", name)
+		}
+		if spec.Weight < 0 {
+			return fmt.Errorf("signal %s weight must be non-negative", name)
+		}
+		sum += spec.Weight
+
+		if name == SignalAssociation {
+			if spec.Curve != "" {
+				return fmt.Errorf("signal %s does not support a curve", name)
+			}
+			continue
+		}
+
+		if spec.Ceiling <= 0 {
+			return fmt.Errorf("signal %s ceiling must be positive", name)
+		}
+		switch spec.Curve {
+		case CurveLog, CurveLinear, CurveDecay:
+		default:
+			return fmt.Errorf("signal %s has unsupported curve: %q (must be log, linear, or decay)", name, spec.Curve)
+		}
+	}
+
+	if math.Abs(sum-1.0) > weightSumTolerance {
+		return fmt.Errorf("signal weights must sum to 1.0, got %.4f", sum)
+	}
+
+	seen := make(map[string]string)
+	for _, c := range m.Categories {
+		if c.Name == "" {
+			return errors.New("category name must be specified")
+		}
+		for _, name := range c.Signals {
+			if _, ok := m.Signals[name]; !ok {
+				return fmt.Errorf("category %s references unknown signal: %s", c.Name, name)
+			}
+			if prev, ok := seen[name]; ok {
+				return fmt.Errorf("signal %s is in both %s and %s categories", name, prev, c.Name)
+			}
+			seen[name] = c.Name
+		}
+	}
+	if len(seen) != len(signalNames) {
+		return errors.New("every signal must belong to a category")
+	}
+
+	if m.Params.MinConfidenceCommits < 0 || m.Params.ConfCommitsPerContrib < 0 {
+		return errors.New("confidence params must be non-negative")
+	}
+	if m.Params.MinHalfLifeMultiple < 0 || m.Params.MinHalfLifeMultiple > 1 {
+		return errors.New("min_half_life_multiple must be in [0, 1]")
+	}
+	if m.Params.ReactivatedScoreCeil < 0 || m.Params.ReactivatedScoreCeil > 1 {
+		return errors.New("reactivated_score_ceil must be in [0, 1]")
+	}
+
+	return nil
+}
+
+// Hash returns a stable SHA-256 digest of the model definition.
+// Formatting and comments in the source file do not affect the hash.
+func (m *Model) Hash() string {
+	if m == nil {
+		return ""
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// CategoryWeights returns the model's categories with their summed weights.
+func (m *Model) CategoryWeights() []CategoryWeight {
+	list := make([]CategoryWeight, 0, len(m.Categories))
+	for _, c := range m.Categories {
+		list = append(list, CategoryWeight{Name: c.Name, Weight: m.categoryWeight(c.Name)})
+	}
+	return list
+}
+
+// categoryWeight returns the summed weight of the signals in the named category.
+func (m *Model) categoryWeight(name string) float64 {
+	var w float64
+	for _, c := range m.Categories {
+		if c.Name != name {
+			continue
+		}
+		for _, s := range c.Signals {
+			w += m.Signals[s].Weight
+		}
+	}
+	return toFixed(w, 4)
+}
+
+// curve maps val into [0.0, 1.0] using the signal's configured curve and ceiling.
+func (m *Model) curve(signal string, val float64) float64 {
+	spec := m.Signals[signal]
+	return applyCurve(spec.Curve, val, spec.Ceiling)
+}
+
+// applyCurve maps val into [0.0, 1.0] using the named curve.
+func applyCurve(curve string, val, ceil float64) float64 {
+	switch curve {
+	case CurveLinear:
+		return clampedRatio(val, ceil)
+	case CurveDecay:
+		return expDecay(val, ceil)
+	default:
+		return logCurve(val, ceil)
+	}
+}
+
+// categoryOf returns the name of the category that contains the signal.
+func (m *Model) categoryOf(signal string) string {
+	for _, c := range m.Categories {
+		if slices.Contains(c.Signals, signal) {
+			return c.Name
+		}
+	}
+	return ""
+}
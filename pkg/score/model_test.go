@@ -0,0 +1,166 @@
+package score
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultModel(t *testing.T) {
+	m := DefaultModel()
+	require.NoError(t, m.Validate())
+	assert.Equal(t, "reputer-v3", m.Name)
+	assert.Equal(t, ModelVersion, m.Version)
+	assert.Equal(t, Categories(), m.CategoryWeights())
+	assert.Len(t, m.Signals, len(signalNames))
+}
+
+func TestDefaultModelIsCopy(t *testing.T) {
+	m := DefaultModel()
+	m.Signals[SignalAge] = SignalSpec{Weight: 1}
+	assert.InDelta(t, 0.15, DefaultModel().Signals[SignalAge].Weight, 0.0001)
+}
+
+func TestModelHash(t *testing.T) {
+	a := DefaultModel()
+	b := DefaultModel()
+	assert.Equal(t, a.Hash(), b.Hash())
+	assert.Contains(t, a.Hash(), "sha256:")
+
+	b.Version = "3.2.1"
+	assert.NotEqual(t, a.Hash(), b.Hash())
+
+	var n *Model
+	assert.Empty(t, n.Hash())
+}
+
+func TestParseModelJSON(t *testing.T) {
+	b, err := json.Marshal(DefaultModel())
+	require.NoError(t, err)
+
+	m, err := ParseModel(b, ".json")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultModel().Hash(), m.Hash())
+}
+
+func TestParseModelUnknownField(t *testing.T) {
+	_, err := ParseModel([]byte(`{"name": "x", "bogus": 1}`), ".json")
+	require.Error(t, err)
+
+	_, err = ParseModel([]byte("name: x\nbogus: 1\n"), ".yaml")
+	require.Error(t, err)
+}
+
+func TestModelValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(m *Model)
+		errMsg string
+	}{
+		{"missing name", func(m *Model) { m.Name = "" }, "name must be specified"},
+		{"missing version", func(m *Model) { m.Version = "" }, "version must be specified"},
+		{"unknown signal", func(m *Model) { m.Signals["stars"] = SignalSpec{} }, "unknown signal"},
+		{"missing signal", func(m *Model) { delete(m.Signals, SignalAge) }, "signal age must be specified"},
+		{"negative weight", func(m *Model) {
+			m.Signals[SignalAge] = SignalSpec{Weight: -0.15, Ceiling: 730, Curve: CurveLog}
+		}, "non-negative"},
+		{"weights sum", func(m *Model) {
+			m.Signals[SignalAge] = SignalSpec{Weight: 0.5, Ceiling: 730, Curve: CurveLog}
+		}, "sum to 1.0"},
+		{"zero ceiling", func(m *Model) {
+			m.Signals[SignalAge] = SignalSpec{Weight: 0.15, Curve: CurveLog}
+		}, "ceiling must be positive"},
+		{"bad curve", func(m *Model) {
+			m.Signals[SignalAge] = SignalSpec{Weight: 0.15, Ceiling: 730, Curve: "cubic"}
+		}, "unsupported curve"},
+		{"association curve", func(m *Model) {
+			m.Signals[SignalAssociation] = SignalSpec{Weight: 0.05, Curve: CurveLog}
+		}, "does not support a curve"},
+		{"category unknown signal", func(m *Model) {
+			m.Categories[0].Signals = append(m.Categories[0].Signals, "stars")
+		}, "references unknown signal"},
+		{"duplicate category signal", func(m *Model) {
+			m.Categories[0].Signals = append(m.Categories[0].Signals, SignalAge)
+		}, "in both"},
+		{"uncategorized signal", func(m *Model) { m.Categories = m.Categories[1:] }, "must belong to a category"},
+		{"unnamed category", func(m *Model) { m.Categories[0].Name = "" }, "category name"},
+		{"bad half-life multiple", func(m *Model) { m.Params.MinHalfLifeMultiple = 2 }, "min_half_life_multiple"},
+		{"bad reactivated ceil", func(m *Model) { m.Params.ReactivatedScoreCeil = -1 }, "reactivated_score_ceil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := DefaultModel()
+			tt.mutate(m)
+			err := m.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	var n *Model
+	require.Error(t, n.Validate())
+}
+
+func TestLoadModel(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "model.yaml")
+	require.NoError(t, os.WriteFile(path, DefaultModelDefinition(), 0o600))
+	m, err := LoadModel(path)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultModel().Hash(), m.Hash())
+
+	_, err = LoadModel(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading model")
+}
+
+func TestParseModelDefaultParams(t *testing.T) {
+	base, err := GetModel("3.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, defaultParams, base.Params, "defaults match the baseline built-in model")
+
+	def := string(DefaultModelDefinition())
+	noParams, _, ok := strings.Cut(def, "params:")
+	require.True(t, ok)
+	m, err := ParseModel([]byte(noParams), ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, defaultParams, m.Params)
+	assert.False(t, m.Params.Renormalize, "later behavior is not enabled by omission")
+	assert.InDelta(t, 1, m.Params.ReactivatedScoreCeil, 0)
+
+	m, err = ParseModel([]byte(noParams+"params:\n  reactivated_score_ceil: 0.5\n"), ".yaml")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, m.Params.ReactivatedScoreCeil, 0)
+	assert.Equal(t, defaultParams.MinConfidenceCommits, m.Params.MinConfidenceCommits)
+}
+
+func TestModelComputeCustomWeights(t *testing.T) {
+	s := Signals{AgeDays: 730, LastCommitDays: 10000, TotalCommits: 100, TotalContributors: 10}
+
+	m := DefaultModel()
+	assert.Equal(t, Compute(s), m.Compute(s))
+
+	// Shift all burst weight to age: a 730-day account with no other signals
+	// keeps the same total but from a different signal.
+	m.Signals[SignalAge] = SignalSpec{Weight: 0.25, Ceiling: 730, Curve: CurveLog}
+	m.Signals[SignalBurst] = SignalSpec{Weight: 0.0, Ceiling: 5, Curve: CurveLinear}
+	require.NoError(t, m.Validate())
+	assert.InDelta(t, 0.25, m.Compute(s).Score, 0.001)
+
+	// Linear age curve with a longer ceiling halves the age contribution.
+	m.Signals[SignalAge] = SignalSpec{Weight: 0.25, Ceiling: 1460, Curve: CurveLinear}
+	assert.InDelta(t, 0.13, m.Compute(s).Score, 0.001)
+}
+
+func TestApplyCurve(t *testing.T) {
+	assert.InDelta(t, 0.5, applyCurve(CurveLinear, 5, 10), 0.001)
+	assert.InDelta(t, 0.5, applyCurve(CurveDecay, 90, 90), 0.001)
+	assert.InDelta(t, 1.0, applyCurve(CurveLog, 730, 730), 0.001)
+}
@@ -0,0 +1,129 @@
+package score
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// modelDir is the embedded directory holding one definition per model version,
+// named v<version>.yaml.
+const modelDir = "models"
+
+//go:embed models/*.yaml
+var modelFS embed.FS
+
+// Versions returns the versions of all built-in models, oldest first.
+func Versions() []string {
+	entries, err := fs.ReadDir(modelFS, modelDir)
+	if err != nil {
+		return nil
+	}
+
+	list := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || path.Ext(name) != ".yaml" {
+			continue
+		}
+		list = append(list, strings.TrimSuffix(strings.TrimPrefix(name, "v"), ".yaml"))
+	}
+
+	slices.SortFunc(list, compareVersions)
+	return list
+}
+
+// GetModel returns a copy of the built-in model with the given version.
+// A leading "v" in the version is ignored.
+func GetModel(version string) (*Model, error) {
+	version = strings.TrimPrefix(version, "v")
+	if !slices.Contains(Versions(), version) {
+		return nil, fmt.Errorf("unknown model version: %s (available: %s)",
+			version, strings.Join(Versions(), ", "))
+	}
+
+	b, err := readDefinition(version)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := ParseModel(b, ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing model %s: %w", version, err)
+	}
+
+	if m.Version != version {
+		return nil, fmt.Errorf("model file for %s declares version %s", version, m.Version)
+	}
+
+	return m, nil
+}
+
+// ResolveModel returns the built-in model when ref is a known version,
+// otherwise it loads ref as a model definition file.
+func ResolveModel(ref string) (*Model, error) {
+	if slices.Contains(Versions(), strings.TrimPrefix(ref, "v")) {
+		return GetModel(ref)
+	}
+
+	if _, err := os.Stat(ref); err != nil {
+		return nil, fmt.Errorf("model %s is neither a known version (%s) nor a readable file: %w",
+			ref, strings.Join(Versions(), ", "), err)
+	}
+
+	return LoadModel(ref)
+}
+
+// readDefinition returns the raw embedded definition for a model version.
+func readDefinition(version string) ([]byte, error) {
+	b, err := modelFS.ReadFile(path.Join(modelDir, "v"+version+".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading model %s: %w", version, err)
+	}
+	return b, nil
+}
+
+// mustReadDefinition returns the raw embedded definition and panics on error.
+func mustReadDefinition(version string) []byte {
+	b, err := readDefinition(version)
+	if err != nil {
+		panic(fmt.Sprintf("built-in model: %v", err))
+	}
+	return b
+}
+
+// compareVersions orders dotted numeric versions; non-numeric parts compare as strings.
+func compareVersions(a, b string) int {
+	ap := strings.Split(a, ".")
+	bp := strings.Split(b, ".")
+
+	for i := 0; i < max(len(ap), len(bp)); i++ {
+		var x, y string
+		if i < len(ap) {
+			x = ap[i]
+		}
+		if i < len(bp) {
+			y = bp[i]
+		}
+
+		xn, xerr := strconv.Atoi(x)
+		yn, yerr := strconv.Atoi(y)
+		if xerr == nil && yerr == nil {
+			if xn != yn {
+				return xn - yn
+			}
+			continue
+		}
+
+		if c := strings.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
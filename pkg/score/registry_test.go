@@ -0,0 +1,95 @@
+package score
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersions(t *testing.T) {
+	v := Versions()
+	require.NotEmpty(t, v)
+	assert.Contains(t, v, ModelVersion)
+	assert.Equal(t, ModelVersion, v[len(v)-1], "ModelVersion should be the latest built-in model")
+}
+
+func TestGetModel(t *testing.T) {
+	for _, v := range Versions() {
+		m, err := GetModel(v)
+		require.NoError(t, err, v)
+		assert.Equal(t, v, m.Version)
+	}
+
+	m, err := GetModel("v" + ModelVersion)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultModel().Hash(), m.Hash())
+
+	_, err = GetModel("0.0.1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown model version")
+}
+
+func TestResolveModel(t *testing.T) {
+	m, err := ResolveModel(ModelVersion)
+	require.NoError(t, err)
+	assert.Equal(t, ModelVersion, m.Version)
+
+	path := filepath.Join(t.TempDir(), "custom.yaml")
+	require.NoError(t, os.WriteFile(path, DefaultModelDefinition(), 0o600))
+	m, err = ResolveModel(path)
+	require.NoError(t, err)
+	assert.Equal(t, ModelVersion, m.Version)
+
+	_, err = ResolveModel("9.9.9")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "neither a known version")
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Negative(t, compareVersions("3.1.0", "3.2.0"))
+	assert.Negative(t, compareVersions("3.2.0", "3.10.0"))
+	assert.Positive(t, compareVersions("4.0.0", "3.9.9"))
+	assert.Zero(t, compareVersions("3.2.0", "3.2.0"))
+	assert.Negative(t, compareVersions("3.2", "3.2.1"))
+	assert.Negative(t, compareVersions("3.2.0-alpha", "3.2.0-beta"))
+}
+
+func TestModelVersionsReproduce(t *testing.T) {
+	s := Signals{
+		Reactivated:       true,
+		AgeDays:           3000,
+		Commits:           20,
+		TotalCommits:      100,
+		TotalContributors: 10,
+		LastCommitDays:    2,
+		PublicRepos:       8,
+	}
+
+	m, err := GetModel("3.2.0")
+	require.NoError(t, err)
+	r := m.Compute(s)
+	assert.False(t, r.Capped, "3.2.0 predates the reactivated-account cap")
+	assert.Greater(t, r.Score, 0.7)
+
+	m, err = GetModel("3.3.0")
+	require.NoError(t, err)
+	r = m.Compute(s)
+	assert.True(t, r.Capped)
+	assert.InDelta(t, 0.30, r.Score, 0.01)
+
+	s.Reactivated = false
+	full := m.Compute(s)
+	s.Unavailable = []string{SignalPRAcceptance, SignalFollowers}
+	r = m.Compute(s)
+	assert.InDelta(t, full.Score, r.Score, 0.001, "3.3.0 predates renormalization")
+	assert.Equal(t, 1.0, r.Confidence)
+
+	m, err = GetModel("3.4.0")
+	require.NoError(t, err)
+	r = m.Compute(s)
+	assert.Greater(t, r.Score, full.Score, "3.4.0 renormalizes over collected signals")
+	assert.InDelta(t, 0.9, r.Confidence, 0.001)
+}
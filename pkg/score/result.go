@@ -0,0 +1,136 @@
+package score
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// breakdownPrecision is the number of decimals kept for breakdown values.
+const breakdownPrecision = 4
+
+// Result is the outcome of scoring a set of signals, including how each
+// signal and category contributed to the final score. Confidence is the
+// share of model weight backed by available signals; when it is below 1.0
+// the score is renormalized over the available weight.
+type Result struct {
+	Score      float64          `json:"score" yaml:"score"`
+	Suspended  bool             `json:"suspended,omitempty" yaml:"suspended,omitempty"`
+	Capped     bool             `json:"capped,omitempty" yaml:"capped,omitempty"`
+	Confidence float64          `json:"confidence" yaml:"confidence"`
+	Categories []CategoryResult `json:"categories,omitempty" yaml:"categories,omitempty"`
+	Signals    []SignalResult   `json:"signals,omitempty" yaml:"signals,omitempty"`
+}
+
+// CategoryResult is the subtotal contributed by a scoring category.
+type CategoryResult struct {
+	Name         string  `json:"name" yaml:"name"`
+	Weight       float64 `json:"weight" yaml:"weight"`
+	Contribution float64 `json:"contribution" yaml:"contribution"`
+}
+
+// SignalResult explains a single signal: its raw input, the value normalized
+// into [0.0, 1.0] by the signal's curve, its weight, and its contribution
+// (normalized x weight) to the score. Unavailable signals contribute nothing.
+type SignalResult struct {
+	Name         string  `json:"name" yaml:"name"`
+	Category     string  `json:"category" yaml:"category"`
+	Raw          float64 `json:"raw" yaml:"raw"`
+	Normalized   float64 `json:"normalized" yaml:"normalized"`
+	Weight       float64 `json:"weight" yaml:"weight"`
+	Contribution float64 `json:"contribution" yaml:"contribution"`
+	Unavailable  bool    `json:"unavailable,omitempty" yaml:"unavailable,omitempty"`
+	Note         string  `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+// breakdown accumulates signal contributions while a model computes a score.
+type breakdown struct {
+	model       *Model
+	unavailable []string
+	total       float64
+	missing     float64
+	result      Result
+}
+
+// newBreakdown returns an empty breakdown for the model. Signals listed in
+// unavailable are recorded without contributing to the total.
+func newBreakdown(m *Model, unavailable []string) *breakdown {
+	return &breakdown{model: m, unavailable: unavailable}
+}
+
+// add records a signal and adds its weighted contribution to the total.
+func (b *breakdown) add(signal string, raw, normalized float64, note string) {
+	weight := b.model.Signals[signal].Weight
+
+	if slices.Contains(b.unavailable, signal) {
+		b.missing += weight
+		slog.Debug(fmt.Sprintf("%s: unavailable", signal))
+		b.result.Signals = append(b.result.Signals, SignalResult{
+			Name:        signal,
+			Category:    b.model.categoryOf(signal),
+			Weight:      weight,
+			Unavailable: true,
+			Note:        "unavailable",
+		})
+		return
+	}
+
+	contribution := normalized * weight
+	b.total += contribution
+
+	slog.Debug(fmt.Sprintf("%s: %.4f (%s)", signal, contribution, note))
+
+	b.result.Signals = append(b.result.Signals, SignalResult{
+		Name:         signal,
+		Category:     b.model.categoryOf(signal),
+		Raw:          toFixed(raw, breakdownPrecision),
+		Normalized:   toFixed(normalized, breakdownPrecision),
+		Weight:       weight,
+		Contribution: toFixed(contribution, breakdownPrecision),
+		Note:         note,
+	})
+}
+
+// renormalize scales the total to the weight of the available signals and
+// records the confidence. Totals are left untouched when nothing is missing.
+func (b *breakdown) renormalize() {
+	if b.missing == 0 {
+		b.result.Confidence = 1
+		return
+	}
+
+	var sum float64
+	for _, spec := range b.model.Signals {
+		sum += spec.Weight
+	}
+
+	available := sum - b.missing
+	if available <= 0 {
+		b.total = 0
+		b.result.Confidence = 0
+		return
+	}
+
+	slog.Debug(fmt.Sprintf("renormalizing %.4f over available weight %.4f", b.total, available))
+	b.total = b.total * sum / available
+	b.result.Confidence = toFixed(available/sum, breakdownPrecision)
+}
+
+// finish rounds the total into the score and computes category subtotals.
+func (b *breakdown) finish() Result {
+	b.result.Score = toFixed(b.total, 2)
+
+	for _, c := range b.model.Categories {
+		cr := CategoryResult{Name: c.Name, Weight: b.model.categoryWeight(c.Name)}
+		var sum float64
+		for _, sr := range b.result.Signals {
+			if sr.Category == c.Name {
+				sum += sr.Contribution
+			}
+		}
+		cr.Contribution = toFixed(sum, breakdownPrecision)
+		b.result.Categories = append(b.result.Categories, cr)
+	}
+
+	return b.result
+}
@@ -0,0 +1,109 @@
+package score
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeBreakdown(t *testing.T) {
+	r := Compute(Signals{
+		Commits:           5,
+		TotalCommits:      50,
+		TotalContributors: 5,
+		AgeDays:           60,
+		HasBio:            true,
+		LastCommitDays:    3,
+		PRsMerged:         8,
+		PRsClosed:         2,
+		PublicRepos:       3,
+		RecentPRRepoCount: 2,
+	})
+
+	require.Len(t, r.Signals, len(signalNames))
+	require.Len(t, r.Categories, 5)
+
+	var signalSum float64
+	for i, sr := range r.Signals {
+		assert.Equal(t, signalNames[i], sr.Name)
+		assert.NotEmpty(t, sr.Category)
+		assert.GreaterOrEqual(t, sr.Normalized, 0.0)
+		assert.LessOrEqual(t, sr.Normalized, 1.0)
+		assert.InDelta(t, sr.Normalized*sr.Weight, sr.Contribution, 0.0001)
+		signalSum += sr.Contribution
+	}
+	assert.InDelta(t, r.Score, signalSum, 0.005)
+
+	var catSum, catWeight float64
+	for _, cr := range r.Categories {
+		assert.LessOrEqual(t, cr.Contribution, cr.Weight+0.0001)
+		catSum += cr.Contribution
+		catWeight += cr.Weight
+	}
+	assert.InDelta(t, signalSum, catSum, 0.0001)
+	assert.InDelta(t, 1.0, catWeight, 0.0001)
+
+	age := r.Signals[1]
+	assert.Equal(t, SignalAge, age.Name)
+	assert.Equal(t, "identity", age.Category)
+	assert.InDelta(t, 60.0, age.Raw, 0.0001)
+	assert.InDelta(t, 0.15, age.Weight, 0.0001)
+}
+
+func TestComputeBreakdownSuspended(t *testing.T) {
+	r := Compute(Signals{Suspended: true, AgeDays: 1000})
+	assert.True(t, r.Suspended)
+	assert.Zero(t, r.Score)
+	assert.InDelta(t, 1.0, r.Confidence, 0.0001)
+	assert.Empty(t, r.Signals)
+}
+
+func TestComputeBreakdownCapped(t *testing.T) {
+	r := Compute(Signals{Reactivated: true, AgeDays: 3000, TotalCommits: 100, TotalContributors: 10, PublicRepos: 8})
+	assert.True(t, r.Capped)
+	assert.InDelta(t, 0.3, r.Score, 0.001)
+
+	r = Compute(Signals{Reactivated: true, AgeDays: 1, TotalCommits: 100, TotalContributors: 10, LastCommitDays: 1000})
+	assert.False(t, r.Capped)
+}
+
+func TestComputeRenormalizesUnavailable(t *testing.T) {
+	s := Signals{
+		Commits:           5,
+		TotalCommits:      50,
+		TotalContributors: 5,
+		AgeDays:           400,
+		HasBio:            true,
+		LastCommitDays:    3,
+		PublicRepos:       3,
+	}
+
+	full := Compute(s)
+	assert.InDelta(t, 1.0, full.Confidence, 0.0001)
+
+	// No terminal PRs scores pr_acceptance as zero; marking it unavailable
+	// removes it from the score instead, so the score can only go up.
+	s.Unavailable = []string{SignalPRAcceptance}
+	r := Compute(s)
+
+	prWeight := defaultModel.Signals[SignalPRAcceptance].Weight
+	assert.InDelta(t, 1.0-prWeight, r.Confidence, 0.0001)
+	assert.Greater(t, r.Score, full.Score)
+
+	var signalSum float64
+	for _, sr := range r.Signals {
+		if sr.Name == SignalPRAcceptance {
+			assert.True(t, sr.Unavailable)
+			assert.Zero(t, sr.Contribution)
+		}
+		signalSum += sr.Contribution
+	}
+	assert.InDelta(t, r.Score, signalSum/r.Confidence, 0.01)
+}
+
+func TestComputeAllUnavailable(t *testing.T) {
+	r := Compute(Signals{AgeDays: 1000, Unavailable: SignalNames()})
+	assert.Zero(t, r.Score)
+	assert.Zero(t, r.Confidence)
+}
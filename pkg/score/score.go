@@ -7,44 +7,15 @@ import (
 )
 
 // ModelVersion is the current scoring model version.
-const ModelVersion = "3.2.0"
-
-const (
-	// Category weights (sum to 1.0).
-	provenanceWeight  = 0.15
-	ageWeight         = 0.15
-	associationWeight = 0.05
-	profileWeight     = 0.05
-	proportionWeight  = 0.15
-	recencyWeight     = 0.05
-	prAcceptWeight    = 0.05
-	followerWeight    = 0.05
-	repoCountWeight   = 0.10
-	burstWeight       = 0.10
-	forkOnlyWeight    = 0.10
-
-	// Ceilings and parameters.
-	ageCeilDays              = 730
-	verificationMaturityCeil = 730.0
-	followerRatioCeil        = 10.0
-	repoCountCeil            = 30.0
-	baseHalfLifeDays         = 90.0
-	minHalfLifeMultiple      = 0.25
-	minProportionCeil        = 0.05
-	minConfidenceCommits     = 30
-	confCommitsPerContrib    = 10
-	prCountCeil              = 20.0
-	burstCeil                = 5.0
-	forkOriginalCeil         = 5.0
-)
+const ModelVersion = "3.4.0"
 
-// Exported category weights derived from signal constants above.
+// Exported category weights derived from the built-in model.
 var (
-	CategoryProvenanceWeight = provenanceWeight
-	CategoryIdentityWeight   = ageWeight + associationWeight + profileWeight
-	CategoryEngagementWeight = proportionWeight + recencyWeight + prAcceptWeight
-	CategoryCommunityWeight  = followerWeight + repoCountWeight
-	CategoryBehavioralWeight = burstWeight + forkOnlyWeight
+	CategoryProvenanceWeight = defaultModel.categoryWeight("code_provenance")
+	CategoryIdentityWeight   = defaultModel.categoryWeight("identity")
+	CategoryEngagementWeight = defaultModel.categoryWeight("engagement")
+	CategoryCommunityWeight  = defaultModel.categoryWeight("community")
+	CategoryBehavioralWeight = defaultModel.categoryWeight("behavioral")
 )
 
 // CategoryWeight describes a scoring category and its weight.
@@ -78,45 +49,56 @@ type Signals struct {
 	RecentPRRepoCount int64  // Distinct repos with PR events in last 90 days
 	ForkedRepos       int64  // Owned repos that are forks
 	TrustedOrgMember  bool   // Member of a caller-specified trusted org
+
+	// Behavioral flags
+	Reactivated bool // Dormant account that recently started contributing
+
+	// Unavailable lists signals whose inputs could not be collected. Models
+	// that renormalize exclude them from the score and scale the remaining
+	// weights.
+	Unavailable []string
 }
 
-// Categories returns the model's scoring categories with their weights.
+// Categories returns the built-in model's scoring categories with their weights.
 func Categories() []CategoryWeight {
-	return []CategoryWeight{
-		{Name: "code_provenance", Weight: CategoryProvenanceWeight},
-		{Name: "identity", Weight: CategoryIdentityWeight},
-		{Name: "engagement", Weight: CategoryEngagementWeight},
-		{Name: "community", Weight: CategoryCommunityWeight},
-		{Name: "behavioral", Weight: CategoryBehavioralWeight},
-	}
+	return defaultModel.CategoryWeights()
 }
 
-// Compute returns a reputation score in [0.0, 1.0] using the v3 weighted model.
-func Compute(s Signals) float64 {
+// Compute scores the signals using the built-in v3 model.
+func Compute(s Signals) Result {
+	return defaultModel.Compute(s)
+}
+
+// Compute scores the signals using the model's weights, ceilings, and curves.
+// The returned [Result] holds the score in [0.0, 1.0] and its per-signal breakdown.
+func (m *Model) Compute(s Signals) Result {
 	if s.Suspended {
 		slog.Debug("score - suspended")
-		return 0
+		return Result{Suspended: true, Confidence: 1}
 	}
 
-	var rep float64
+	var unavailable []string
+	if m.Params.Renormalize {
+		unavailable = s.Unavailable
+	}
+	b := newBreakdown(m, unavailable)
 
-	// --- Category 1: Code Provenance (0.15) ---
+	// --- Category 1: Code Provenance ---
 	if s.Commits > 0 && s.TotalCommits > 0 {
 		verifiedRatio := float64(s.Commits-s.UnverifiedCommits) / float64(s.Commits)
-		maturity := logCurve(float64(s.AgeDays), verificationMaturityCeil)
-		rep += verifiedRatio * maturity * provenanceWeight
-		slog.Debug(fmt.Sprintf("provenance: %.4f (verified=%.2f, maturity=%.2f)",
-			verifiedRatio*maturity*provenanceWeight, verifiedRatio, maturity))
+		maturity := m.curve(SignalProvenance, float64(s.AgeDays))
+		b.add(SignalProvenance, verifiedRatio, verifiedRatio*maturity,
+			fmt.Sprintf("verified=%.2f, maturity=%.2f", verifiedRatio, maturity))
+	} else {
+		b.add(SignalProvenance, 0, 0, "no commits")
 	}
 
-	// --- Category 2: Identity (0.25) ---
-	ageScore := logCurve(float64(s.AgeDays), ageCeilDays) * ageWeight
-	rep += ageScore
-	slog.Debug(fmt.Sprintf("age: %.4f (%d days)", ageScore, s.AgeDays))
+	// --- Category 2: Identity ---
+	b.add(SignalAge, float64(s.AgeDays), m.curve(SignalAge, float64(s.AgeDays)),
+		fmt.Sprintf("%d days", s.AgeDays))
 
-	assocScore := associationScore(s.AuthorAssociation, s.OrgMember, s.TrustedOrgMember) * associationWeight
-	rep += assocScore
-	slog.Debug(fmt.Sprintf("association: %.4f (%s)", assocScore, s.AuthorAssociation))
+	assoc := associationScore(s.AuthorAssociation, s.OrgMember, s.TrustedOrgMember)
+	b.add(SignalAssociation, assoc, assoc, s.AuthorAssociation)
 
 	profileCount := 0
 	if s.HasBio {
@@ -131,86 +113,90 @@ func Compute(s Signals) float64 {
 	if s.HasWebsite {
 		profileCount++
 	}
-	profScore := float64(profileCount) / 4.0 * profileWeight
-	rep += profScore
-	slog.Debug(fmt.Sprintf("profile: %.4f (%d/4 fields)", profScore, profileCount))
+	b.add(SignalProfile, float64(profileCount), m.curve(SignalProfile, float64(profileCount)),
+		fmt.Sprintf("%d/4 fields", profileCount))
 
-	// --- Category 3: Engagement (0.25) ---
+	// --- Category 3: Engagement ---
 	if s.Commits > 0 && s.TotalCommits > 0 {
 		proportion := float64(s.Commits) / float64(s.TotalCommits)
-		propCeil := math.Max(1.0/float64(max(s.TotalContributors, 1)), minProportionCeil)
+		propSpec := m.Signals[SignalProportion]
+		propCeil := math.Max(1.0/float64(max(s.TotalContributors, 1)), propSpec.Ceiling)
 
 		confThreshold := float64(max(
-			int64(s.TotalContributors)*int64(confCommitsPerContrib),
-			int64(minConfidenceCommits),
+			int64(s.TotalContributors)*m.Params.ConfCommitsPerContrib,
+			m.Params.MinConfidenceCommits,
+			1,
 		))
 		confidence := math.Min(float64(s.TotalCommits)/confThreshold, 1.0)
 
-		propScore := clampedRatio(proportion, propCeil) * confidence * proportionWeight
-		rep += propScore
-		slog.Debug(fmt.Sprintf("proportion: %.4f (prop=%.3f, ceil=%.3f, conf=%.3f)",
-			propScore, proportion, propCeil, confidence))
+		b.add(SignalProportion, proportion, applyCurve(propSpec.Curve, proportion, propCeil)*confidence,
+			fmt.Sprintf("ceil=%.3f, conf=%.3f", propCeil, confidence))
+	} else {
+		b.add(SignalProportion, 0, 0, "no commits")
 	}
 
 	numContrib := max(s.TotalContributors, 1)
-	halfLifeMult := math.Max(1.0/math.Log(1+float64(numContrib)), minHalfLifeMultiple)
+	halfLifeMult := math.Max(1.0/math.Log(1+float64(numContrib)), m.Params.MinHalfLifeMultiple)
 	if halfLifeMult > 1.0 {
 		halfLifeMult = 1.0
 	}
-	halfLife := baseHalfLifeDays * halfLifeMult
-	recScore := expDecay(float64(s.LastCommitDays), halfLife) * recencyWeight
-	rep += recScore
-	slog.Debug(fmt.Sprintf("recency: %.4f (%d days, halfLife=%.1f)",
-		recScore, s.LastCommitDays, halfLife))
+	recSpec := m.Signals[SignalRecency]
+	halfLife := recSpec.Ceiling * halfLifeMult
+	b.add(SignalRecency, float64(s.LastCommitDays), applyCurve(recSpec.Curve, float64(s.LastCommitDays), halfLife),
+		fmt.Sprintf("%d days, halfLife=%.1f", s.LastCommitDays, halfLife))
 
 	totalTerminalPRs := s.PRsMerged + s.PRsClosed
 	if totalTerminalPRs > 0 {
 		mergeRate := float64(s.PRsMerged) / float64(totalTerminalPRs)
-		confidence := logCurve(float64(totalTerminalPRs), prCountCeil)
-		prScore := mergeRate * confidence * prAcceptWeight
-		rep += prScore
-		slog.Debug(fmt.Sprintf("pr_acceptance: %.4f (rate=%.2f, conf=%.2f)",
-			prScore, mergeRate, confidence))
+		confidence := m.curve(SignalPRAcceptance, float64(totalTerminalPRs))
+		b.add(SignalPRAcceptance, mergeRate, mergeRate*confidence,
+			fmt.Sprintf("rate=%.2f, conf=%.2f", mergeRate, confidence))
+	} else {
+		b.add(SignalPRAcceptance, 0, 0, "no terminal PRs")
 	}
 
-	// --- Category 4: Community (0.15) ---
+	// --- Category 4: Community ---
 	if s.Following > 0 {
 		ratio := float64(s.Followers) / float64(s.Following)
-		rep += logCurve(ratio, followerRatioCeil) * followerWeight
-		slog.Debug(fmt.Sprintf("followers: %.4f (ratio=%.2f)",
-			logCurve(ratio, followerRatioCeil)*followerWeight, ratio))
+		b.add(SignalFollowers, ratio, m.curve(SignalFollowers, ratio), fmt.Sprintf("ratio=%.2f", ratio))
+	} else {
+		b.add(SignalFollowers, 0, 0, "following none")
 	}
 
 	totalRepos := float64(s.PublicRepos)
-	rep += logCurve(totalRepos, repoCountCeil) * repoCountWeight
-	slog.Debug(fmt.Sprintf("repos: %.4f (%d combined)",
-		logCurve(totalRepos, repoCountCeil)*repoCountWeight, int64(totalRepos)))
+	b.add(SignalRepoCount, totalRepos, m.curve(SignalRepoCount, totalRepos),
+		fmt.Sprintf("%d combined", s.PublicRepos))
 
-	// --- Category 5: Behavioral (0.20) ---
+	// --- Category 5: Behavioral ---
 	if s.RecentPRRepoCount > 0 && s.AgeDays > 0 {
 		ageMonths := math.Max(float64(s.AgeDays)/30.0, 1.0)
 		burstRate := float64(s.RecentPRRepoCount) / ageMonths
-		bScore := (1.0 - clampedRatio(burstRate, burstCeil)) * burstWeight
-		rep += bScore
-		slog.Debug(fmt.Sprintf("burst: %.4f (rate=%.2f, repos=%d)",
-			bScore, burstRate, s.RecentPRRepoCount))
+		b.add(SignalBurst, burstRate, 1.0-m.curve(SignalBurst, burstRate),
+			fmt.Sprintf("rate=%.2f, repos=%d", burstRate, s.RecentPRRepoCount))
 	} else {
-		rep += burstWeight
-		slog.Debug(fmt.Sprintf("burst: %.4f (no recent PR repos)", burstWeight))
+		b.add(SignalBurst, 0, 1, "no recent PR repos")
 	}
 
 	totalOwnedRepos := s.PublicRepos
 	if totalOwnedRepos > 0 {
 		originalRepos := float64(totalOwnedRepos - s.ForkedRepos)
-		fScore := clampedRatio(originalRepos, forkOriginalCeil) * forkOnlyWeight
-		rep += fScore
-		slog.Debug(fmt.Sprintf("fork_ratio: %.4f (original=%.0f, forked=%d)",
-			fScore, originalRepos, s.ForkedRepos))
+		b.add(SignalForkRatio, originalRepos, m.curve(SignalForkRatio, originalRepos),
+			fmt.Sprintf("original=%.0f, forked=%d", originalRepos, s.ForkedRepos))
+	} else {
+		b.add(SignalForkRatio, 0, 0, "no owned repos")
 	}
 
-	result := toFixed(rep, 2)
-	slog.Debug(fmt.Sprintf("reputation: %.2f", result))
-	return result
+	b.renormalize()
+
+	if s.Reactivated && b.total > m.Params.ReactivatedScoreCeil {
+		slog.Debug(fmt.Sprintf("reactivated: capped %.4f at %.2f", b.total, m.Params.ReactivatedScoreCeil))
+		b.total = m.Params.ReactivatedScoreCeil
+		b.result.Capped = true
+	}
+
+	r := b.finish()
+	slog.Debug(fmt.Sprintf("reputation: %.2f", r.Score))
+	return r
 }
 
 // associationScore maps GitHub's author_association to a [0, 1] score.
@@ -303,11 +303,24 @@ func TestCompute(t *testing.T) {
 			},
 			wantScore: 0.78,
 		},
+		{
+			name: "reactivated account capped",
+			signals: Signals{
+				Reactivated:       true,
+				AgeDays:           3000,
+				Commits:           20,
+				TotalCommits:      100,
+				TotalContributors: 10,
+				LastCommitDays:    2,
+				PublicRepos:       8,
+			},
+			wantScore: 0.30,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := Compute(tt.signals)
+			got := Compute(tt.signals).Score
 			assert.InDelta(t, tt.wantScore, got, 0.01,
 				"%s: got=%.4f want=%.4f", tt.name, got, tt.wantScore)
 		})
@@ -315,5 +328,5 @@ func TestCompute(t *testing.T) {
 }
 
 func TestModelVersion(t *testing.T) {
-	assert.Equal(t, "3.2.0", ModelVersion)
+	assert.Equal(t, "3.4.0", ModelVersion)
 }
@@ -0,0 +1,270 @@
+// Package server serves reputation reports over a REST API.
+//
+// Reports are produced on demand with the server's provider tokens, cached
+// for a configurable time, and concurrent requests for the same report are
+// coalesced into one provider query. Responses are the same JSON reports
+// the CLI writes. Since every query spends the server's tokens, the server
+// refuses to start unless requests are required to carry a bearer token,
+// limited to allowed hosts and orgs, or it is explicitly run insecure.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/cache"
+	"github.com/mchmarny/reputer/pkg/provider"
+	"github.com/mchmarny/reputer/pkg/report"
+)
+
+const (
+	// DefaultAddr is the address the server listens on; loopback only, so
+	// exposing the server is a deliberate choice.
+	DefaultAddr = "127.0.0.1:8080"
+	// DefaultTTL is how long reports are cached.
+	DefaultTTL = 15 * time.Minute
+
+	// queryTimeout bounds a single provider query; scanning a large repo
+	// can take minutes.
+	queryTimeout = 10 * time.Minute
+	// shutdownTimeout bounds how long in-flight requests may finish after
+	// the server is stopped.
+	shutdownTimeout   = 30 * time.Second
+	readHeaderTimeout = 10 * time.Second
+)
+
+// Options configures the server.
+type Options struct {
+	// Addr is the listen address (optional, default: DefaultAddr).
+	Addr string
+	// TTL is how long reports are cached (optional, default: DefaultTTL).
+	TTL time.Duration
+	// Stats includes author stats in repo reports. User reports always
+	// include them, as with the CLI.
+	Stats       bool
+	TrustedOrgs []string
+	// AuthToken, when set, must be sent by API requests as a bearer token
+	// (optional).
+	AuthToken string
+	// Allow limits API requests to these hosts, e.g. github.com, or orgs,
+	// e.g. github.com/acme (optional, default: any). User lookups are only
+	// allowed on hosts allowed as a whole.
+	Allow []string
+	// Insecure allows serving without an AuthToken or Allow list, so anyone
+	// who can reach the server spends its provider tokens (optional).
+	Insecure bool
+}
+
+// ErrUnprotected is returned when the server would serve any request
+// without a bearer token or allowlist, and was not explicitly run insecure.
+var ErrUnprotected = errors.New("server requires a bearer token or allowlist, or must be explicitly run insecure")
+
+// Server serves reputation reports.
+type Server struct {
+	opt     Options
+	reports *cache.Cache
+	// accounts caches account-level lookups for as long as reports, so a
+	// report is never built from older account data than it is cached for.
+	accounts *cache.Cache
+
+	// getAuthors and getUser query the providers; replaced in tests.
+	getAuthors func(ctx context.Context, q report.Query) (*report.Report, error)
+	getUser    func(ctx context.Context, q report.Query) (*report.Report, error)
+}
+
+// New returns a server with the options' defaults applied.
+func New(opt Options) *Server {
+	if opt.Addr == "" {
+		opt.Addr = DefaultAddr
+	}
+	if opt.TTL == 0 {
+		opt.TTL = DefaultTTL
+	}
+
+	return &Server{
+		opt:        opt,
+		reports:    cache.New(opt.TTL),
+		accounts:   cache.New(opt.TTL),
+		getAuthors: provider.GetAuthors,
+		getUser:    provider.GetUser,
+	}
+}
+
+// Handler returns the server's API routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/repos/{host}/{owner}/{name}/report", s.authorize(s.handleRepo))
+	mux.HandleFunc("GET /v1/users/{host}/{login}", s.authorize(s.handleUser))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+// ListenAndServe serves the API until ctx is done, then lets in-flight
+// requests finish. It returns ErrUnprotected when the server has neither
+// an AuthToken nor an Allow list and is not run Insecure.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	if s.opt.AuthToken == "" && len(s.opt.Allow) == 0 {
+		if !s.opt.Insecure {
+			return ErrUnprotected
+		}
+		slog.Warn("server accepts unauthenticated requests for any repo or user, spending its provider tokens on each")
+	}
+
+	srv := &http.Server{
+		Addr:              s.opt.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		slog.Info("server listening", "addr", s.opt.Addr, "ttl", s.opt.TTL)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("error serving on %s: %w", s.opt.Addr, err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("server shutting down")
+	sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(sctx); err != nil {
+		return fmt.Errorf("error shutting down server: %w", err)
+	}
+
+	return nil
+}
+
+// authorize rejects requests without the configured bearer token, and
+// requests for a host or org that is not allowed.
+func (s *Server) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.opt.AuthToken != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.opt.AuthToken)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+				return
+			}
+		}
+
+		if !s.allowed(r.PathValue("host"), r.PathValue("owner")) {
+			writeError(w, http.StatusForbidden, errors.New("host or org is not allowed"))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// allowed reports whether a request for the host, and for repo requests
+// the owner, is allowed. Hosts and orgs are matched case-insensitively.
+func (s *Server) allowed(host, owner string) bool {
+	if len(s.opt.Allow) == 0 {
+		return true
+	}
+
+	for _, a := range s.opt.Allow {
+		if strings.EqualFold(a, host) || (owner != "" && strings.EqualFold(a, host+"/"+owner)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleRepo serves the report of a repo.
+func (s *Server) handleRepo(w http.ResponseWriter, r *http.Request) {
+	repo := r.PathValue("host") + "/" + r.PathValue("owner") + "/" + r.PathValue("name")
+
+	q, err := report.MakeQuery(repo, "", s.opt.Stats)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	q.TrustedOrgs = s.opt.TrustedOrgs
+	q.Accounts = s.accounts
+
+	s.serve(w, r, "repo/"+strings.ToLower(repo), func(ctx context.Context) (*report.Report, error) {
+		return s.getAuthors(ctx, *q)
+	})
+}
+
+// handleUser serves the report of a single user.
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
+	user := r.PathValue("host") + "/" + r.PathValue("login")
+
+	q, err := report.DefaultQuery(true).ForUser(user)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	q.TrustedOrgs = s.opt.TrustedOrgs
+	q.Accounts = s.accounts
+
+	s.serve(w, r, "user/"+strings.ToLower(user), func(ctx context.Context) (*report.Report, error) {
+		return s.getUser(ctx, *q)
+	})
+}
+
+// serve writes the report cached under key, producing it with fetch when
+// it is not cached. The query runs detached from the request, so a client
+// that disconnects does not fail others waiting on the same report.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, key string, fetch func(context.Context) (*report.Report, error)) {
+	start := time.Now()
+
+	rpt, err := cache.Get(s.reports, key, func() (*report.Report, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), queryTimeout)
+		defer cancel()
+
+		rpt, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rpt.AssignTiers(nil)
+		return rpt, nil
+	})
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, provider.ErrUnsupported) {
+			status = http.StatusNotFound
+		}
+		slog.Error("request failed", "path", r.URL.Path, "status", status, "error", err)
+		writeError(w, status, err)
+		return
+	}
+
+	slog.Debug("request served", "path", r.URL.Path, "duration", time.Since(start))
+	writeJSON(w, http.StatusOK, rpt)
+}
+
+// errorResponse is the body of a failed request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeError writes err as a JSON error response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("error writing response", "error", err)
+	}
+}
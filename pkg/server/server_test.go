@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mchmarny/reputer/pkg/provider"
+	"github.com/mchmarny/reputer/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// get requests path from the server and decodes the JSON body into v.
+func get(t *testing.T, srv *httptest.Server, path string, v any) int {
+	t.Helper()
+	resp, err := http.Get(srv.URL + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if v != nil {
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func TestRepoReport(t *testing.T) {
+	s := New(Options{Stats: true, TrustedOrgs: []string{"acme"}})
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	s.getAuthors = func(ctx context.Context, q report.Query) (*report.Report, error) {
+		calls.Add(1)
+		<-release
+		assert.NoError(t, ctx.Err())
+		assert.Equal(t, "github.com/o/r", q.Repo)
+		assert.True(t, q.Stats)
+		assert.Equal(t, []string{"acme"}, q.TrustedOrgs)
+		assert.NotNil(t, q.Accounts, "account lookups are cached with the server's reports")
+		return &report.Report{
+			SchemaVersion: report.SchemaVersion,
+			Repo:          q.Repo,
+			Contributors:  []*report.Author{{Username: "alice", Reputation: 0.8}},
+		}, nil
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var r report.Report
+			assert.Equal(t, http.StatusOK, get(t, srv, "/v1/repos/github.com/o/r/report", &r))
+			assert.Equal(t, "github.com/o/r", r.Repo)
+			if assert.Len(t, r.Contributors, 1) {
+				assert.Equal(t, "high", r.Contributors[0].Tier, "tiers are assigned as by the CLI")
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load(), "concurrent requests are coalesced")
+
+	assert.Equal(t, http.StatusOK, get(t, srv, "/v1/repos/github.com/O/R/report", &report.Report{}))
+	assert.Equal(t, int32(1), calls.Load(), "reports are cached, case-insensitively")
+}
+
+func TestUserReport(t *testing.T) {
+	s := New(Options{})
+	s.getUser = func(_ context.Context, q report.Query) (*report.Report, error) {
+		assert.Equal(t, "octocat", q.User)
+		assert.Equal(t, "github.com", q.Kind)
+		assert.True(t, q.Stats, "user reports include stats")
+		return &report.Report{Contributors: []*report.Author{{Username: q.User}}}, nil
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	var r report.Report
+	assert.Equal(t, http.StatusOK, get(t, srv, "/v1/users/github.com/octocat", &r))
+	require.Len(t, r.Contributors, 1)
+	assert.Equal(t, "octocat", r.Contributors[0].Username)
+}
+
+func TestErrors(t *testing.T) {
+	s := New(Options{})
+	var calls atomic.Int32
+	s.getAuthors = func(_ context.Context, q report.Query) (*report.Report, error) {
+		calls.Add(1)
+		if q.Kind == "bitbucket.org" {
+			return nil, fmt.Errorf("%w: %s", provider.ErrUnsupported, q.Kind)
+		}
+		return nil, errors.New("rate limited")
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	var e errorResponse
+	assert.Equal(t, http.StatusNotFound, get(t, srv, "/v1/repos/bitbucket.org/o/r/report", &e))
+	assert.Contains(t, e.Error, "unsupported git provider")
+
+	assert.Equal(t, http.StatusBadGateway, get(t, srv, "/v1/repos/github.com/o/r/report", &e))
+	assert.Equal(t, "rate limited", e.Error)
+	assert.Equal(t, http.StatusBadGateway, get(t, srv, "/v1/repos/github.com/o/r/report", &e))
+	assert.Equal(t, int32(3), calls.Load(), "failures are not cached")
+
+	assert.Equal(t, http.StatusNotFound, get(t, srv, "/v1/repos/github.com/o/report", nil))
+	assert.Equal(t, http.StatusOK, get(t, srv, "/healthz", nil))
+}
+
+func TestAuthorize(t *testing.T) {
+	s := New(Options{AuthToken: "secret", Allow: []string{"github.com/Acme", "gitlab.com"}})
+	s.getAuthors = func(_ context.Context, q report.Query) (*report.Report, error) {
+		return &report.Report{Repo: q.Repo}, nil
+	}
+	s.getUser = func(_ context.Context, q report.Query) (*report.Report, error) {
+		return &report.Report{Contributors: []*report.Author{{Username: q.User}}}, nil
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	request := func(path, token string) int {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request("/v1/repos/github.com/acme/r/report", ""))
+	assert.Equal(t, http.StatusUnauthorized, request("/v1/repos/github.com/acme/r/report", "wrong"))
+	assert.Equal(t, http.StatusOK, request("/v1/repos/github.com/acme/r/report", "secret"), "orgs match case-insensitively")
+	assert.Equal(t, http.StatusForbidden, request("/v1/repos/github.com/other/r/report", "secret"))
+	assert.Equal(t, http.StatusOK, request("/v1/repos/gitlab.com/any/r/report", "secret"), "a whole host is allowed")
+	assert.Equal(t, http.StatusForbidden, request("/v1/users/github.com/octocat", "secret"), "users need their host allowed")
+	assert.Equal(t, http.StatusOK, request("/healthz", ""), "health checks are open")
+}
+
+func TestListenAndServeUnprotected(t *testing.T) {
+	err := New(Options{Addr: "127.0.0.1:0"}).ListenAndServe(context.Background())
+	require.ErrorIs(t, err, ErrUnprotected, "refuses to start with neither a token nor an allowlist")
+
+	for _, opt := range []Options{
+		{Addr: "127.0.0.1:0", AuthToken: "secret"},
+		{Addr: "127.0.0.1:0", Allow: []string{"github.com"}},
+		{Addr: "127.0.0.1:0", Insecure: true},
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- New(opt).ListenAndServe(ctx) }()
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+func TestNewDefaults(t *testing.T) {
+	s := New(Options{})
+	assert.Equal(t, DefaultAddr, s.opt.Addr)
+	assert.Equal(t, DefaultTTL, s.opt.TTL)
+}
+
+func TestListenAndServe(t *testing.T) {
+	s := New(Options{Addr: "127.0.0.1:0", AuthToken: "secret"})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.ListenAndServe(ctx) }()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	s = New(Options{Addr: "invalid-addr", Insecure: true})
+	require.ErrorContains(t, s.ListenAndServe(context.Background()), "error serving on invalid-addr")
+}
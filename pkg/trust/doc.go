@@ -0,0 +1,9 @@
+// Package trust applies explicit, reviewer-maintained trust decisions to
+// computed reputation scores.
+//
+// A trust file lists allowlisted users (given a score floor or a fixed
+// score), denylisted users (forced to 0 with a reason), trusted orgs, and
+// trusted email domains. It is loaded from YAML or JSON, either from a local
+// path or from the scanned repo itself (see [RepoPath]). Every adjustment is
+// returned as an [Override] so reports can show why a score changed.
+package trust
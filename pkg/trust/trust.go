@@ -0,0 +1,249 @@
+package trust
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoPath is where a trust file is read from within a repo.
+const RepoPath = ".github/reputer.yaml"
+
+// Override types.
+const (
+	// OverrideAllow marks an allowlisted user.
+	OverrideAllow = "allow"
+	// OverrideDeny marks a denylisted user.
+	OverrideDeny = "deny"
+	// OverrideTrustedOrg marks a member of a trusted org.
+	OverrideTrustedOrg = "trusted_org"
+	// OverrideTrustedDomain marks an author with a verified email in a trusted domain.
+	OverrideTrustedDomain = "trusted_domain"
+)
+
+// Config is a set of explicit trust decisions.
+type Config struct {
+	Allow          []*Allow `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Deny           []*Deny  `json:"deny,omitempty" yaml:"deny,omitempty"`
+	TrustedOrgs    []string `json:"trusted_orgs,omitempty" yaml:"trusted_orgs,omitempty"`
+	TrustedDomains []string `json:"trusted_domains,omitempty" yaml:"trusted_domains,omitempty"`
+}
+
+// Allow raises an allowlisted user's score to at least Floor, or sets it to Score.
+type Allow struct {
+	User   string   `json:"user" yaml:"user"`
+	Floor  *float64 `json:"floor,omitempty" yaml:"floor,omitempty"`
+	Score  *float64 `json:"score,omitempty" yaml:"score,omitempty"`
+	Reason string   `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Deny forces a denylisted user's score to 0.
+type Deny struct {
+	User   string `json:"user" yaml:"user"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// Override records a trust decision applied to an author.
+type Override struct {
+	Type    string   `json:"type" yaml:"type"`
+	Subject string   `json:"subject" yaml:"subject"`
+	Score   *float64 `json:"score,omitempty" yaml:"score,omitempty"`
+	Reason  string   `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Load reads and validates a trust file.
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // G304: path is a user-supplied trust file
+	if err != nil {
+		return nil, fmt.Errorf("error reading trust file %s: %w", path, err)
+	}
+
+	c, err := Parse(b, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing trust file %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Parse decodes and validates a trust file. The ext selects the decoder:
+// ".json" for JSON, anything else for YAML. Unknown fields are rejected.
+func Parse(b []byte, ext string) (*Config, error) {
+	var c Config
+
+	switch strings.ToLower(ext) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&c); err != nil {
+			return nil, fmt.Errorf("error decoding JSON trust file: %w", err)
+		}
+	default:
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err := dec.Decode(&c); err != nil {
+			return nil, fmt.Errorf("error decoding YAML trust file: %w", err)
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid trust file: %w", err)
+	}
+
+	return &c, nil
+}
+
+// Validate checks entries and normalizes domains to lower case.
+// Users are matched case-insensitively and may appear only once.
+func (c *Config) Validate() error {
+	if c == nil {
+		return errors.New("trust config must be specified")
+	}
+
+	seen := make(map[string]string)
+	mark := func(user, list string) error {
+		if user == "" {
+			return fmt.Errorf("%s entry: user must be specified", list)
+		}
+		key := strings.ToLower(user)
+		if prev, ok := seen[key]; ok {
+			return fmt.Errorf("user %s is listed in both %s and %s", user, prev, list)
+		}
+		seen[key] = list
+		return nil
+	}
+
+	for _, a := range c.Allow {
+		if a == nil {
+			return errors.New("allow entry must be specified")
+		}
+		if err := mark(a.User, OverrideAllow); err != nil {
+			return err
+		}
+		switch {
+		case a.Floor == nil && a.Score == nil:
+			return fmt.Errorf("allow %s: floor or score must be specified", a.User)
+		case a.Floor != nil && a.Score != nil:
+			return fmt.Errorf("allow %s: floor and score are mutually exclusive", a.User)
+		case a.Floor != nil && (*a.Floor < 0 || *a.Floor > 1):
+			return fmt.Errorf("allow %s: floor must be in [0, 1]", a.User)
+		case a.Score != nil && (*a.Score < 0 || *a.Score > 1):
+			return fmt.Errorf("allow %s: score must be in [0, 1]", a.User)
+		}
+	}
+
+	for _, d := range c.Deny {
+		if d == nil {
+			return errors.New("deny entry must be specified")
+		}
+		if err := mark(d.User, OverrideDeny); err != nil {
+			return err
+		}
+		if strings.TrimSpace(d.Reason) == "" {
+			return fmt.Errorf("deny %s: reason must be specified", d.User)
+		}
+	}
+
+	for _, o := range c.TrustedOrgs {
+		if o == "" {
+			return errors.New("trusted org must not be empty")
+		}
+	}
+
+	for i, d := range c.TrustedDomains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d == "" || strings.Contains(d, "@") {
+			return fmt.Errorf("invalid trusted domain: %q", c.TrustedDomains[i])
+		}
+		c.TrustedDomains[i] = d
+	}
+
+	return nil
+}
+
+// Merge returns a config combining c and other. Both must be valid; entries
+// from c come first and a user listed in both fails validation.
+func (c *Config) Merge(other *Config) (*Config, error) {
+	switch {
+	case c == nil:
+		return other, nil
+	case other == nil:
+		return c, nil
+	}
+
+	m := &Config{
+		Allow:          slices.Concat(c.Allow, other.Allow),
+		Deny:           slices.Concat(c.Deny, other.Deny),
+		TrustedOrgs:    slices.Concat(c.TrustedOrgs, other.TrustedOrgs),
+		TrustedDomains: slices.Concat(c.TrustedDomains, other.TrustedDomains),
+	}
+	slices.Sort(m.TrustedOrgs)
+	m.TrustedOrgs = slices.Compact(m.TrustedOrgs)
+	slices.Sort(m.TrustedDomains)
+	m.TrustedDomains = slices.Compact(m.TrustedDomains)
+
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("error merging trust files: %w", err)
+	}
+
+	return m, nil
+}
+
+// Apply adjusts a user's score by the allowlist and denylist and returns
+// the adjusted score with the override applied, if any.
+func (c *Config) Apply(user string, score float64) (float64, *Override) {
+	if c == nil {
+		return score, nil
+	}
+
+	for _, d := range c.Deny {
+		if strings.EqualFold(d.User, user) {
+			var v float64
+			return v, &Override{Type: OverrideDeny, Subject: d.User, Score: &v, Reason: d.Reason}
+		}
+	}
+
+	for _, a := range c.Allow {
+		if !strings.EqualFold(a.User, user) {
+			continue
+		}
+		var v float64
+		if a.Score != nil {
+			v = *a.Score
+		} else {
+			v = max(score, *a.Floor)
+		}
+		return v, &Override{Type: OverrideAllow, Subject: a.User, Score: &v, Reason: a.Reason}
+	}
+
+	return score, nil
+}
+
+// TrustedDomain returns the first trusted domain matching any of the emails.
+// Subdomains of a trusted domain also match.
+func (c *Config) TrustedDomain(emails ...string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	for _, e := range emails {
+		_, host, ok := strings.Cut(strings.ToLower(strings.TrimSpace(e)), "@")
+		if !ok || host == "" {
+			continue
+		}
+		for _, d := range c.TrustedDomains {
+			if host == d || strings.HasSuffix(host, "."+d) {
+				return d, true
+			}
+		}
+	}
+
+	return "", false
+}
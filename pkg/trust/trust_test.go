@@ -0,0 +1,155 @@
+package trust
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTrust = `
+allow:
+- user: maintainer
+  score: 1.0
+  reason: project maintainer
+- user: Bot-Account
+  floor: 0.7
+deny:
+- user: mallory
+  reason: account compromised in 2024
+trusted_orgs:
+- cncf
+trusted_domains:
+- "@Example.com"
+`
+
+func float(v float64) *float64 { return &v }
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(testTrust), ".yaml")
+	require.NoError(t, err)
+	assert.Len(t, c.Allow, 2)
+	assert.Len(t, c.Deny, 1)
+	assert.Equal(t, []string{"cncf"}, c.TrustedOrgs)
+	assert.Equal(t, []string{"example.com"}, c.TrustedDomains)
+
+	_, err = Parse([]byte(`{"deny": [{"user": "x", "reason": "spam"}]}`), ".json")
+	require.NoError(t, err)
+
+	_, err = Parse([]byte("allow: []\nbogus: 1\n"), ".yaml")
+	require.Error(t, err)
+}
+
+func TestValidateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    *Config
+		errMsg string
+	}{
+		{"nil config", nil, "must be specified"},
+		{"missing user", &Config{Allow: []*Allow{{Floor: float(0.5)}}}, "user must be specified"},
+		{"no adjustment", &Config{Allow: []*Allow{{User: "a"}}}, "floor or score"},
+		{"both adjustments", &Config{Allow: []*Allow{{User: "a", Floor: float(0.5), Score: float(1)}}}, "mutually exclusive"},
+		{"floor out of range", &Config{Allow: []*Allow{{User: "a", Floor: float(2)}}}, "floor must be in"},
+		{"score out of range", &Config{Allow: []*Allow{{User: "a", Score: float(-1)}}}, "score must be in"},
+		{"deny without reason", &Config{Deny: []*Deny{{User: "a"}}}, "reason must be specified"},
+		{"allowed and denied", &Config{
+			Allow: []*Allow{{User: "a", Floor: float(0.5)}},
+			Deny:  []*Deny{{User: "A", Reason: "x"}},
+		}, "both allow and deny"},
+		{"bad domain", &Config{TrustedDomains: []string{"a@b.com"}}, "invalid trusted domain"},
+		{"empty org", &Config{TrustedOrgs: []string{""}}, "trusted org"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reputer.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testTrust), 0o600))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+	assert.Len(t, c.Allow, 2)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	c, err := Parse([]byte(testTrust), ".yaml")
+	require.NoError(t, err)
+
+	score, o := c.Apply("maintainer", 0.2)
+	assert.InDelta(t, 1.0, score, 0.0001)
+	require.NotNil(t, o)
+	assert.Equal(t, OverrideAllow, o.Type)
+	assert.Equal(t, "project maintainer", o.Reason)
+
+	score, o = c.Apply("bot-account", 0.3)
+	assert.InDelta(t, 0.7, score, 0.0001)
+	require.NotNil(t, o)
+	assert.InDelta(t, 0.7, *o.Score, 0.0001)
+
+	score, _ = c.Apply("bot-account", 0.9)
+	assert.InDelta(t, 0.9, score, 0.0001)
+
+	score, o = c.Apply("Mallory", 0.95)
+	assert.Zero(t, score)
+	require.NotNil(t, o)
+	assert.Equal(t, OverrideDeny, o.Type)
+	assert.Equal(t, "account compromised in 2024", o.Reason)
+
+	score, o = c.Apply("someone", 0.4)
+	assert.InDelta(t, 0.4, score, 0.0001)
+	assert.Nil(t, o)
+
+	var n *Config
+	score, o = n.Apply("maintainer", 0.4)
+	assert.InDelta(t, 0.4, score, 0.0001)
+	assert.Nil(t, o)
+}
+
+func TestTrustedDomain(t *testing.T) {
+	c := &Config{TrustedDomains: []string{"example.com"}}
+
+	d, ok := c.TrustedDomain("dev@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "example.com", d)
+
+	_, ok = c.TrustedDomain("not-an-email", "Dev@Eng.Example.COM")
+	assert.True(t, ok)
+
+	_, ok = c.TrustedDomain("dev@badexample.com", "dev@example.com.evil.io")
+	assert.False(t, ok)
+
+	var n *Config
+	_, ok = n.TrustedDomain("dev@example.com")
+	assert.False(t, ok)
+}
+
+func TestMerge(t *testing.T) {
+	a := &Config{Allow: []*Allow{{User: "a", Floor: float(0.5)}}, TrustedOrgs: []string{"x", "y"}}
+	b := &Config{Deny: []*Deny{{User: "b", Reason: "spam"}}, TrustedOrgs: []string{"y"}}
+
+	m, err := a.Merge(b)
+	require.NoError(t, err)
+	assert.Len(t, m.Allow, 1)
+	assert.Len(t, m.Deny, 1)
+	assert.Equal(t, []string{"x", "y"}, m.TrustedOrgs)
+
+	_, err = a.Merge(&Config{Deny: []*Deny{{User: "A", Reason: "x"}}})
+	require.Error(t, err)
+
+	var n *Config
+	m, err = n.Merge(b)
+	require.NoError(t, err)
+	assert.Same(t, b, m)
+}